@@ -0,0 +1,62 @@
+// Package amqpadapter logs AMQP connection close and flow control notifications through logger.Logger.
+package amqpadapter
+
+import (
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/w84thesun/logger"
+)
+
+// Fields attached to connection entries
+const (
+	BrokerKey = "amqp.broker"
+	VhostKey  = "amqp.vhost"
+	CodeKey   = "amqp.code"
+	ReasonKey = "amqp.reason"
+)
+
+// Component of all entries
+const Component = "amqp"
+
+// Watch logs close and blocked notifications of the connection in a goroutine, which stops when
+// the connection is closed. Closes initiated by the server are logged at error level, Connection.Close at info.
+func Watch(l logger.Logger, conn *amqp.Connection) {
+	fields := logger.Fields{VhostKey: conn.Config.Vhost}
+	if addr := conn.RemoteAddr(); addr != nil {
+		fields[BrokerKey] = addr.String()
+	}
+
+	closes := conn.NotifyClose(make(chan *amqp.Error, 1))
+	blocked := conn.NotifyBlocked(make(chan amqp.Blocking, 1))
+
+	go watch(l.WithComponent(Component).With(fields), closes, blocked)
+}
+
+func watch(l logger.Logger, closes <-chan *amqp.Error, blocked <-chan amqp.Blocking) {
+	for {
+		select {
+		case err, ok := <-closes:
+			if !ok || err == nil {
+				l.Info("amqp connection closed")
+				return
+			}
+			l.With(logger.Fields{
+				CodeKey:   err.Code,
+				ReasonKey: err.Reason,
+				"error":   err.Error(),
+			}).Error("amqp connection closed")
+			return
+		case b, ok := <-blocked:
+			if !ok {
+				// Closed together with closes on shutdown, wait for the close notification only
+				blocked = nil
+				continue
+			}
+			if b.Active {
+				l.With(logger.Fields{ReasonKey: b.Reason}).Warn("amqp connection blocked")
+			} else {
+				l.Info("amqp connection unblocked")
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package amqpadapter
+
+import (
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+// runWatch runs watch until it returns
+func runWatch(t *testing.T, l logger.Logger, closes chan *amqp.Error, blocked chan amqp.Blocking) {
+	done := make(chan struct{})
+	go func() {
+		watch(l.WithComponent(Component).With(logger.Fields{BrokerKey: "10.0.0.1:5672"}), closes, blocked)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch didn't stop")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	closes := make(chan *amqp.Error, 1)
+	blocked := make(chan amqp.Blocking, 2)
+	blocked <- amqp.Blocking{Active: true, Reason: "low on memory"}
+	blocked <- amqp.Blocking{Active: false}
+	close(blocked)
+	go func() {
+		// Let blocked notifications be handled first
+		time.Sleep(10 * time.Millisecond)
+		closes <- &amqp.Error{Code: amqp.ConnectionForced, Reason: "broker shutdown", Server: true}
+	}()
+
+	runWatch(t, l, closes, blocked)
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, "warn", got[0]["level"])
+	assert.Equal(t, "amqp connection blocked", got[0]["message"])
+	assert.Equal(t, "low on memory", got[0][ReasonKey])
+	assert.Equal(t, "amqp connection unblocked", got[1]["message"])
+
+	assert.Equal(t, "error", got[2]["level"])
+	assert.Equal(t, "amqp connection closed", got[2]["message"])
+	assert.Equal(t, float64(amqp.ConnectionForced), got[2][CodeKey])
+	assert.Equal(t, "broker shutdown", got[2][ReasonKey])
+	assert.Contains(t, got[2]["error"], "broker shutdown")
+
+	for _, entry := range got {
+		assert.Equal(t, Component, entry[logger.ComponentKey])
+		assert.Equal(t, "10.0.0.1:5672", entry[BrokerKey])
+	}
+}
+
+func TestWatch_GracefulClose(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	closes := make(chan *amqp.Error)
+	close(closes)
+	runWatch(t, l, closes, make(chan amqp.Blocking))
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "amqp connection closed", got[0]["message"])
+	assert.NotContains(t, got[0], "error")
+}
@@ -0,0 +1,19 @@
+module github.com/w84thesun/logger/amqpadapter
+
+go 1.21
+
+require (
+	github.com/rabbitmq/amqp091-go v1.15.0
+	github.com/stretchr/testify v1.12.1
+	github.com/w84thesun/logger v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/pkg/errors v0.9.1 // indirect
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+)
+
+replace github.com/w84thesun/logger => ../
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Message of the entry logged by New with LoggingConfig.AnnounceConfig
+const AnnounceMessage = "logger initialized"
+
+// Key of the field with the resolved config of the AnnounceMessage entry
+const AnnounceConfigKey = "config"
+
+// Parts of names of query parameters and headers whose values are redacted from the announced config
+var secretNameParts = []string{"auth", "token", "secret", "password", "passwd", "key", "credential", "signature", "cookie", "session"}
+
+// announce logs AnnounceMessage at info level with the config as the logger resolved it, e.g. the level it fell back to.
+// Level is the lowest one enabled by outputs except the debug file.
+func (l loggerImpl) announce(config LoggingConfig, level zapcore.Level) {
+	l.With(Fields{AnnounceConfigKey: announcedConfig(config, level, l.Stats())}).Info(AnnounceMessage)
+}
+
+// minLevel returns the lowest level enabled by outputs, fatal if none is enabled
+func (l loggerImpl) minLevel() zapcore.Level {
+	level := zapcore.DebugLevel
+	for level < zapcore.FatalLevel && l.disabled(level) {
+		level++
+	}
+	return level
+}
+
+// announcedConfig returns effective settings of the config with secrets redacted, optional ones only if used
+func announcedConfig(config LoggingConfig, level zapcore.Level, stats LoggerStats) Fields {
+	outputs := make([]string, 0, len(stats.Sinks))
+	for name := range stats.Sinks {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+
+	// Already validated by New
+	format, _ := ParseFormat(config.FormatStdout)
+
+	announced := Fields{
+		"level":     level.String(),
+		"format":    format,
+		"outputs":   outputs,
+		"service":   config.Service,
+		"namespace": config.Namespace,
+		"sampling":  config.Sampling,
+	}
+
+	optional := Fields{
+		"logstash_uri":      redactAddresses(config.LogstashURI),
+		"http_endpoint":     redactAddresses(config.HTTPEndpoint),
+		"nats_url":          redactAddresses(config.NATSURL),
+		"nats_subject":      config.NATSSubject,
+		"audit_file":        config.AuditFile,
+		"audit_uri":         redactAddresses(config.AuditURI),
+		"debug_file":        config.DebugFilePath,
+		"stacktrace_level":  config.StacktraceLevel,
+		"caller":            config.Caller,
+		"async_stdout":      config.AsyncStdout,
+		"sequence":          config.Sequence,
+		"entry_id":          config.EntryID,
+		"dedup_consecutive": config.DedupConsecutive,
+	}
+	if config.LogstashURI != "" {
+		optional["logstash_protocol"] = config.LogstashProtocol
+	}
+	if len(config.HTTPHeaders) > 0 {
+		headers := make(map[string]string, len(config.HTTPHeaders))
+		for name, value := range config.HTTPHeaders {
+			if isSecretName(name) {
+				value = redacted
+			}
+			headers[name] = value
+		}
+		optional["http_headers"] = headers
+	}
+	if config.FlushInterval > 0 {
+		optional["flush_interval"] = config.FlushInterval.String()
+	}
+
+	for key, value := range optional {
+		if value != "" && value != false {
+			announced[key] = value
+		}
+	}
+	return announced
+}
+
+// redactAddresses redacts user info and secret-looking query parameters of comma-separated URLs.
+// Addresses without scheme, e.g. "host:5000", are kept as is.
+func redactAddresses(addresses string) string {
+	if addresses == "" {
+		return ""
+	}
+
+	parts := strings.Split(addresses, ",")
+	for i, address := range parts {
+		parts[i] = redactURL(strings.TrimSpace(address))
+	}
+	return strings.Join(parts, ",")
+}
+
+func redactURL(address string) string {
+	if !strings.Contains(address, "://") {
+		return address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		// Can't tell secrets apart
+		return redacted
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	if u.RawQuery != "" {
+		query := u.Query()
+		for param := range query {
+			if isSecretName(param) {
+				query[param] = []string{redacted}
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
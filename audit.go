@@ -0,0 +1,208 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Namespace of entries written by Audit, so they are stored apart from application ones
+const AuditNamespace = "audit"
+
+// Fields every audit entry must have, see ValidateAudit
+const (
+	AuditActorKey  = "actor"
+	AuditActionKey = "action"
+	AuditTargetKey = "target"
+)
+
+// Timeout of connecting and writing to AuditURI
+const DefaultAuditTimeout = 5 * time.Second
+
+// Returned by Audit of loggers without audit output, e.g. if AuditFile, AuditURI and AuditWriter are empty
+var ErrAuditNotConfigured = errors.New("audit output is not configured, set AuditFile, AuditURI or AuditWriter")
+
+// Returned by Audit after Close
+var errAuditClosed = errors.New("audit output is closed")
+
+// ValidateAudit returns error if the audit entry misses actor, action or target field, or any of them is empty.
+// Audit calls it with fields of the logger too, so they may be added by With beforehand, e.g. the actor.
+func ValidateAudit(event string, fields Fields) error {
+	if strings.TrimSpace(event) == "" {
+		return errors.New("audit event must not be empty")
+	}
+	for _, key := range []string{AuditActorKey, AuditActionKey, AuditTargetKey} {
+		value, ok := fields.Get(key)
+		if !ok || value == nil || value == "" {
+			return fmt.Errorf("audit event %q must have non-empty %s field", event, key)
+		}
+	}
+	return nil
+}
+
+// auditState is the audit output shared by all loggers derived from the same New call and kept by Reconfigure.
+// It's apart from the core of other outputs, so neither sampling, dedup nor level apply to it.
+type auditState struct {
+	// Serializes entries, so each one is synced before Audit returns
+	mu     sync.Mutex
+	closed bool
+
+	core zapcore.Core
+	ws   zapcore.WriteSyncer
+	// Closes the file or connection, nil for AuditWriter owned by the caller
+	closer io.Closer
+}
+
+// newAuditState opens the audit output of the config, nil if there is none
+func newAuditState(config LoggingConfig) (*auditState, error) {
+	var outputs int
+	for _, set := range []bool{config.AuditFile != "", config.AuditURI != "", config.AuditWriter != nil} {
+		if set {
+			outputs++
+		}
+	}
+	switch {
+	case outputs == 0:
+		return nil, nil
+	case outputs > 1:
+		return nil, errors.New("only one of AuditFile, AuditURI and AuditWriter can be set")
+	}
+
+	a := &auditState{ws: config.AuditWriter}
+	switch {
+	case config.AuditFile != "":
+		file, err := os.OpenFile(config.AuditFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		a.ws, a.closer = file, file
+	case config.AuditURI != "":
+		conn, err := newAuditConn(config.AuditURI)
+		if err != nil {
+			return nil, err
+		}
+		a.ws, a.closer = conn, conn
+	}
+
+	a.core = newCompatJSONCore(a.ws, zapcore.DebugLevel, config).With(generalFields(config))
+	return a, nil
+}
+
+// write writes the entry and syncs the output, so it's on disk or sent once write returns
+func (a *auditState) write(ent zapcore.Entry, fields []zapcore.Field) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return errAuditClosed
+	}
+
+	if err := a.core.Write(ent, fields); err != nil {
+		return err
+	}
+	return a.ws.Sync()
+}
+
+// Close closes the file or connection of the audit output, calling it more than once is no-op
+func (a *auditState) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+
+	a.closed = true
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// auditConn is a tcp connection of the audit output separate from logstash ones. Failed writes are retried once
+// on a new connection, so an entry may be delivered twice but isn't lost silently.
+type auditConn struct {
+	addr string
+	conn net.Conn
+}
+
+func newAuditConn(addr string) (*auditConn, error) {
+	c := &auditConn{addr: addr}
+	if err := c.dial(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *auditConn) dial() error {
+	conn, err := net.DialTimeout("tcp", c.addr, DefaultAuditTimeout)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	return nil
+}
+
+// Write is serialized by auditState
+func (c *auditConn) Write(p []byte) (int, error) {
+	n, err := c.write(p)
+	if err == nil {
+		return n, nil
+	}
+
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+	return c.write(p)
+}
+
+func (c *auditConn) write(p []byte) (int, error) {
+	if c.conn == nil {
+		if err := c.dial(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(DefaultAuditTimeout)); err != nil {
+		return 0, err
+	}
+	return c.conn.Write(p)
+}
+
+// Sync is no-op, entries are written to the connection unbuffered
+func (c *auditConn) Sync() error {
+	return nil
+}
+
+func (c *auditConn) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+func (l loggerImpl) Audit(event string, fields Fields) error {
+	if l.audit == nil {
+		return ErrAuditNotConfigured
+	}
+
+	// Fields of the call take precedence like with other entries
+	all := l.fields.fields().Copy()
+	for _, fn := range l.lazy {
+		all = all.Merge(fn())
+	}
+	all = all.Merge(fields)
+	if err := ValidateAudit(event, all); err != nil {
+		return err
+	}
+	all["namespace"] = AuditNamespace
+
+	zapFields, _ := all.zapFields(l.collisionPolicy())
+	return l.audit.write(zapcore.Entry{Level: zapcore.InfoLevel, Time: time.Now(), Message: event}, zapFields)
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_Audit(t *testing.T) {
+	stdout := &lockedBuffer{}
+	audit := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		Namespace:        "payments",
+		Level:            "error",
+		Sampling:         true,
+		DedupConsecutive: true,
+		AuditWriter:      zapcore.AddSync(audit),
+	}, zapcore.AddSync(stdout))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	admin := logger.With(Fields{AuditActorKey: "admin", "request_id": 7}).WithLazy(func() Fields {
+		return Fields{"ip": "10.0.0.1"}
+	})
+	for i := 0; i < 3; i++ {
+		require.NoError(t, admin.Audit("payout approved", Fields{AuditActionKey: "approve", AuditTargetKey: "payout/42"}))
+	}
+
+	// Neither level, sampling nor dedup of other outputs apply
+	entries := audit.entries(t)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "payout approved", entries[0]["message"])
+	assert.Equal(t, "info", entries[0]["level"])
+	assert.Equal(t, "testing", entries[0]["service"])
+	assert.Equal(t, AuditNamespace, entries[0]["namespace"])
+	assert.Equal(t, "admin", entries[0][AuditActorKey])
+	assert.Equal(t, "approve", entries[0][AuditActionKey])
+	assert.Equal(t, "payout/42", entries[0][AuditTargetKey])
+	assert.Equal(t, float64(7), entries[0]["request_id"])
+	assert.Equal(t, "10.0.0.1", entries[0]["ip"])
+	assert.Empty(t, stdout.entries(t))
+
+	// Other outputs don't get audit entries
+	admin.Error("regular")
+	require.Len(t, stdout.entries(t), 1)
+	assert.Equal(t, "payments", stdout.entries(t)[0]["namespace"])
+	assert.Len(t, audit.entries(t), 3)
+}
+
+func TestLoggerImpl_Audit_Invalid(t *testing.T) {
+	audit := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", AuditWriter: zapcore.AddSync(audit)}, &mockSink{})
+	require.NoError(t, err)
+
+	err = logger.Audit("role granted", Fields{AuditActorKey: "admin", AuditActionKey: "grant"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "target")
+
+	err = logger.Audit("role granted", Fields{AuditActorKey: "", AuditActionKey: "grant", AuditTargetKey: "user/1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "actor")
+
+	assert.Error(t, logger.Audit(" ", Fields{AuditActorKey: "admin", AuditActionKey: "grant", AuditTargetKey: "user/1"}))
+	assert.Empty(t, audit.entries(t))
+}
+
+func TestLoggerImpl_Audit_WriteError(t *testing.T) {
+	failed := errors.New("disk full")
+	logger, err := newLogger(LoggingConfig{Service: "testing", AuditWriter: &mockSink{err: failed}}, &mockSink{})
+	require.NoError(t, err)
+
+	err = logger.Audit("payout approved", Fields{AuditActorKey: "a", AuditActionKey: "b", AuditTargetKey: "c"})
+	assert.True(t, errors.Is(err, failed), err)
+}
+
+func TestLoggerImpl_Audit_NotConfigured(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, &mockSink{})
+	require.NoError(t, err)
+	fields := Fields{AuditActorKey: "a", AuditActionKey: "b", AuditTargetKey: "c"}
+	assert.Equal(t, ErrAuditNotConfigured, logger.Audit("event", fields))
+
+	var zero loggerImpl
+	assert.Equal(t, ErrAuditNotConfigured, zero.Audit("event", fields))
+
+	_, err = newLogger(LoggingConfig{Service: "testing", AuditURI: "127.0.0.1:1", AuditWriter: &mockSink{}}, &mockSink{})
+	assert.Error(t, err)
+}
+
+func TestLoggerImpl_Audit_SlowSink(t *testing.T) {
+	stdout := &lockedBuffer{}
+	audit := &mockSink{release: make(chan struct{})}
+	logger, err := newLogger(LoggingConfig{Service: "testing", AuditWriter: audit}, zapcore.AddSync(stdout))
+	require.NoError(t, err)
+
+	written := make(chan error)
+	go func() {
+		written <- logger.Audit("payout approved", Fields{AuditActorKey: "a", AuditActionKey: "b", AuditTargetKey: "c"})
+	}()
+
+	// Regular entries aren't blocked by the audit output
+	logger.Info("regular")
+	assert.Len(t, stdout.entries(t), 1)
+
+	close(audit.release)
+	require.NoError(t, <-written)
+	assert.Len(t, audit.written(), 1)
+}
+
+func TestLoggerImpl_Audit_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	logger, err := newLogger(LoggingConfig{Service: "testing", AuditFile: path}, &mockSink{})
+	require.NoError(t, err)
+	require.NoError(t, logger.Audit("role granted", Fields{AuditActorKey: "a", AuditActionKey: "grant", AuditTargetKey: "c"}))
+
+	// Reconfigure keeps the audit output
+	require.NoError(t, logger.(Reconfigurer).Reconfigure(LoggingConfig{Service: "testing"}))
+	require.NoError(t, logger.Audit("role revoked", Fields{AuditActorKey: "a", AuditActionKey: "revoke", AuditTargetKey: "c"}))
+
+	// Synced by Audit, so it's in the file before Close
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"message":"role granted"`)
+	assert.Contains(t, string(data), `"message":"role revoked"`)
+
+	require.NoError(t, logger.Close())
+	assert.Equal(t, errAuditClosed,
+		logger.Audit("role granted", Fields{AuditActorKey: "a", AuditActionKey: "grant", AuditTargetKey: "c"}))
+}
+
+func TestLoggerImpl_Audit_URI(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	logger, err := newLogger(LoggingConfig{Service: "testing", AuditURI: listener.Addr().String()}, &mockSink{})
+	require.NoError(t, err)
+	defer logger.Close()
+	require.NoError(t, logger.Audit("payout approved", Fields{AuditActorKey: "a", AuditActionKey: "b", AuditTargetKey: "c"}))
+
+	select {
+	case line := <-lines:
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.Equal(t, "payout approved", entry["message"])
+		assert.Equal(t, AuditNamespace, entry["namespace"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("audit entry isn't received")
+	}
+}
@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Defaults of batching stream logstash writes
+const (
+	DefaultLogstashBatchSize     = 64 << 10
+	DefaultLogstashBatchInterval = 250 * time.Millisecond
+)
+
+// Defaults of AsyncStdout buffer, see LoggingConfig.AsyncStdout
+const (
+	DefaultAsyncStdoutBufferSize    = 256 << 10
+	DefaultAsyncStdoutFlushInterval = time.Second
+)
+
+// batchWriter buffers entries and writes them in one call once the buffer fills up or the interval passes,
+// so every entry isn't a separate syscall. Only for stream connections, datagrams must hold single entries.
+// Panic and fatal entries are synced by zap cores, so they are written before the process exits.
+// Entries written after Close go directly to the underlying syncer.
+type batchWriter struct {
+	mu     sync.Mutex
+	ws     zapcore.WriteSyncer
+	buf    []byte
+	size   int
+	closed bool
+	// Entries in buf
+	entries int
+
+	// Error of the last background flush, returned by the next Write or Sync
+	err error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newBatchWriter(ws zapcore.WriteSyncer, size int, interval time.Duration) *batchWriter {
+	w := &batchWriter{
+		ws:   ws,
+		buf:  make([]byte, 0, size),
+		size: size,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go w.run(interval)
+	return w
+}
+
+func (w *batchWriter) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			w.err = multierr.Append(w.err, w.flush())
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// flush writes buffered entries, must be called with the lock held
+func (w *batchWriter) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+
+	_, err := w.ws.Write(w.buf)
+	w.buf = w.buf[:0]
+	w.entries = 0
+	return err
+}
+
+// takeErr returns background flush error, must be called with the lock held
+func (w *batchWriter) takeErr() error {
+	err := w.err
+	w.err = nil
+	return err
+}
+
+func (w *batchWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.ws.Write(p)
+	}
+
+	err := w.takeErr()
+	if len(w.buf)+len(p) > w.size {
+		err = multierr.Append(err, w.flush())
+	}
+
+	// Entries not fitting the buffer are written as is
+	if len(p) >= w.size {
+		_, writeErr := w.ws.Write(p)
+		return len(p), multierr.Append(err, writeErr)
+	}
+
+	w.buf = append(w.buf, p...)
+	w.entries++
+	return len(p), err
+}
+
+// queueStats reports buffered entries to Stats, the buffer is limited by bytes and never drops them
+func (w *batchWriter) queueStats() (int, int, uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.entries, 0, 0
+}
+
+func (w *batchWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return w.ws.Sync()
+	}
+
+	return multierr.Combine(w.takeErr(), w.flush(), w.ws.Sync())
+}
+
+// Close stops background flushing and writes buffered entries, the underlying syncer is left open
+func (w *batchWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return multierr.Append(w.takeErr(), w.flush())
+}
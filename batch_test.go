@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// writeCountingSink counts writes to the underlying writer
+type writeCountingSink struct {
+	zapcore.WriteSyncer
+	writes int64
+}
+
+func (s *writeCountingSink) Write(p []byte) (int, error) {
+	atomic.AddInt64(&s.writes, 1)
+	return s.WriteSyncer.Write(p)
+}
+
+func TestBatchWriter_Size(t *testing.T) {
+	sink := &mockSink{}
+	w := newBatchWriter(sink, 10, time.Hour)
+
+	for _, entry := range []string{"aaaa\n", "bbbb\n", "cccc\n"} {
+		_, err := w.Write([]byte(entry))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"aaaa\nbbbb\n"}, sink.written())
+
+	// Entries larger than the buffer are written as is after buffered ones
+	_, err := w.Write([]byte("0123456789\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aaaa\nbbbb\n", "cccc\n", "0123456789\n"}, sink.written())
+
+	require.NoError(t, w.Close())
+}
+
+func TestBatchWriter_Interval(t *testing.T) {
+	sink := &mockSink{}
+	w := newBatchWriter(sink, 1024, 10*time.Millisecond)
+	defer w.Close()
+
+	_, err := w.Write([]byte("delayed\n"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(sink.written()) == 1
+	}, 2*time.Second, time.Millisecond)
+}
+
+func TestBatchWriter_SyncClose(t *testing.T) {
+	sink := &mockSink{}
+	w := newBatchWriter(sink, 1024, time.Hour)
+
+	_, err := w.Write([]byte("synced\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Sync())
+	assert.Equal(t, []string{"synced\n"}, sink.written())
+
+	_, err = w.Write([]byte("closed\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{"synced\n", "closed\n"}, sink.written())
+
+	_, err = w.Write([]byte("unbuffered\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"synced\n", "closed\n", "unbuffered\n"}, sink.written())
+	assert.NoError(t, w.Close())
+}
+
+func TestBatchWriter_FlushError(t *testing.T) {
+	sink := &mockSink{err: io.ErrClosedPipe}
+	w := newBatchWriter(sink, 1024, time.Hour)
+
+	_, err := w.Write([]byte("failed\n"))
+	require.NoError(t, err)
+	assert.Equal(t, io.ErrClosedPipe, w.Sync())
+
+	_, err = w.Write([]byte("failed on close\n"))
+	require.NoError(t, err)
+	assert.Equal(t, io.ErrClosedPipe, w.Close())
+}
+
+func TestLogstashBatchSize(t *testing.T) {
+	assert.Equal(t, DefaultLogstashBatchSize, logstashBatchSize(LoggingConfig{LogstashProtocol: "tcp"}))
+	assert.Equal(t, 1024, logstashBatchSize(LoggingConfig{LogstashProtocol: "tcp4", LogstashBatchSize: 1024}))
+	assert.Equal(t, 0, logstashBatchSize(LoggingConfig{LogstashProtocol: "tcp", LogstashBatchSize: -1}))
+	assert.Equal(t, 0, logstashBatchSize(LoggingConfig{LogstashProtocol: "udp", LogstashBatchSize: 1024}))
+}
+
+func TestAsyncStdout(t *testing.T) {
+	sink := &mockSink{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", AsyncStdout: true}, sink)
+	require.NoError(t, err)
+
+	logger.Info("first")
+	logger.Info("second")
+	assert.Empty(t, sink.written())
+
+	require.NoError(t, logger.Sync())
+	require.Len(t, sink.written(), 1)
+	assert.Equal(t, 2, strings.Count(sink.written()[0], "\n"))
+
+	logger.Info("closed")
+	require.NoError(t, logger.Close())
+	assert.Len(t, sink.written(), 2)
+
+	// Written directly after Close
+	logger.Info("late")
+	assert.Len(t, sink.written(), 3)
+}
+
+func TestAsyncStdout_Interactive(t *testing.T) {
+	_, err := newLogger(LoggingConfig{Service: "testing", AsyncStdout: true, Interactive: true}, &mockSink{})
+	assert.Error(t, err)
+}
+
+// Compare ns/op and writes/op, i.e. write syscalls per entry, of unbatched and batched tcp connection
+func BenchmarkBatchWriter_TCP(b *testing.B) {
+	entry := []byte(`{"level":"info","message":"benchmark","service":"testing","@version":"1","type":"log"}` + "\n")
+
+	for _, batchSize := range []int{0, DefaultLogstashBatchSize} {
+		name := "unbatched"
+		if batchSize > 0 {
+			name = "batched"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(b, err)
+			defer listener.Close()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				_, _ = io.Copy(ioutil.Discard, conn)
+			}()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			require.NoError(b, err)
+			defer conn.Close()
+
+			sink := &writeCountingSink{WriteSyncer: zapcore.AddSync(conn)}
+			var ws zapcore.WriteSyncer = sink
+			if batchSize > 0 {
+				batch := newBatchWriter(sink, batchSize, DefaultLogstashBatchInterval)
+				defer batch.Close()
+				ws = batch
+			}
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(entry)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := ws.Write(entry); err != nil {
+					b.Fatal(err)
+				}
+			}
+			require.NoError(b, ws.Sync())
+			b.StopTimer()
+
+			b.ReportMetric(float64(atomic.LoadInt64(&sink.writes))/float64(b.N), "writes/op")
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+)
+
+func (l loggerImpl) WithCallerSkip(n int) Logger {
+	if n == 0 {
+		return l
+	}
+	return l.skipCaller(n)
+}
+
+// WithCallerOutside returns l reporting the first caller whose function doesn't start with any of the prefixes,
+// for adapters called by libraries through several frames, e.g. hooks of database clients:
+//
+//	logger.WithCallerOutside(l, "github.com/redis/go-redis/", "example.com/app/redishook.").Debug("command")
+//
+// Must be called directly by the function logging the entry. Frames of _test.go files are never skipped,
+// l is returned unchanged if all frames match.
+func WithCallerOutside(l Logger, prefixes ...string) Logger {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+
+	for skip := 0; ; skip++ {
+		frame, more := frames.Next()
+		if !hasAnyPrefix(frame.Function, prefixes) || strings.HasSuffix(frame.File, "_test.go") {
+			return l.WithCallerSkip(skip)
+		}
+		if !more {
+			return l
+		}
+	}
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
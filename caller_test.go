@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_WithCallerSkip(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	// Helper logging on behalf of its caller, like adapters do
+	helper := func(l Logger) {
+		l.WithCallerSkip(1).With(Fields{"a": "b"}).Info("skipped")
+	}
+	helper(logger)
+	_, _, line, _ := runtime.Caller(0)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.True(t, strings.HasSuffix(entries[0]["caller"].(string), "caller_test.go:"+strconv.Itoa(line-1)), entries[0]["caller"])
+}
+
+func TestWithCallerOutside(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	// Functions of tests match the prefix, but frames of _test.go files are never skipped
+	WithCallerOutside(logger, "github.com/w84thesun/logger.").Info("test")
+	_, _, line, _ := runtime.Caller(0)
+	WithCallerOutside(logger).Info("no prefixes")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.True(t, strings.HasSuffix(entries[0]["caller"].(string), "caller_test.go:"+strconv.Itoa(line-1)), entries[0]["caller"])
+	assert.True(t, strings.HasSuffix(entries[1]["caller"].(string), "caller_test.go:"+strconv.Itoa(line+1)), entries[1]["caller"])
+}
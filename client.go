@@ -1,14 +1,20 @@
 package logger
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/pkg/errors"
-
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -25,13 +31,208 @@ type LoggingConfig struct {
 	// Can be overwritten for each log using .With method.
 	Namespace string `env:"LOGGER_NAMESPACE"`
 
+	// New logs "logger initialized" entry at info level with the effective config under "config" field, e.g. level,
+	// format and outputs, with credentials of URLs and headers redacted. True in DefaultConfig and other presets,
+	// can be disabled for noise-sensitive CLIs.
+	AnnounceConfig bool `env:"LOGGER_ANNOUNCE_CONFIG"`
+
 	// Disables stdout if not needed.
 	DisableStdout bool   `env:"LOGGER_DISABLE_STDOUT"`
 	FormatStdout  string `env:"LOGGER_FORMAT_STDOUT"`
 
+	// Replaces os.Stdout as the stdout output, e.g. a buffer in tests. Isn't closed by Close.
+	StdoutWriter zapcore.WriteSyncer
+
+	// New fails if no output is enabled, e.g. stdout is disabled and LogstashURI is empty, so entries aren't lost
+	// silently. With AllowNoOutputs it only warns, e.g. for benchmarks or loggers writing through CoreWrapper.
+	AllowNoOutputs bool `env:"LOGGER_ALLOW_NO_OUTPUTS"`
+
 	// TCP connection settings. Only for development and testing, publishers should be used instead in production.
+	// Several comma-separated addresses are written concurrently through FanOutWriter.
 	LogstashURI      string `env:"LOGGER_LOGSTASH_URI"`
 	LogstashProtocol string `env:"LOGGER_LOGSTASH_PROTOCOL"`
+
+	// Buffers writes of stream (tcp) logstash connections up to the size in bytes, flushed at least every interval
+	// and on Sync, Close, panic and fatal entries. DefaultLogstashBatchSize and DefaultLogstashBatchInterval
+	// if zero, negative size disables batching. Every udp entry is always sent in its own datagram.
+	LogstashBatchSize     int           `env:"LOGGER_LOGSTASH_BATCH_SIZE"`
+	LogstashBatchInterval time.Duration `env:"LOGGER_LOGSTASH_BATCH_INTERVAL"`
+
+	// Dials stream logstash connections with TLS, verifying the server certificate against LogstashTLSCA PEM file
+	// (system roots if empty). LogstashTLSCert and LogstashTLSKey are PEM files of the client certificate if logstash
+	// requires one. LogstashTLSInsecureSkipVerify disables verification, only for testing.
+	LogstashTLS                   bool   `env:"LOGGER_LOGSTASH_TLS"`
+	LogstashTLSCA                 string `env:"LOGGER_LOGSTASH_TLS_CA"`
+	LogstashTLSCert               string `env:"LOGGER_LOGSTASH_TLS_CERT"`
+	LogstashTLSKey                string `env:"LOGGER_LOGSTASH_TLS_KEY"`
+	LogstashTLSInsecureSkipVerify bool   `env:"LOGGER_LOGSTASH_TLS_INSECURE_SKIP_VERIFY"`
+
+	// Production alternative to logstash connection: entries are POSTed to the endpoint as NDJSON batches of
+	// HTTPBatchSize entries, at least every HTTPFlushInterval (DefaultHTTPBatchSize and DefaultHTTPFlushInterval
+	// if zero). Failed batches are retried HTTPRetries times with exponential backoff (DefaultHTTPRetries if zero,
+	// negative disables retries). After DefaultHTTPBreakerFailures failed batches in a row, batches are dropped
+	// for a while without requests. HTTPTLSConfig configures https endpoints, e.g. client certificates.
+	HTTPEndpoint      string            `env:"LOGGER_HTTP_ENDPOINT"`
+	HTTPHeaders       map[string]string `env:"LOGGER_HTTP_HEADERS"`
+	HTTPBatchSize     int               `env:"LOGGER_HTTP_BATCH_SIZE"`
+	HTTPFlushInterval time.Duration     `env:"LOGGER_HTTP_FLUSH_INTERVAL"`
+	HTTPRetries       int               `env:"LOGGER_HTTP_RETRIES"`
+	HTTPTLSConfig     *tls.Config
+
+	// Publishes entries as JSON messages to NATSSubject through NATSPublisher, e.g. connection of the service,
+	// or through a connection to NATSURL made by natsadapter (see RegisterNATSDialer). With NATSJetStream
+	// the connection publishes to JetStream for durability, the subject must be bound to a stream. Entries are
+	// published in background from a queue of NATSQueueSize entries (DefaultNATSQueueSize if zero), entries
+	// not fitting it are dropped.
+	NATSURL       string `env:"LOGGER_NATS_URL"`
+	NATSSubject   string `env:"LOGGER_NATS_SUBJECT"`
+	NATSJetStream bool   `env:"LOGGER_NATS_JETSTREAM"`
+	NATSQueueSize int    `env:"LOGGER_NATS_QUEUE_SIZE"`
+	NATSPublisher Publisher
+
+	// Dedicated output of Audit entries, kept apart from other outputs: AuditFile is appended and synced after every
+	// entry, AuditURI is a tcp address connected separately from logstash, AuditWriter is synced after every entry
+	// and isn't closed. Only one of them can be set. Sampling, dedup, level and CoreWrapper don't apply to it,
+	// and a failed write is returned by Audit.
+	AuditFile   string `env:"LOGGER_AUDIT_FILE"`
+	AuditURI    string `env:"LOGGER_AUDIT_URI"`
+	AuditWriter zapcore.WriteSyncer
+
+	// Writes entries of all levels as JSON to the file, appending to it, while other outputs stay at Level,
+	// e.g. for a verbose file during an incident. Can be enabled and disabled at runtime through DebugFiler.
+	// Not reported by Stats and not wrapped by CoreWrapper.
+	DebugFilePath string `env:"LOGGER_DEBUG_FILE_PATH"`
+
+	// Writes entries to systemd journal through its native socket (JournaldSocket) with fields in uppercase,
+	// e.g. REQUEST_ID. Skipped with a warning if the socket is absent, e.g. not on a systemd host.
+	Journald bool `env:"LOGGER_JOURNALD"`
+
+	// Queue size and overflow policy of FanOutWriter used for several logstash addresses
+	FanOutQueueSize int          `env:"LOGGER_FANOUT_QUEUE_SIZE"`
+	FanOutPolicy    FanOutPolicy `env:"LOGGER_FANOUT_POLICY"`
+
+	// Emits JSON keys in a deterministic order: "@timestamp", "level", "message", "service", "namespace"
+	// first, then all other fields sorted lexicographically. Slower than the default, see benchmarks.
+	SortKeys bool `env:"LOGGER_SORT_KEYS"`
+
+	// Adds "seq" field increasing with every entry, helps to restore order of entries sharing a timestamp.
+	// Counter is per New call and shared by all loggers derived from it.
+	Sequence bool `env:"LOGGER_SEQUENCE"`
+
+	// Adds "entry_id" field unique for every entry, a ULID sortable by time, e.g. for deduplication of entries
+	// delivered more than once downstream
+	EntryID bool `env:"LOGGER_ENTRY_ID"`
+
+	// Suppresses entries identical to the previous one (level, message and fields), e.g. of flappy loops.
+	// When a different entry comes or on Sync, the last suppressed entry is written with "repeated" field
+	// counting suppressed entries. Serializes writes of all loggers derived from the same New call.
+	DedupConsecutive bool `env:"LOGGER_DEDUP_CONSECUTIVE"`
+
+	// Development option printing a warning with the caller of the first entry with empty message,
+	// e.g. of Info() called without arguments
+	WarnOnEmptyMessage bool `env:"LOGGER_WARN_ON_EMPTY_MESSAGE"`
+
+	// What to do with fields colliding with "@timestamp", "message", "level" and "service" written by the logger,
+	// FieldCollisionIgnore if empty. The first collision is logged at debug level.
+	FieldCollision FieldCollisionPolicy `env:"LOGGER_FIELD_COLLISION"`
+
+	// Which of fields with the same key is written, e.g. a field of With and a typed field of the entry.
+	// Fields added by With are encoded with every entry then, like with SortKeys. If empty, keys are written
+	// as many times as they are added.
+	FieldConflict FieldConflictPolicy `env:"LOGGER_FIELD_CONFLICT"`
+
+	// Deep-copies maps, slices, pointers and structs passed to With, so mutating them afterwards doesn't change
+	// entries, e.g. a map reused by the caller. Otherwise loggers keep references: fields are encoded on the first
+	// entry, and GetField and Record return the values themselves. Values encoded by their methods, e.g. errors,
+	// and unexported struct fields are shared. Costs a reflective copy per With, so it's off by default.
+	DeepCopyFields bool `env:"LOGGER_DEEP_COPY_FIELDS"`
+
+	// Formats errors logged by Trace and Recover, DefaultStackFormatter is used if not set
+	StackFormatter StackFormatter
+
+	// Uses StructuredStackFormatter if StackFormatter isn't set: stacks are logged as "stack" array of frames
+	// instead of the text appended to the message
+	StructuredStack bool `env:"LOGGER_STRUCTURED_STACK"`
+
+	// Format of the panic entry of Recover, gets its message and the panic value.
+	// DefaultRecoverFormat is used if empty.
+	RecoverFormat string `env:"LOGGER_RECOVER_FORMAT"`
+
+	// Recover logs a single panic entry with the message passed to it and PanicValueKey, PanicTypeKey,
+	// PanicStacktraceKey and GoroutineIDKey fields instead of Trace of the value and the entry formatted
+	// by RecoverFormat, so panics can be aggregated by type and searched by value
+	RecoverStructured bool `env:"LOGGER_RECOVER_STRUCTURED"`
+
+	// Syncs outputs in background with the interval if set, bounds data loss for buffered outputs.
+	// Stopped by Close.
+	FlushInterval time.Duration `env:"LOGGER_FLUSH_INTERVAL"`
+
+	// Decorates cores of outputs, e.g. promadapter metrics. Not used if nil.
+	CoreWrapper CoreWrapper
+
+	// Called once for every written entry before outputs encode it, returns fields of the entry, e.g. with a hash
+	// or a signature appended. The entry may be modified too. Gets fields passed with the entry and "seq",
+	// but not ones added by With or config, they are encoded beforehand. Not used if nil.
+	EncoderHook func(ent *zapcore.Entry, fields []zapcore.Field) []zapcore.Field
+
+	// Called with the output name (OutputStdout, OutputLogstash, ...) when writing an entry to it fails, e.g. to count
+	// or alert on lost entries. Errors are also printed to stderr. Failed writes of entries logged by the callback
+	// itself are only printed, ones of other goroutines are reported even while it runs. Not used if nil.
+	OnWriteError func(sink string, err error)
+
+	// Healthy of the logger reports outputs failing for longer than the threshold, DefaultHealthThreshold if zero
+	HealthThreshold time.Duration `env:"LOGGER_HEALTH_THRESHOLD"`
+
+	// Called once after the first fatal entry is written and before the process exits, e.g. to close database
+	// or flush metrics. Fatal entries logged by the hook exit without calling it again. Not used if nil.
+	OnFatal func()
+
+	// Separates elements of FormatPretty entries, tab if empty
+	ConsoleSeparator string `env:"LOGGER_CONSOLE_SEPARATOR"`
+
+	// Colors levels of FormatPretty entries, e.g. for terminals. LevelColors overrides DefaultLevelColors per level
+	// ("debug", "info", ...) with a color name ("red", "cyan", ...) or ANSI code ("1;31").
+	Color       bool              `env:"LOGGER_COLOR"`
+	LevelColors map[string]string `env:"LOGGER_LEVEL_COLORS"`
+
+	// Adds "caller" field with file and line of the logging call
+	Caller bool `env:"LOGGER_CALLER"`
+
+	// Adds "stacktrace" field to entries of the level and above, e.g. "error". Not used if empty.
+	StacktraceLevel string `env:"LOGGER_STACKTRACE_LEVEL"`
+
+	// Limits repeated entries like zap production preset: every second first 100 entries
+	// with the same level and message are logged, then only every 100th of them.
+	Sampling bool `env:"LOGGER_SAMPLING"`
+
+	// Writes level of JSON entries as Datadog "status" attribute ("warning", "critical", ...) instead of "level".
+	// Use DatadogTraceFields to correlate entries with APM traces.
+	DatadogCompat bool `env:"LOGGER_DATADOG_COMPAT"`
+
+	// Infrastructure tags added to every entry like service, e.g. for cross-region correlation.
+	// Empty values are taken from well-known variables: AWS_REGION, AWS_DEFAULT_REGION or GOOGLE_CLOUD_REGION
+	// for region, AWS_AVAILABILITY_ZONE or GOOGLE_CLOUD_ZONE for zone, ECS_CLUSTER or CLUSTER_NAME for cluster.
+	// Tags which are not found are omitted.
+	Region           string `env:"LOGGER_REGION"`
+	AvailabilityZone string `env:"LOGGER_AVAILABILITY_ZONE"`
+	Cluster          string `env:"LOGGER_CLUSTER"`
+
+	// Writes JSON entries with Elastic Common Schema keys for Kibana: "log.level", "service.name", "ecs.version",
+	// caller as "log.origin" fields and stacktrace as "error.stack_trace". Service is renamed in all outputs.
+	ECSCompat bool `env:"LOGGER_ECS_COMPAT"`
+
+	// Omits message key of JSON entries with empty message, e.g. logged by Event
+	OmitEmptyMessage bool `env:"LOGGER_OMIT_EMPTY_MESSAGE"`
+
+	// For CLI tools mixing entries and prompts: every stdout entry is written at once starting on a new line
+	// and synced before the next one. Output written through Stdout shares the lock, so it doesn't interleave.
+	Interactive bool `env:"LOGGER_INTERACTIVE"`
+
+	// Buffers stdout entries, so concurrent logging calls don't wait for each other's write syscalls. Buffer is
+	// flushed when DefaultAsyncStdoutBufferSize bytes fill up, every DefaultAsyncStdoutFlushInterval, on Sync, Close
+	// and before panic and fatal entries return. Entries still in the buffer are lost if the process crashes or
+	// exits without Close. Can't be used with Interactive.
+	AsyncStdout bool `env:"LOGGER_ASYNC_STDOUT"`
 }
 
 var DefaultConfig = LoggingConfig{
@@ -39,19 +240,74 @@ var DefaultConfig = LoggingConfig{
 	Level:     "debug",
 	Namespace: "awesome-namespace",
 
+	AnnounceConfig: true,
+
 	DisableStdout: false,
 	FormatStdout:  FormatJSON,
 
 	// Not used by default
 	LogstashURI:      "",
 	LogstashProtocol: "udp",
+
+	SortKeys: false,
+	Sequence: false,
+
+	// Not used by default
+	FlushInterval: 0,
+
+	Caller:          false,
+	StacktraceLevel: "",
+	Sampling:        false,
+
+	// Tab if empty
+	ConsoleSeparator: "",
+}
+
+// Preset for local development, mirrors zap development preset
+var DevelopmentConfig = LoggingConfig{
+	Service:   DefaultConfig.Service,
+	Level:     "debug",
+	Namespace: DefaultConfig.Namespace,
+
+	AnnounceConfig: true,
+
+	FormatStdout:     FormatPretty,
+	LogstashProtocol: DefaultConfig.LogstashProtocol,
+
+	Caller:          true,
+	StacktraceLevel: "warn",
+}
+
+// Preset for production, mirrors zap production preset
+var ProductionConfig = LoggingConfig{
+	Service:   DefaultConfig.Service,
+	Level:     "info",
+	Namespace: DefaultConfig.Namespace,
+
+	AnnounceConfig: true,
+
+	FormatStdout:     FormatJSON,
+	LogstashProtocol: DefaultConfig.LogstashProtocol,
+
+	Caller:          true,
+	StacktraceLevel: "error",
+	Sampling:        true,
 }
 
 var (
 	FormatJSON   = "json"
 	FormatPretty = "pretty"
+
+	// JSON with keys and severities expected by Google Cloud Logging
+	FormatGCP = "gcp"
 )
 
+// Separates namespaces joined by AppendNamespace
+const NamespaceSeparator = "/"
+
+// Logger is safe for concurrent use. Methods deriving new loggers (With, Namespace, etc.)
+// never modify the receiver, so both parent and derived loggers can be shared between goroutines.
+// The zero value of the implementation discards entries, loggers should be built by New though.
 type Logger interface {
 	Debug(message ...interface{})
 	Debugf(format string, args ...interface{})
@@ -65,228 +321,1105 @@ type Logger interface {
 	Error(message ...interface{})
 	Errorf(format string, args ...interface{})
 
+	// Same as Typed().Info(msg, fields...) without building the typed logger, for hot paths
+	DebugZ(msg string, fields ...Field)
+	InfoZ(msg string, fields ...Field)
+	WarnZ(msg string, fields ...Field)
+	ErrorZ(msg string, fields ...Field)
+
 	Panic(message ...interface{})
 	Panicf(format string, args ...interface{})
 
 	Fatal(message ...interface{})
 	Fatalf(format string, args ...interface{})
 
-	// Add extra fields to message
+	// Add extra fields to message. With(nil) and empty fields return the logger unchanged,
+	// nil values are logged as JSON null.
 	With(fields Fields) Logger
 
+	// Same as With, but builds the underlying zap logger right away instead of on the first entry,
+	// for loggers created once and used for many entries, e.g. per connection or worker
+	WithReuse(fields Fields) Logger
+
+	// Add extra fields computed only if the entry is actually emitted, e.g. for heavy debug-only fields.
+	// Nil function is ignored.
+	WithLazy(fn func() Fields) Logger
+
 	// Override namespace
 	Namespace(namespace string) Logger
 
+	// Append sub namespace to the current one, e.g. "orders" becomes "orders/payments"
+	AppendNamespace(sub string) Logger
+
+	// Add component field (see ComponentKey) naming a subsystem of the service, e.g. "sql" or "kafka".
+	// Empty name is ignored.
+	WithComponent(name string) Logger
+
+	// Add error.class field (see ErrorClassKey) grouping errors for alerting, e.g. ErrorClassTransient or
+	// ClassifyError(err). Empty class is ignored.
+	WithErrorClass(class string) Logger
+
+	// Add tenant_id field with tenant stored by ContextWithTenant, logger is returned unchanged if there is none
+	WithTenant(ctx context.Context) Logger
+
+	// Add user_id field with user stored by ContextWithUser, logger is returned unchanged if there is none
+	WithUser(ctx context.Context) Logger
+
+	// Add fields pushed to the context by PushField, e.g. by middlewares and callers up the flow,
+	// logger is returned unchanged if there are none
+	WithMDC(ctx context.Context) Logger
+
+	// Add ContextErrorKey and ContextCauseKey fields with ctx.Err() and the cause of cancellation if the context is done,
+	// logger is returned unchanged otherwise
+	WithContextError(ctx context.Context) Logger
+
+	// Add retention field in days (see TTLKey), e.g. to route short-lived debug entries to a shorter-retention index.
+	// Partial days are rounded up, negative durations are ignored.
+	WithTTL(d time.Duration) Logger
+
+	// Writes only the first entry of the key process-wide, e.g. for deprecation warnings. Keys are shared by
+	// loggers of the process, so callers should prefix them, e.g. "orders.legacy-api".
+	Once(key string) Logger
+
+	// Writes one of every n entries of the key process-wide, the first one included, with SuppressedCountKey field
+	// counting entries suppressed before it, e.g. for warnings of retry loops. Logger is returned unchanged for n <= 1.
+	Every(key string, n int) Logger
+
+	// Same as Every, but writes at most one entry of the key per d. Logger is returned unchanged for d <= 0.
+	EveryDuration(key string, d time.Duration) Logger
+
+	// Reports caller n frames further up the stack, for adapters and helpers logging on behalf of their callers,
+	// so the caller field points to the calling code instead of the adapter. Logger is returned unchanged for n == 0.
+	WithCallerSkip(n int) Logger
+
+	// Logs entry with the fields and empty message at info level, e.g. for event pipelines.
+	// Message key is omitted with OmitEmptyMessage.
+	Event(fields Fields)
+
 	// Logs call stack for error
 	Trace(err error)
 
+	// Logs message at error level with ErrorKey field and returns err unchanged, no-op for nil:
+	// return log.LogErr(err, "failed to save"). Use Trace for the stack of the error.
+	LogErr(err error, msg string) error
+
 	// Tries to recover from panic. Logs trace of error if occurred and calls Panic with passed message
+	// formatted by RecoverFormat, the entry has PanicKey and PanicStackKey fields. See RecoverStructured
+	// of LoggingConfig for a single entry with the panic in fields.
 	// Like any recover should be deferred
 	Recover(msg string)
 
+	// Returns value of the field added with With and similar methods, or of the field added to all entries by config,
+	// e.g. service or region, unless shadowed by the former. Matches what is encoded, e.g. service added by With
+	// is shadowed by the config one unless FieldCollisionOverride is used.
 	GetField(field string) (interface{}, bool)
+
+	// Returns copy of fields added with With and similar methods and of fields added to all entries by config,
+	// shadowed like by GetField. Lazy fields aren't included.
+	GetFields() Fields
+
+	// Add fields of the other logger (see GetFields). The receiver's fields take precedence on conflicting keys,
+	// so e.g. namespace is never taken from other. Neither are fields added by the receiver's config, so they
+	// aren't copied into fields of the logger, where they would shadow ones replaced by Reconfigure.
+	MergeFrom(other Logger) Logger
+
+	// Reports whether entries of the level (e.g. "debug") would be logged, unknown levels are never enabled
+	Enabled(level string) bool
+
+	// Flushes buffered entries
+	Sync() error
+
+	// Stops background flushing, syncs and closes network outputs.
+	// Affects all loggers derived from the same New call, calling it more than once is no-op.
+	Close() error
+
+	// Reports whether the last write of every output, e.g. stdout or HTTP, succeeded and network outputs (logstash)
+	// are connected, e.g. for readiness probes. Unlike Healthy, failures are reported right away.
+	SinkHealthy() bool
+
+	// Counters of entries by level and of outputs, e.g. write errors and queue depth, shared by all loggers
+	// derived from the same New call. Empty for loggers which aren't built by New.
+	Stats() LoggerStats
+
+	// Returns error describing outputs failing for longer than LoggingConfig.HealthThreshold, nil if none
+	Healthy() error
+
+	// Zeroes counters of Stats, e.g. between tests
+	ResetStats()
+
+	// Writer logs every written line as a separate entry of the level, "info" for unknown levels.
+	// Should be closed or synced to flush the last line without trailing newline.
+	Writer(level string) *LineWriter
+
+	// Standard library logger on top of Writer, e.g. for http.Server.ErrorLog. Caller field points to the code calling it.
+	StdLogger(level string) *log.Logger
+
+	// Logs metrics in AWS CloudWatch embedded metric format at info level.
+	// Metric values must be numbers, dimension values are converted to strings.
+	EMF(namespace string, metrics Fields, dimensions Fields) error
+
+	// Writes the event synchronously to the audit output (see LoggingConfig.AuditFile) with AuditNamespace and fields
+	// of the logger and the call, which must include non-empty actor, action and target (see ValidateAudit).
+	// Returns error if the entry is invalid or isn't written, e.g. to abort the audited operation.
+	Audit(event string, fields Fields) error
+
+	// Returns strongly-typed logger with the same fields for hot paths
+	Typed() TypedLogger
+
+	// Starts recording entries of all levels logged by loggers derived from the same New call, e.g. for debugging
+	// a tricky flow. Entries are still written to outputs if their level is enabled.
+	// stop ends the session and returns recorded entries in order, sessions may overlap.
+	Record() (stop func() []Entry)
 }
 
 type loggerImpl struct {
-	base *zap.SugaredLogger
+	// Desugared, fields are converted once when they change instead of on every entry
+	base *zap.Logger
+
+	// Extra fields except namespace, immutable and shared with derived loggers
+	fields *fieldLayers
+
+	// Namespace is replaced far more often than other fields, so it's kept apart from them. Nil if not set.
+	namespace *namespaceField
+
+	// Core of base for level checks
+	core zapcore.Core
+
+	// base with fields converted to zap fields, built on the first entry and shared by copies of the logger
+	// until fields change
+	prepared *preparedLogger
+
+	// base with fields except namespace, shared by loggers differing only in namespace
+	withoutNamespace *preparedLogger
+
+	// Extra fields computed only for entries passing the level check
+	lazy []func() Fields
+
+	stack StackFormatter
+
+	// Format of Recover panic entries, DefaultRecoverFormat if empty
+	recoverFormat string
 
-	// Extra fields
-	fields Fields
+	// See LoggingConfig.RecoverStructured
+	recoverStructured bool
+
+	// Copy mutable values passed to With, see LoggingConfig.DeepCopyFields
+	deepCopy bool
+
+	// Resolves fields colliding with ones of the logger, shared by all derived loggers. Nil for test ones.
+	collisions *collisionState
+
+	// Service and fields added to all entries by the core, e.g. tags, replaced by Reconfigure.
+	// Fields are looked up by GetField after fields of the logger. Shared by all derived loggers, nil for test ones.
+	reconfig *reconfigState
+
+	// Shared by all derived loggers
+	closer *closer
+
+	// Shared by all derived loggers
+	recorder *recorder
+
+	// Counters of Stats, shared by all derived loggers, nil for test ones
+	stats *statsState
+
+	// Output of Audit, shared by all derived loggers. Nil if not configured.
+	audit *auditState
+
+	// Shared by all derived loggers, nil for test ones
+	debugFile *debugFileState
+}
+
+var _ Logger = loggerImpl{}
+
+// preparedLogger caches zap logger with fields, so static loggers don't re-encode fields on every entry
+type preparedLogger struct {
+	// *zap.SugaredLogger for prepared and *zap.Logger for withoutNamespace,
+	// concurrent first entries may build it twice with the same result
+	logger atomic.Value
+
+	// *zap.Logger of Typed and level methods taking fields, only for prepared
+	typed atomic.Value
+}
+
+// namespaceField is namespace with its zap field built once
+type namespaceField struct {
+	value string
+	field zap.Field
+}
+
+func newNamespaceField(namespace string) *namespaceField {
+	return &namespaceField{value: namespace, field: zap.String("namespace", namespace)}
 }
 
 func (l loggerImpl) prepare() *zap.SugaredLogger {
-	flatten := l.fields.Flatten()
+	if l.prepared != nil {
+		if prepared, ok := l.prepared.logger.Load().(*zap.SugaredLogger); ok {
+			return prepared
+		}
+	}
 
-	prepared := l.base.With(flatten...)
+	var prepared *zap.SugaredLogger
+	if atomic.LoadInt32(&deterministicFlatten) == 1 {
+		// Namespace is sorted with other fields
+		fields, collision := l.allFields().zapFields(l.collisionPolicy())
+		l.reportCollision(collision)
+		prepared = l.zapLogger().With(fields...).Sugar()
+	} else {
+		base := l.prepareWithoutNamespace()
+		if l.namespace != nil {
+			base = base.With(l.namespace.field)
+		}
+		prepared = base.Sugar()
+	}
 
-	putFlatten(flatten)
+	if l.prepared != nil {
+		l.prepared.logger.Store(prepared)
+	}
+	return prepared
+}
+
+func (l loggerImpl) prepareWithoutNamespace() *zap.Logger {
+	if l.withoutNamespace != nil {
+		if prepared, ok := l.withoutNamespace.logger.Load().(*zap.Logger); ok {
+			return prepared
+		}
+	}
 
+	fields, collision := l.fields.fields().zapFields(l.collisionPolicy())
+	l.reportCollision(collision)
+	prepared := l.zapLogger().With(fields...)
+
+	if l.withoutNamespace != nil {
+		l.withoutNamespace.logger.Store(prepared)
+	}
 	return prepared
 }
 
+// zapLogger returns base of the logger, no-op one for the zero value of loggerImpl
+func (l loggerImpl) zapLogger() *zap.Logger {
+	if l.base == nil {
+		return zap.NewNop()
+	}
+	return l.base
+}
+
+// allFields returns fields including namespace, must not be modified
+func (l loggerImpl) allFields() Fields {
+	fields := l.fields.fields()
+	if l.namespace == nil {
+		return fields
+	}
+	return fields.Merge(Fields{"namespace": l.namespace.value})
+}
+
+// disabled reports whether entries of the level are dropped by all outputs.
+// Checked first by level methods, so disabled entries don't prepare fields or clone loggers.
+// All entries are dropped by the zero value of loggerImpl.
+func (l loggerImpl) disabled(level zapcore.Level) bool {
+	return l.core == nil || !l.core.Enabled(level)
+}
+
+// withFields replaces fields and drops the cached loggers built with the old ones
+func (l loggerImpl) withFields(fields *fieldLayers) loggerImpl {
+	l.fields = fields
+	l.prepared = &preparedLogger{}
+	l.withoutNamespace = &preparedLogger{}
+	return l
+}
+
+// withNamespace replaces namespace, logger with the other fields stays cached
+func (l loggerImpl) withNamespace(namespace *namespaceField) loggerImpl {
+	l.namespace = namespace
+	l.prepared = &preparedLogger{}
+	return l
+}
+
+// with adds fields, namespace among them is kept apart
+func (l loggerImpl) with(fields Fields) loggerImpl {
+	if l.deepCopy {
+		fields = deepCopyFields(fields)
+	}
+
+	namespace, ok := fields["namespace"]
+	if !ok {
+		return l.withFields(l.fields.with(fields))
+	}
+
+	l = l.withNamespace(newNamespaceField(namespaceString(namespace)))
+	if len(fields) == 1 {
+		return l
+	}
+
+	others := fields.Copy()
+	delete(others, "namespace")
+	return l.withFields(l.fields.with(others))
+}
+
+// namespaceString converts namespace set by With, it's always a string
+func namespaceString(value interface{}) string {
+	value = fieldValue(value)
+	if namespace, ok := value.(string); ok {
+		return namespace
+	}
+	return fmt.Sprint(value)
+}
+
+// skipCaller reports caller n frames further, for methods logging through other methods
+func (l loggerImpl) skipCaller(n int) loggerImpl {
+	return l.withZapOptions(zap.AddCallerSkip(n))
+}
+
+// withZapOptions applies options to base, dropping the cached loggers built with the old one
+func (l loggerImpl) withZapOptions(opts ...zap.Option) loggerImpl {
+	l.base = l.zapLogger().WithOptions(opts...)
+	l.prepared = &preparedLogger{}
+	l.withoutNamespace = &preparedLogger{}
+	return l
+}
+
 func (l loggerImpl) Debug(message ...interface{}) {
+	if l.disabled(zapcore.DebugLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.DebugLevel, "", message)
+		return
+	}
 	l.prepare().Debug(message...)
 }
 
 func (l loggerImpl) Debugf(format string, args ...interface{}) {
+	if l.disabled(zapcore.DebugLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.DebugLevel, format, args)
+		return
+	}
 	l.prepare().Debugf(format, args...)
 }
 
 func (l loggerImpl) Info(message ...interface{}) {
+	if l.disabled(zapcore.InfoLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.InfoLevel, "", message)
+		return
+	}
 	l.prepare().Info(message...)
 }
 
 func (l loggerImpl) Infof(format string, args ...interface{}) {
+	if l.disabled(zapcore.InfoLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.InfoLevel, format, args)
+		return
+	}
 	l.prepare().Infof(format, args...)
 }
 
 func (l loggerImpl) Warn(message ...interface{}) {
+	if l.disabled(zapcore.WarnLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.WarnLevel, "", message)
+		return
+	}
 	l.prepare().Warn(message...)
 }
 
 func (l loggerImpl) Warnf(format string, args ...interface{}) {
+	if l.disabled(zapcore.WarnLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.WarnLevel, format, args)
+		return
+	}
 	l.prepare().Warnf(format, args...)
 }
 
 func (l loggerImpl) Error(message ...interface{}) {
+	if l.disabled(zapcore.ErrorLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.ErrorLevel, "", message)
+		return
+	}
 	l.prepare().Error(message...)
 }
 
 func (l loggerImpl) Errorf(format string, args ...interface{}) {
+	if l.disabled(zapcore.ErrorLevel) {
+		return
+	}
+	if l.lazy != nil {
+		l.logLazy(zapcore.ErrorLevel, format, args)
+		return
+	}
 	l.prepare().Errorf(format, args...)
 }
 
 func (l loggerImpl) Panic(message ...interface{}) {
+	if l.lazy != nil {
+		l.logLazy(zapcore.PanicLevel, "", message)
+		return
+	}
 	l.prepare().Panic(message...)
 }
 
 func (l loggerImpl) Panicf(format string, args ...interface{}) {
+	if l.lazy != nil {
+		l.logLazy(zapcore.PanicLevel, format, args)
+		return
+	}
 	l.prepare().Panicf(format, args...)
 }
 
 func (l loggerImpl) Fatal(message ...interface{}) {
+	if l.lazy != nil {
+		l.logLazy(zapcore.FatalLevel, "", message)
+		return
+	}
 	l.prepare().Fatal(message...)
 }
 
 func (l loggerImpl) Fatalf(format string, args ...interface{}) {
+	if l.lazy != nil {
+		l.logLazy(zapcore.FatalLevel, format, args)
+		return
+	}
 	l.prepare().Fatalf(format, args...)
 }
 
 func (l loggerImpl) With(fields Fields) Logger {
-	l.fields = l.fields.Merge(fields)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.with(fields)
+}
 
+func (l loggerImpl) WithReuse(fields Fields) Logger {
+	if len(fields) > 0 {
+		l = l.with(fields)
+	}
+	// Caches both the sugared and the typed logger, see BenchmarkLoggerImpl_FirstEntryWithReuse
+	l.typed()
 	return l
 }
 
 func (l loggerImpl) Namespace(namespace string) Logger {
-	l.fields = l.fields.Merge(Fields{"namespace": namespace})
+	return l.withNamespace(newNamespaceField(namespace))
+}
 
-	return l
+func (l loggerImpl) AppendNamespace(sub string) Logger {
+	var current string
+	if l.namespace != nil {
+		current = l.namespace.value
+	}
+	if current == "" {
+		return l.Namespace(sub)
+	}
+	if sub == "" {
+		return l
+	}
+
+	return l.Namespace(current + NamespaceSeparator + sub)
 }
 
 func (l loggerImpl) GetField(fieldName string) (value interface{}, ok bool) {
-	value, ok = l.fields[fieldName]
+	if fieldName == "namespace" {
+		if l.namespace == nil {
+			return nil, false
+		}
+		return l.namespace.value, true
+	}
+
+	if l.encodesField(fieldName) {
+		if value, ok = l.fields.get(fieldName); ok {
+			return fieldValue(value), true
+		}
+	}
+
+	value, ok = l.general().fields[fieldName]
 	return value, ok
 }
 
+// encodesField reports whether the field of the logger is encoded under its name. Colliding ones are dropped
+// or renamed, except service with FieldCollisionOverride.
+func (l loggerImpl) encodesField(fieldName string) bool {
+	_, colliding := ignore[fieldName]
+	return !colliding || fieldName == "service" && l.collisionPolicy() == FieldCollisionOverride
+}
+
+func (l loggerImpl) Enabled(level string) bool {
+	zapLevel, err := ParseLevel(level)
+	if err != nil {
+		return false
+	}
+
+	return !l.disabled(zapLevel)
+}
+
 func New(config LoggingConfig) (logger Logger, err error) {
-	level := config.Level
-	if level == "" {
-		log.Println("logging level not set, using 'info'")
-		level = "info"
+	if config.StdoutWriter != nil {
+		return newLogger(config, zapcore.Lock(config.StdoutWriter))
+	}
+	if config.Interactive {
+		return newLogger(config, interactiveStdout.entries())
 	}
+	return newLogger(config, zapcore.Lock(stdoutFile{os.Stdout}))
+}
+
+// Builds logger from DevelopmentConfig
+func NewDevelopment() (Logger, error) {
+	return New(DevelopmentConfig)
+}
 
-	format, err := getFormat(config.FormatStdout)
+// Builds logger from ProductionConfig
+func NewProduction() (Logger, error) {
+	return New(ProductionConfig)
+}
+
+// Must is like New but panics on invalid config, e.g. for main functions
+func Must(config LoggingConfig) Logger {
+	logger, err := New(config)
 	if err != nil {
-		return nil, err
+		panic(err)
 	}
+	return logger
+}
 
-	zapLevel, err := getLevel(level)
+// NewBasic returns logger writing pretty entries of the level and above into w, e.g. for quick scripts
+// not worth a config. Service is the program name. Panics on unknown level like Must.
+func NewBasic(w io.Writer, level string) Logger {
+	logger, err := newLogger(LoggingConfig{
+		Service:      filepath.Base(os.Args[0]),
+		Level:        level,
+		FormatStdout: FormatPretty,
+	}, zapcore.Lock(zapcore.AddSync(w)))
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
+// NewWithCore returns logger writing entries to the core instead of outputs of LoggingConfig, e.g. for test loggers
+// like logtest.NewTB. Record works like with loggers built by New, Reconfigure and Stats don't.
+func NewWithCore(core zapcore.Core, opts ...zap.Option) Logger {
+	rec := &recorder{}
+	zapLogger := zap.New(newRecordCore(core, rec, nil), opts...)
+
+	impl := loggerImpl{
+		base:     zapLogger,
+		core:     zapLogger.Core(),
+		stack:    DefaultStackFormatter,
+		closer:   newCloser(zapLogger, 0, nil),
+		recorder: rec,
+	}.withFields(newFieldLayers(nil))
+
+	return &impl
+}
+
+// newLogger builds a logger writing its stdout output into the passed syncer.
+func newLogger(config LoggingConfig, stdout zapcore.WriteSyncer) (logger Logger, err error) {
+	stats := newStatsState(config.HealthThreshold)
+	debugFile := newDebugFileState()
+	zapLogger, closers, err := newConfiguredZapLogger(config, stdout, stats, debugFile)
 	if err != nil {
 		return nil, err
 	}
 
-	zapLogger, err := newZapLogger(
-		zapLevel,
-		config.Service,
-		config.LogstashProtocol, config.LogstashURI,
-		config.DisableStdout,
-		format,
-	)
+	// Outputs kept by Reconfigure, closed with the logger
+	var kept []io.Closer
+	closeAll := func() {
+		for _, c := range append(closers, kept...) {
+			_ = c.Close()
+		}
+	}
+
+	audit, err := newAuditState(config)
 	if err != nil {
+		closeAll()
 		return nil, err
 	}
+	if audit != nil {
+		kept = append(kept, audit)
+	}
+
+	// Announced before the debug file enables all levels, it's reported separately
+	level := loggerImpl{core: zapLogger.Core()}.minLevel()
+
+	kept = append(kept, debugFile)
+	if config.DebugFilePath != "" {
+		if err := debugFile.enable(config.DebugFilePath); err != nil {
+			closeAll()
+			return nil, err
+		}
+	}
+
+	stack := config.StackFormatter
+	switch {
+	case stack != nil:
+	case config.StructuredStack:
+		stack = StructuredStackFormatter
+	default:
+		stack = DefaultStackFormatter
+	}
+
+	// Outermost but the swap, so entries are recorded before sampling and level checks of outputs.
+	// Reconfigure replaces the core behind the swap, loggers derived from this one keep it.
+	rec := &recorder{}
+	swap := newSwapCore(newRecordCore(zapLogger.Core(), rec, generalFields(config)))
+	zapLogger = zapLogger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return swap
+	}))
+
+	impl := loggerImpl{
+		base:              zapLogger,
+		core:              zapLogger.Core(),
+		stack:             stack,
+		recoverFormat:     config.RecoverFormat,
+		recoverStructured: config.RecoverStructured,
+		deepCopy:          config.DeepCopyFields,
+		collisions:        newCollisionState(config.FieldCollision),
+		reconfig:          newReconfigState(swap.state, stdout, config),
+		closer:            newCloser(zapLogger, config.FlushInterval, closers, kept...),
+		recorder:          rec,
+		stats:             stats,
+		audit:             audit,
+		debugFile:         debugFile,
+	}.withFields(newFieldLayers(nil)).withNamespace(newNamespaceField(config.Namespace))
+
+	if config.AnnounceConfig {
+		impl.announce(config, level)
+	}
+	return &impl, nil
+}
+
+// newConfiguredZapLogger validates the config and builds zap logger with its outputs
+func newConfiguredZapLogger(
+	config LoggingConfig,
+	stdout zapcore.WriteSyncer,
+	stats *statsState,
+	debugFile *debugFileState,
+) (*zap.Logger, []io.Closer, error) {
+	level := config.Level
+	if strings.TrimSpace(level) == "" {
+		log.Println("logging level not set, using 'info'")
+		level = "info"
+	}
+
+	format, err := ParseFormat(config.FormatStdout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	zapLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if config.DatadogCompat && config.ECSCompat {
+		return nil, nil, errors.New("DatadogCompat and ECSCompat can't be used together")
+	}
 
-	logger = &loggerImpl{
-		base:   zapLogger.Sugar(),
-		fields: Fields{"namespace": config.Namespace},
+	if config.AsyncStdout && config.Interactive {
+		return nil, nil, errors.New("AsyncStdout and Interactive can't be used together")
 	}
 
-	return logger, nil
+	if config.LogstashURI != "" && !isLogstashProtocol(config.LogstashProtocol) {
+		return nil, nil, fmt.Errorf("invalid LogstashProtocol %q, must be tcp, udp or unix", config.LogstashProtocol)
+	}
+
+	switch config.FanOutPolicy {
+	case "", FanOutBlock, FanOutDrop:
+	default:
+		return nil, nil, fmt.Errorf("invalid FanOutPolicy %v, must be %v or %v", config.FanOutPolicy, FanOutBlock, FanOutDrop)
+	}
+
+	switch config.FieldCollision {
+	case "", FieldCollisionIgnore, FieldCollisionOverride, FieldCollisionRename:
+	default:
+		return nil, nil, fmt.Errorf("invalid FieldCollision %v, must be %v, %v or %v",
+			config.FieldCollision, FieldCollisionIgnore, FieldCollisionOverride, FieldCollisionRename)
+	}
+
+	switch config.FieldConflict {
+	case "", FieldConflictLast, FieldConflictFirst, FieldConflictError:
+	default:
+		return nil, nil, fmt.Errorf("invalid FieldConflict %v, must be %v, %v or %v",
+			config.FieldConflict, FieldConflictLast, FieldConflictFirst, FieldConflictError)
+	}
+
+	return newZapLogger(zapLevel, format, stdout, config, stats, debugFile)
 }
 
 func newZapLogger(
 	zapLevel zapcore.Level,
-	service string,
-	logstashProtocol, logstashURI string,
-	disableStdout bool,
 	formatStdout string,
-) (*zap.Logger, error) {
+	stdout zapcore.WriteSyncer,
+	config LoggingConfig,
+	stats *statsState,
+	debugFile *debugFileState,
+) (*zap.Logger, []io.Closer, error) {
+	var options []zap.Option
+	if config.Caller {
+		// Skips loggerImpl method calling sugared logger
+		options = append(options, zap.AddCaller(), zap.AddCallerSkip(1))
+	}
+	if config.StacktraceLevel != "" {
+		stacktraceLevel, err := ParseLevel(config.StacktraceLevel)
+		if err != nil {
+			return nil, nil, err
+		}
+		options = append(options, zap.AddStacktrace(stacktraceLevel))
+	}
+
+	// Write errors of outputs are printed by zap
+	options = append(options, zap.ErrorOutput(zapcore.Lock(os.Stderr)))
+
 	var cores []zapcore.Core
+	var closers []io.Closer
+
+	// Counters of outputs and their writers reporting queues and connections, replace ones of Stats
+	// once all outputs are built
+	sinks := map[string]*sinkStats{}
+	sources := map[string][]io.Closer{}
+
+	// Decorates cores of outputs with counters of Stats, CoreWrapper and reporting of write errors
+	wrapOutputCore := func(name string, core zapcore.Core, writers ...io.Closer) zapcore.Core {
+		sink := stats.sink(name)
+		sinks[name] = sink
+		sources[name] = writers
+		core = newSinkStatsCore(core, sink)
+		core = wrapOutput(config.CoreWrapper, name, core)
+		if config.OnWriteError != nil {
+			core = newWriteErrorCore(core, name, config.OnWriteError)
+		}
+		return core
+	}
+
+	// New passes entries of Stdout, other syncers are serialized on their own
+	if _, ok := stdout.(interactiveEntries); config.Interactive && !ok {
+		stdout = newInteractiveWriter(stdout).entries()
+	}
 
-	if !disableStdout {
-		cores = append(cores, newStdoutCore(zapLevel, formatStdout))
+	var stdoutWriters []io.Closer
+	if config.AsyncStdout && !config.DisableStdout {
+		batch := newBatchWriter(stdout, DefaultAsyncStdoutBufferSize, DefaultAsyncStdoutFlushInterval)
+		closers = append(closers, batch)
+		stdoutWriters = append(stdoutWriters, batch)
+		stdout = batch
+	}
+
+	if !config.DisableStdout {
+		stdoutCore, err := newStdoutCore(zapLevel, formatStdout, stdout, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		cores = append(cores, wrapOutputCore(OutputStdout, stdoutCore, stdoutWriters...))
 	}
 
 	// Optional logstash connection
-	if logstashURI != "" {
+	if config.LogstashURI != "" {
 		log.Println("using logstash, should not be used in production")
-		logstashCore, err := newLogstashCore(zapLevel, logstashProtocol, logstashURI)
+		logstashCore, logstashClosers, err := newLogstashCore(zapLevel, config)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
-		cores = append(cores, logstashCore)
+		cores = append(cores, wrapOutputCore(OutputLogstash, logstashCore, logstashClosers...))
+		closers = append(closers, logstashClosers...)
 	}
 
+	if config.HTTPEndpoint != "" {
+		httpCore, httpCloser, err := newHTTPCore(zapLevel, config)
+		if err != nil {
+			for _, c := range closers {
+				_ = c.Close()
+			}
+			return nil, nil, err
+		}
+		cores = append(cores, wrapOutputCore(OutputHTTP, httpCore, httpCloser))
+		closers = append(closers, httpCloser)
+	}
+
+	if config.NATSURL != "" || config.NATSPublisher != nil {
+		natsCore, natsCloser, err := newNATSCore(zapLevel, config)
+		if err != nil {
+			for _, c := range closers {
+				_ = c.Close()
+			}
+			return nil, nil, err
+		}
+		cores = append(cores, wrapOutputCore(OutputNATS, natsCore, natsCloser))
+		closers = append(closers, natsCloser)
+	}
+
+	if config.Journald {
+		journaldCore, journaldCloser, err := newJournaldCore(zapLevel, config.Service, JournaldSocket)
+		if err != nil {
+			log.Printf("journald is not available, skipping it: %v", err)
+		} else {
+			cores = append(cores, wrapOutputCore(OutputJournald, journaldCore, journaldCloser))
+			closers = append(closers, journaldCloser)
+		}
+	}
+
+	if len(cores) == 0 && config.DebugFilePath == "" {
+		if !config.AllowNoOutputs {
+			return nil, nil, errors.New("no outputs enabled: stdout is disabled, LogstashURI, HTTPEndpoint and NATS " +
+				"are not set and journald is not used, set AllowNoOutputs to log nowhere")
+		}
+		log.Println("no logging outputs enabled, entries are dropped")
+	}
+
+	// Disabled unless a debug file is written, so loggers can enable it at runtime
+	cores = append(cores, newDebugFileCore(debugFile, config))
+
 	core := zapcore.NewTee(
 		cores...,
 	)
+	core = newLevelStatsCore(core, stats)
 
-	// Add general fields
-	core = core.With(
-		[]zap.Field{
-			zap.String("service", service),
-		},
-	)
+	// Inside the hook, so fields it adds are resolved too
+	if config.FieldConflict != "" {
+		core = newConflictCore(core, config.FieldConflict)
+	}
+
+	// Inside sequence counter and entry ids, so the hook gets them too
+	if config.EncoderHook != nil {
+		core = newEncoderHookCore(core, config.EncoderHook)
+	}
+
+	if config.Sequence {
+		core = newSeqCore(core)
+	}
+
+	if config.EntryID {
+		core = newEntryIDCore(core)
+	}
+
+	// Inside dedup like Stats, so wrappers count written entries, not suppressed ones
+	if config.CoreWrapper != nil {
+		core = config.CoreWrapper.WrapCore(core)
+	}
+
+	// Outside sequence counter and entry ids, so suppressed entries don't consume them
+	if config.DedupConsecutive {
+		core = newDedupCore(core)
+	}
+
+	// Add general fields, service may be replaced by fields of loggers
+	if config.FieldCollision == FieldCollisionOverride {
+		core = newShadowCore(core, generalFields(config))
+	} else {
+		core = core.With(generalFields(config))
+	}
+
+	// Sampler goes last to drop entries before they reach sequence counter and wrappers
+	if config.Sampling {
+		core = zapcore.NewSampler(core, time.Second, 100, 100)
+	}
+
+	// Wraps sampler too, so the hook runs even if the fatal entry is sampled out
+	if config.OnFatal != nil {
+		core = newFatalHookCore(core, config.OnFatal)
+	}
+
+	// Outside sampler, so empty messages are reported even if their entries are sampled out
+	if config.WarnOnEmptyMessage {
+		core = newEmptyMessageCore(core)
+	}
 
-	zapLogger := zap.New(core)
+	options = append(options, zap.OnFatal(fatalAction))
+	zapLogger := zap.New(core, options...)
 
-	return zapLogger, nil
+	stats.replaceSinks(sinks, sources)
+	return zapLogger, closers, nil
 }
 
-func newStdoutCore(zapLevel zapcore.Level, format string) zapcore.Core {
+// generalFields returns fields added to all entries by the core
+func generalFields(config LoggingConfig) []zap.Field {
+	return append([]zap.Field{
+		zap.String(serviceKey(config), config.Service),
+	}, tagFields(config)...)
+}
+
+func newStdoutCore(
+	zapLevel zapcore.Level,
+	format string,
+	console zapcore.WriteSyncer,
+	config LoggingConfig,
+) (zapcore.Core, error) {
 	levelEnabler := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
 		return level >= zapLevel
 	})
 
-	console := zapcore.Lock(os.Stdout)
-
 	var encoder zapcore.Encoder
-	encoderConfig := newEncoderConfig()
-	if format == FormatJSON {
-		encoder = zapcore.NewJSONEncoder(encoderConfig)
-	} else {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	switch format {
+	case FormatJSON:
+		return newCompatJSONCore(console, levelEnabler, config), nil
+	case FormatGCP:
+		return newJSONCore(console, levelEnabler, newGCPEncoderConfig(), config.SortKeys, config.OmitEmptyMessage), nil
+	case FormatPretty:
+		// Fields are always sorted for readability
+		encoderConfig := newEncoderConfig()
+		encoderConfig.ConsoleSeparator = config.ConsoleSeparator
+		if config.Color {
+			encodeLevel, err := newColorLevelEncoder(config.LevelColors)
+			if err != nil {
+				return nil, err
+			}
+			encoderConfig.EncodeLevel = encodeLevel
+		}
+		return newSortedConsoleCore(console, levelEnabler, encoderConfig), nil
+	default:
+		constructor, ok := getEncoderConstructor(format)
+		if !ok {
+			return nil, fmt.Errorf("encoder %v is not registered", format)
+		}
+
+		var err error
+		encoder, err = constructor(newEncoderConfig())
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	stdoutCore := zapcore.NewCore(encoder, console, levelEnabler)
 
-	return stdoutCore
+	return stdoutCore, nil
+}
+
+// newCompatJSONCore builds JSON core of stdout and logstash outputs, with Datadog or ECS keys if enabled
+func newCompatJSONCore(ws zapcore.WriteSyncer, enab zapcore.LevelEnabler, config LoggingConfig) zapcore.Core {
+	encoderConfig := newEncoderConfig()
+	switch {
+	case config.DatadogCompat:
+		encoderConfig = newDatadogEncoderConfig(encoderConfig)
+	case config.ECSCompat:
+		return newECSCore(newJSONCore(ws, enab, newECSEncoderConfig(encoderConfig), config.SortKeys, config.OmitEmptyMessage))
+	}
+	return newJSONCore(ws, enab, encoderConfig, config.SortKeys, config.OmitEmptyMessage)
+}
+
+func newJSONCore(
+	ws zapcore.WriteSyncer,
+	enab zapcore.LevelEnabler,
+	encoderConfig zapcore.EncoderConfig,
+	sortKeys bool,
+	omitEmptyMessage bool,
+) zapcore.Core {
+	newCore := func(encoderConfig zapcore.EncoderConfig) zapcore.Core {
+		if sortKeys {
+			return newSortedCore(ws, enab, encoderConfig)
+		}
+		return zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), ws, enab)
+	}
+
+	if !omitEmptyMessage {
+		return newCore(encoderConfig)
+	}
+
+	noMessageConfig := encoderConfig
+	noMessageConfig.MessageKey = ""
+	return newOmitMessageCore(newCore(encoderConfig), newCore(noMessageConfig))
 }
 
-func newLogstashCore(zapLevel zapcore.Level, protocol, addr string) (zapcore.Core, error) {
-	conn, err := net.Dial(protocol, addr)
+func newLogstashCore(
+	zapLevel zapcore.Level,
+	config LoggingConfig,
+) (zapcore.Core, []io.Closer, error) {
+	tlsConfig, err := logstashTLSConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	var closers []io.Closer
+	var sinks []zapcore.WriteSyncer
+	for _, addr := range strings.Split(config.LogstashURI, ",") {
+		var dialed net.Conn
+		if tlsConfig != nil {
+			// Server name is taken from the address
+			dialed, err = tls.Dial(config.LogstashProtocol, strings.TrimSpace(addr), tlsConfig)
+		} else {
+			dialed, err = net.Dial(config.LogstashProtocol, strings.TrimSpace(addr))
+		}
+		if err != nil {
+			for _, c := range closers {
+				_ = c.Close()
+			}
+			return nil, nil, err
+		}
+		conn := newHealthConn(dialed)
+
+		sink := zapcore.AddSync(conn)
+		if batchSize := logstashBatchSize(config); batchSize > 0 {
+			interval := config.LogstashBatchInterval
+			if interval <= 0 {
+				interval = DefaultLogstashBatchInterval
+			}
+			batch := newBatchWriter(sink, batchSize, interval)
+			// Closed first to write buffered entries
+			closers = append(closers, batch)
+			sink = batch
+		}
+
+		closers = append(closers, conn)
+		sinks = append(sinks, sink)
+	}
+
+	tcpWriter := sinks[0]
+	if len(sinks) > 1 {
+		fanOut := NewFanOutWriter(FanOutConfig{QueueSize: config.FanOutQueueSize, Policy: config.FanOutPolicy}, sinks...)
+		tcpWriter = fanOut
+		// Closed first to write queued entries before connections are closed
+		closers = append([]io.Closer{fanOut}, closers...)
 	}
 
 	levelEnabler := zap.LevelEnablerFunc(func(level zapcore.Level) bool {
 		return level >= zapLevel
 	})
 
-	logstashEncoder := zapcore.NewJSONEncoder(newEncoderConfig())
-
-	tcpWriter := zapcore.AddSync(conn)
-
-	logstashCore := zapcore.
-		NewCore(logstashEncoder, tcpWriter, levelEnabler).
+	logstashCore := newCompatJSONCore(tcpWriter, levelEnabler, config).
 		With([]zap.Field{
 			// Extra fields from logrustash formatter, not sure if they are really needed
 			zap.String("@version", "1"),
 			zap.String("type", "log"),
 		})
 
-	return logstashCore, nil
+	return logstashCore, closers, nil
+}
+
+// isLogstashProtocol reports whether logstash can be dialed with the network
+func isLogstashProtocol(protocol string) bool {
+	switch protocol {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+		return true
+	default:
+		return false
+	}
+}
+
+// logstashBatchSize returns buffer size of logstash writes, zero if they aren't batched
+func logstashBatchSize(config LoggingConfig) int {
+	switch {
+	case config.LogstashBatchSize < 0:
+		return 0
+	case !strings.HasPrefix(config.LogstashProtocol, "tcp") && config.LogstashProtocol != "unix":
+		// Batching datagrams would put several entries into one of them
+		return 0
+	case config.LogstashBatchSize == 0:
+		return DefaultLogstashBatchSize
+	default:
+		return config.LogstashBatchSize
+	}
 }
 
 func newEncoderConfig() zapcore.EncoderConfig {
@@ -299,21 +1432,34 @@ func newEncoderConfig() zapcore.EncoderConfig {
 	return logstashEncoderConfig
 }
 
-func getFormat(format string) (string, error) {
+// ParseFormat parses format of stdout like LoggingConfig.FormatStdout, FormatJSON for empty one.
+// Surrounding spaces are ignored, so is case of built-in formats and of encoders registered in lower case.
+func ParseFormat(format string) (string, error) {
+	format = strings.TrimSpace(format)
 	if format == "" {
 		return FormatJSON, nil
 	}
 
-	if format != FormatJSON && format != FormatPretty {
-		return "", fmt.Errorf("invalid FormatStdout %v, must be %v or %v",
-			format, FormatJSON, FormatPretty)
+	if lower := strings.ToLower(format); isBuiltinFormat(lower) {
+		return lower, nil
 	}
 
-	return format, nil
+	if _, ok := getEncoderConstructor(format); ok {
+		return format, nil
+	}
+	if lower := strings.ToLower(format); lower != format {
+		if _, ok := getEncoderConstructor(lower); ok {
+			return lower, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid FormatStdout %q, must be %v, %v, %v or a registered encoder",
+		format, FormatJSON, FormatPretty, FormatGCP)
 }
 
-func getLevel(level string) (zapcore.Level, error) {
-	switch level {
+// ParseLevel parses level name like LoggingConfig.Level, ignoring case and surrounding spaces, e.g. " INFO"
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
 	case "debug":
 		return zapcore.DebugLevel, nil
 	case "info":
@@ -327,24 +1473,64 @@ func getLevel(level string) (zapcore.Level, error) {
 	case "panic":
 		return zapcore.PanicLevel, nil
 	default:
-		return 0, fmt.Errorf("bad logging level %v", level)
+		return 0, fmt.Errorf("bad logging level %q, must be debug, info, warn, error, panic or fatal", level)
 	}
 }
 
-func (l loggerImpl) Trace(err error) {
-	if err != nil {
-		l.Errorf("%+v", errors.WithStack(err))
+func (l loggerImpl) Recover(msg string) {
+	if i := recover(); i != nil {
+		l.logRecovered(msg, i)
 	}
 }
 
-func (l loggerImpl) Recover(msg string) {
-	if i := recover(); i != nil {
-		switch v := i.(type) {
-		case error:
-			l.Trace(v)
-		case string:
-			l.Trace(errors.New(v))
+// Written by Recover when logging the recovered value panics, replaced by tests
+var recoverFallback io.Writer = os.Stderr
+
+// logRecovered logs the value recovered by Recover and panics with the message like Panicf does.
+// If logging panics itself, e.g. an output or the encoder, the value and the stack are written to recoverFallback
+// and the value is panicked with again, so the logging panic doesn't replace the original one.
+func (l loggerImpl) logRecovered(msg string, i interface{}) {
+	// Caller of entries is Recover like when it logged them itself
+	l = l.skipCaller(1)
+
+	format := l.recoverFormat
+	if format == "" {
+		format = DefaultRecoverFormat
+	}
+	message := fmt.Sprintf(format, msg, i)
+	if l.recoverStructured {
+		message = msg
+	}
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if s, ok := r.(string); ok && s == message {
+			// Panic of Panicf
+			panic(r)
 		}
-		l.Panicf("recovered %s from %v", msg, i)
+		_, _ = fmt.Fprintf(recoverFallback, "%s, logging it panicked: %v\n%s", message, r, debug.Stack())
+		panic(i)
+	}()
+
+	if l.recoverStructured {
+		// The entry has its own stacktrace field
+		l.withZapOptions(zap.AddStacktrace(zapcore.FatalLevel + 1)).With(Fields{
+			PanicValueKey:      fmt.Sprint(i),
+			PanicTypeKey:       fmt.Sprintf("%T", i),
+			PanicStacktraceKey: panicStack(i),
+			GoroutineIDKey:     goroutineID(),
+		}).Panic(msg)
+		return
+	}
+
+	switch v := i.(type) {
+	case error:
+		l.Trace(v)
+	case string:
+		l.Trace(errors.New(v))
 	}
+	l.With(Fields{PanicKey: fmt.Sprint(i), PanicStackKey: panicStack(i)}).Panicf(format, msg, i)
 }
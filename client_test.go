@@ -1,9 +1,21 @@
 package logger
 
 import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func Test_mapToSlice(t *testing.T) {
@@ -21,6 +33,7 @@ func Test_mapToSlice(t *testing.T) {
 			want: []interface{}{"1", 1},
 		},
 		{
+			// Flatten always drops them like FieldCollisionIgnore, the default policy of loggers
 			name: "ignore fields",
 			args: args{fields: map[string]interface{}{
 				"1":          1,
@@ -51,6 +64,70 @@ func Test_mapToSlice(t *testing.T) {
 	}
 }
 
+func Test_mapToSlice_Deterministic(t *testing.T) {
+	SetDeterministicFlatten(true)
+	defer SetDeterministicFlatten(false)
+
+	fields := Fields{"b": 2, "a": 1, "service": "ignored", "c": 3, "cached": zap.Bool("cached", true)}
+	want := []interface{}{"a", 1, "b", 2, "c", 3, zap.Bool("cached", true)}
+
+	for i := 0; i < 20; i++ {
+		got := fields.Flatten()
+		assert.Equal(t, want, got)
+	}
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	logger.With(Fields{"z": 1, "y": 2, "x": 3}).Info("stable")
+
+	line := buf.buf.String()
+	assert.Contains(t, line, `"namespace":"default","x":3,"y":2,"z":1}`)
+}
+
+// Fast paths of zapField must produce the same fields as zap.Any
+func Test_zapField(t *testing.T) {
+	for _, value := range []interface{}{"value", 42, int64(42), true, time.Second, 4.2, errors.New("failed"), []int{1}} {
+		assert.Equal(t, zap.Any("key", value), zapField("key", value), "%T", value)
+	}
+	assert.Equal(t, zap.Bool("key", true), zapField("key", zap.Bool("other", true)))
+
+	SetDeterministicFlatten(true)
+	defer SetDeterministicFlatten(false)
+	fields := Fields{"b": 2, "a": "1", "service": "ignored"}
+	zapFields, collision := fields.zapFields(FieldCollisionIgnore)
+	assert.Equal(t, []zap.Field{zap.String("a", "1"), zap.Int("b", 2)}, zapFields)
+	assert.Equal(t, "service", collision)
+}
+
+// hugeFields returns n distinct fields, e.g. to check a large entry doesn't affect later small ones
+func hugeFields(n int) Fields {
+	fields := make(Fields, n)
+	for i := 0; i < n; i++ {
+		fields["field_"+strconv.Itoa(i)] = i
+	}
+	return fields
+}
+
+// Flatten returns a new slice sized by the fields, so a huge field set doesn't grow memory kept for later calls
+func Test_mapToSlice_HugeFieldsSteadyState(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "info"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	small := Fields{"a": "b", "c": 1}
+	logSmall := func() { logger.With(small).Info("small") }
+
+	before := testing.AllocsPerRun(100, logSmall)
+	logger.With(hugeFields(500)).Info("huge")
+	after := testing.AllocsPerRun(100, logSmall)
+	// Pooled buffers may be dropped, e.g. under the race detector, so allow some headroom
+	assert.LessOrEqual(t, after, before+2)
+
+	// Not sized by the huge field set
+	flat := small.Flatten()
+	assert.Less(t, cap(flat), len(hugeFields(500)))
+}
+
 func TestLoggerImpl_With(t *testing.T) {
 	logger, _ := New(LoggingConfig{
 		Service:   "testing",
@@ -64,13 +141,567 @@ func TestLoggerImpl_With(t *testing.T) {
 	logger.Info("should be clear")
 }
 
+func TestLoggerImpl_WithNil(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	// Same fields and prepared zap logger
+	derived := logger.With(Fields{"request_id": 42})
+	for _, same := range []Logger{derived.With(nil), derived.With(Fields{})} {
+		assert.Same(t, derived.(loggerImpl).fields, same.(loggerImpl).fields)
+		assert.Same(t, derived.(loggerImpl).prepared, same.(loggerImpl).prepared)
+	}
+
+	var typed *int
+	derived.With(Fields{"missing": nil, "typed": typed}).Info("nil values")
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Contains(t, entries[0], "missing")
+	assert.Nil(t, entries[0]["missing"])
+	assert.Contains(t, entries[0], "typed")
+	assert.Nil(t, entries[0]["typed"])
+}
+
+func TestLoggerImpl_WithReuse(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	// Built before the first entry
+	reused := logger.Namespace("worker").WithReuse(Fields{"worker_id": 3}).(loggerImpl)
+	assert.NotNil(t, reused.prepared.logger.Load())
+	assert.NotNil(t, reused.prepared.typed.Load())
+	assert.Nil(t, logger.With(Fields{"worker_id": 3}).(loggerImpl).prepared.logger.Load())
+
+	reused.Info("started")
+	reused.InfoZ("stopped", zap.Int("jobs", 2))
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, float64(3), entry["worker_id"])
+		assert.Equal(t, "worker", entry["namespace"])
+	}
+	assert.Equal(t, float64(2), entries[1]["jobs"])
+
+	// Nothing to add, the logger is still built
+	assert.NotNil(t, logger.WithReuse(nil).(loggerImpl).prepared.logger.Load())
+}
+
+func TestLoggerImpl_ZeroValue(t *testing.T) {
+	var logger Logger = loggerImpl{}
+
+	assert.NotPanics(t, func() {
+		logger.Debug("discarded")
+		logger.Infof("discarded %d", 1)
+		logger.Warn("discarded")
+		logger.Errorf("discarded %d", 2)
+		logger.Event(Fields{"event": "discarded"})
+		logger.Trace(errors.New("discarded"))
+		assert.Error(t, logger.LogErr(errors.New("discarded"), "failed"))
+
+		derived := logger.With(Fields{"hello": "world"}).WithComponent("sql").WithTTL(time.Minute).Namespace("derived")
+		derived.Info("discarded")
+		value, ok := derived.GetField("hello")
+		assert.True(t, ok)
+		assert.Equal(t, "world", value)
+		assert.Empty(t, logger.GetFields())
+		logger.Typed().Info("discarded")
+		logger.WithReuse(Fields{"reused": true}).InfoZ("discarded")
+		logger.WithLazy(func() Fields { return Fields{"lazy": true} }).AppendNamespace("sub").Info("discarded")
+		logger.MergeFrom(derived).Info("discarded")
+		logger.Writer("info").Write([]byte("discarded\n"))
+		logger.StdLogger("warn").Print("discarded")
+		assert.False(t, logger.Enabled("error"))
+
+		stop := logger.Record()
+		assert.Empty(t, stop())
+		assert.True(t, logger.SinkHealthy())
+		assert.NoError(t, logger.Sync())
+		assert.NoError(t, logger.Close())
+	})
+}
+
+func TestLoggerImpl_AppendNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		namespace string
+		subs      []string
+		want      string
+	}{
+		{
+			name:      "single",
+			namespace: "orders",
+			subs:      []string{"payments"},
+			want:      "orders/payments",
+		},
+		{
+			name:      "chained",
+			namespace: "orders",
+			subs:      []string{"payments", "refunds"},
+			want:      "orders/payments/refunds",
+		},
+		{
+			name:      "empty current namespace",
+			namespace: "",
+			subs:      []string{"payments"},
+			want:      "payments",
+		},
+		{
+			name:      "empty sub namespace",
+			namespace: "orders",
+			subs:      []string{""},
+			want:      "orders",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := New(LoggingConfig{Namespace: tt.namespace, DisableStdout: true, AllowNoOutputs: true})
+			assert.NoError(t, err)
+
+			for _, sub := range tt.subs {
+				logger = logger.AppendNamespace(sub)
+			}
+
+			got, _ := logger.GetField("namespace")
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoggerImpl_NamespaceField(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	logger = logger.With(Fields{"a": "b"})
+
+	// Namespace set by With is the same as set by Namespace
+	viaWith := logger.With(Fields{"namespace": "orders", "c": 1})
+	got, ok := viaWith.GetField("namespace")
+	assert.True(t, ok)
+	assert.Equal(t, "orders", got)
+	assert.Equal(t, Fields{"service": "testing", "namespace": "orders", "a": "b", "c": 1}, viaWith.GetFields())
+	assert.Equal(t, Fields{"service": "testing", "namespace": "custom", "a": "b"}, logger.Namespace("custom").GetFields())
+
+	// Loggers differing only in namespace share zap logger with the other fields
+	logger.Info("first")
+	derived := logger.Namespace("custom").(loggerImpl)
+	derived.Info("second")
+	assert.Same(t, logger.(loggerImpl).prepareWithoutNamespace(), derived.prepareWithoutNamespace())
+
+	lines := strings.Split(strings.TrimSpace(buf.buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, 1, strings.Count(lines[0], `"namespace":"default"`))
+	assert.Equal(t, 1, strings.Count(lines[1], `"namespace":"custom"`))
+	assert.NotContains(t, lines[1], "default")
+}
+
+func TestLoggerImpl_AppendNamespaceKeepsParent(t *testing.T) {
+	parent, err := New(LoggingConfig{Namespace: "orders", DisableStdout: true, AllowNoOutputs: true})
+	assert.NoError(t, err)
+
+	parent.AppendNamespace("payments")
+
+	got, _ := parent.GetField("namespace")
+	assert.Equal(t, "orders", got)
+
+	got, _ = parent.Namespace("custom").AppendNamespace("payments").GetField("namespace")
+	assert.Equal(t, "custom/payments", got)
+}
+
+func TestLoggerImpl_Enabled(t *testing.T) {
+	logger, err := New(LoggingConfig{Level: "warn"})
+	assert.NoError(t, err)
+
+	assert.False(t, logger.Enabled("debug"))
+	assert.False(t, logger.Enabled("info"))
+	assert.True(t, logger.Enabled("warn"))
+	assert.True(t, logger.Enabled("error"))
+	assert.False(t, logger.Enabled("unknown"))
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, expected := range map[string]zapcore.Level{
+		"debug":   zapcore.DebugLevel,
+		" INFO ":  zapcore.InfoLevel,
+		"Warn\n":  zapcore.WarnLevel,
+		"\tfatal": zapcore.FatalLevel,
+	} {
+		level, err := ParseLevel(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, level, input)
+	}
+
+	_, err := ParseLevel("verbose")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"verbose"`)
+	assert.Contains(t, err.Error(), "debug, info, warn, error, panic or fatal")
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, expected := range map[string]string{
+		"":        FormatJSON,
+		" ":       FormatJSON,
+		"JSON":    FormatJSON,
+		" Pretty": FormatPretty,
+		"gcp":     FormatGCP,
+	} {
+		format, err := ParseFormat(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, format, input)
+	}
+
+	_, err := ParseFormat("yaml")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"yaml"`)
+}
+
+func TestNew_Lenient(t *testing.T) {
+	logger, err := New(LoggingConfig{Service: "testing", Level: " WARN", FormatStdout: "Pretty "})
+	require.NoError(t, err)
+	assert.False(t, logger.Enabled("info"))
+	assert.True(t, logger.Enabled("warn"))
+}
+
+func TestMust(t *testing.T) {
+	assert.Panics(t, func() {
+		Must(LoggingConfig{Level: "invalid"})
+	})
+
+	var logger Logger
+	assert.NotPanics(t, func() {
+		logger = Must(LoggingConfig{Service: "testing", Level: "info"})
+	})
+	assert.True(t, logger.Enabled("info"))
+}
+
+func TestNew_NoOutputs(t *testing.T) {
+	_, err := New(LoggingConfig{Service: "testing", DisableStdout: true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no outputs enabled")
+
+	logger, err := New(LoggingConfig{Service: "testing", DisableStdout: true, AllowNoOutputs: true})
+	require.NoError(t, err)
+	logger.Info("dropped")
+	assert.False(t, logger.Enabled("error"))
+}
+
+func TestNew_InvalidLogstashProtocol(t *testing.T) {
+	_, err := New(LoggingConfig{Service: "testing", LogstashURI: "127.0.0.1:5000", LogstashProtocol: "tpc"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"tpc"`)
+}
+
+func TestNewBasic(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger := NewBasic(buf, "warn")
+
+	logger.Info("skipped")
+	logger.With(Fields{"attempt": 2}).Warn("retrying")
+
+	line := buf.buf.String()
+	assert.NotContains(t, line, "skipped")
+	assert.Contains(t, line, "\twarn\tretrying\t")
+	assert.Contains(t, line, `"attempt": 2`)
+
+	assert.Panics(t, func() { NewBasic(buf, "verbose") })
+}
+
+func TestLoggerImpl_Concurrent(t *testing.T) {
+	buf := &lockedBuffer{}
+	parent, err := newLogger(LoggingConfig{
+		Service:   "testing",
+		Namespace: "default",
+		Level:     "debug",
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	parent = parent.With(Fields{"shared": "parent"})
+
+	const goroutines, entries = 50, 20
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			id := strconv.Itoa(g)
+			for i := 0; i < entries; i++ {
+				derived := parent.
+					Namespace("ns-" + id).
+					With(Fields{"goroutine": id}).
+					WithLazy(func() Fields { return Fields{"lazy": id} })
+				derived.Info("derived")
+				parent.Debug("parent")
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	got := buf.entries(t)
+	require.Len(t, got, goroutines*entries*2)
+
+	for _, entry := range got {
+		assert.Equal(t, "parent", entry["shared"])
+
+		if entry["message"] == "parent" {
+			assert.Equal(t, "default", entry["namespace"])
+			assert.NotContains(t, entry, "goroutine")
+			continue
+		}
+
+		id := entry["goroutine"]
+		assert.Equal(t, "ns-"+id.(string), entry["namespace"])
+		assert.Equal(t, id, entry["lazy"])
+	}
+
+	namespace, _ := parent.GetField("namespace")
+	assert.Equal(t, "default", namespace)
+}
+
+// Goroutines share a logger whose flattened fields and cached zap logger are built concurrently on the first entries
+func TestLoggerImpl_ConcurrentShared(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	shared := logger.With(Fields{"shared": "value", "count": 1})
+
+	const goroutines, entries = 50, 20
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			<-start
+
+			id := strconv.Itoa(g)
+			for i := 0; i < entries; i++ {
+				shared.Info(id)
+				shared.With(Fields{"goroutine": id}).Info(id)
+			}
+		}(g)
+	}
+	close(start)
+	wg.Wait()
+
+	got := buf.entries(t)
+	require.Len(t, got, goroutines*entries*2)
+	for _, entry := range got {
+		assert.Equal(t, "value", entry["shared"])
+		assert.Equal(t, float64(1), entry["count"])
+		if id, ok := entry["goroutine"]; ok {
+			assert.Equal(t, entry["message"], id)
+		}
+	}
+}
+
+// observerWrapper tees all entries into an observer core
+type observerWrapper struct {
+	observer zapcore.Core
+}
+
+func (w observerWrapper) WrapOutput(_ string, core zapcore.Core) zapcore.Core {
+	return core
+}
+
+func (w observerWrapper) WrapCore(core zapcore.Core) zapcore.Core {
+	return zapcore.NewTee(core, w.observer)
+}
+
+// Entries logged concurrently with distinct values must never carry values of another goroutine
+func TestLoggerImpl_ConcurrentFieldsIsolated(t *testing.T) {
+	observerCore, logs := observer.New(zapcore.DebugLevel)
+	logger, err := newLogger(LoggingConfig{
+		Service:        "testing",
+		Level:          "debug",
+		DisableStdout:  true,
+		AllowNoOutputs: true,
+		CoreWrapper:    observerWrapper{observer: observerCore},
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	const goroutines, entries = 64, 50
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			<-start
+
+			id := strconv.Itoa(g)
+			derived := logger.With(Fields{"goroutine": id, "a": id, "b": id, "c": id})
+			for i := 0; i < entries; i++ {
+				derived.Info(id)
+				logger.With(Fields{"goroutine": id, "entry": i}).Debug(id)
+			}
+		}(g)
+	}
+	close(start)
+	wg.Wait()
+
+	require.Equal(t, goroutines*entries*2, logs.Len())
+	for _, entry := range logs.All() {
+		fields := entry.ContextMap()
+		assert.Equal(t, entry.Message, fields["goroutine"])
+		if entry.Level == zapcore.InfoLevel {
+			for _, key := range []string{"a", "b", "c"} {
+				assert.Equal(t, entry.Message, fields[key])
+			}
+		}
+	}
+}
+
 func BenchmarkLoggerImpl_Info(b *testing.B) {
 	logger, _ := New(LoggingConfig{
-		Service:       "testing",
-		Namespace:     "default",
-		DisableStdout: true,
-		Level:         "info",
+		Service:        "testing",
+		Namespace:      "default",
+		DisableStdout:  true,
+		AllowNoOutputs: true,
+		Level:          "info",
+	})
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Namespace("test").With(Fields{"a": "b"}).Info("hello there")
+	}
+}
+
+// Logger with static fields reuses zap logger built on the first entry, compare with BenchmarkLoggerImpl_Info
+// deriving logger on every call
+func BenchmarkLoggerImpl_InfoStatic(b *testing.B) {
+	logger, _ := New(LoggingConfig{
+		Service:        "testing",
+		Namespace:      "default",
+		DisableStdout:  true,
+		AllowNoOutputs: true,
+		Level:          "info",
+	})
+	logger = logger.Namespace("test").With(Fields{"a": "b"})
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Info("hello there")
+	}
+}
+
+// Concurrent logging into a file like stdout, compare sync and async lines for contention on writes
+func BenchmarkLoggerImpl_InfoParallel(b *testing.B) {
+	for _, async := range []bool{false, true} {
+		name := "sync"
+		if async {
+			name = "async"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			file, err := ioutil.TempFile("", "logger-bench")
+			require.NoError(b, err)
+			defer os.Remove(file.Name())
+			defer file.Close()
+
+			logger, err := newLogger(LoggingConfig{
+				Service:     "testing",
+				Namespace:   "default",
+				Level:       "info",
+				AsyncStdout: async,
+			}, zapcore.Lock(file))
+			require.NoError(b, err)
+			defer logger.Close()
+			logger = logger.With(Fields{"a": "b"})
+
+			b.ReportAllocs()
+
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info("hello there")
+				}
+			})
+		})
+	}
+}
+
+// Same entry as BenchmarkLoggerImpl_InfoStatic with one field per call, compare with BenchmarkTypedLogger_Info
+func BenchmarkLoggerImpl_InfoField(b *testing.B) {
+	logger, _ := New(LoggingConfig{
+		Service:        "testing",
+		Namespace:      "default",
+		DisableStdout:  true,
+		AllowNoOutputs: true,
+		Level:          "info",
+	})
+	logger = logger.Namespace("test").With(Fields{"a": "b"})
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.With(Fields{"attempt": i}).Info("hello there")
+	}
+}
+
+func BenchmarkTypedLogger_Info(b *testing.B) {
+	logger, _ := New(LoggingConfig{
+		Service:        "testing",
+		Namespace:      "default",
+		DisableStdout:  true,
+		AllowNoOutputs: true,
+		Level:          "info",
+	})
+	typed := logger.Namespace("test").With(Fields{"a": "b"}).Typed()
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		typed.Info("hello there", zap.Int("attempt", i))
+	}
+}
+
+// Same entry as BenchmarkTypedLogger_Info without building the typed logger, compare with BenchmarkLoggerImpl_InfoField
+func BenchmarkLoggerImpl_InfoZ(b *testing.B) {
+	logger, _ := New(LoggingConfig{
+		Service:        "testing",
+		Namespace:      "default",
+		DisableStdout:  true,
+		AllowNoOutputs: true,
+		Level:          "info",
 	})
+	logger = logger.Namespace("test").With(Fields{"a": "b"})
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.InfoZ("hello there", zap.Int("attempt", i))
+	}
+}
+
+// benchmarkSmallFields logs entries with small fields, optionally after a huge field set was logged once
+func benchmarkSmallFields(b *testing.B, huge bool) {
+	logger, _ := newLogger(LoggingConfig{
+		Service:   "testing",
+		Namespace: "default",
+		Level:     "info",
+	}, zapcore.AddSync(ioutil.Discard))
+	if huge {
+		logger.With(hugeFields(500)).Info("huge")
+	}
 
 	b.ReportAllocs()
 
@@ -81,6 +712,75 @@ func BenchmarkLoggerImpl_Info(b *testing.B) {
 	}
 }
 
+// benchmarkFirstEntry logs the first entry of a logger derived by With or WithReuse, only the entry is timed
+func benchmarkFirstEntry(b *testing.B, reuse bool) {
+	logger, _ := newLogger(LoggingConfig{
+		Service:   "testing",
+		Namespace: "default",
+		Level:     "info",
+	}, zapcore.AddSync(ioutil.Discard))
+	fields := Fields{"connection_id": "c1", "remote": "10.0.0.1:5432", "protocol": "tcp"}
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		var derived Logger
+		if reuse {
+			derived = logger.WithReuse(fields)
+		} else {
+			derived = logger.With(fields)
+		}
+		b.StartTimer()
+
+		derived.Info("hello there")
+	}
+}
+
+func BenchmarkLoggerImpl_FirstEntryWith(b *testing.B) {
+	benchmarkFirstEntry(b, false)
+}
+
+// The first entry costs like any next one, the zap logger is built by WithReuse.
+// Compare with BenchmarkLoggerImpl_FirstEntryWith.
+func BenchmarkLoggerImpl_FirstEntryWithReuse(b *testing.B) {
+	benchmarkFirstEntry(b, true)
+}
+
+func BenchmarkLoggerImpl_SmallFields(b *testing.B) {
+	benchmarkSmallFields(b, false)
+}
+
+// Bytes and allocations per entry should match BenchmarkLoggerImpl_SmallFields
+func BenchmarkLoggerImpl_SmallFieldsAfterHuge(b *testing.B) {
+	benchmarkSmallFields(b, true)
+}
+
+// Middleware-like chain adding a field per layer, only the last logger logs
+func BenchmarkLoggerImpl_WithChain(b *testing.B) {
+	logger, _ := newLogger(LoggingConfig{
+		Service:   "testing",
+		Namespace: "default",
+		Level:     "info",
+	}, zapcore.AddSync(ioutil.Discard))
+	logger = logger.With(Fields{"request_id": "1", "method": "GET", "path": "/orders"})
+	keys := []string{"layer_1", "layer_2", "layer_3", "layer_4", "layer_5", "layer_6", "layer_7", "layer_8"}
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		chained := logger
+		for _, key := range keys {
+			chained = chained.With(Fields{key: i})
+		}
+		chained.Info("handled")
+	}
+}
+
 func BenchmarkLoggerImpl_Error(b *testing.B) {
 	logger, _ := New(DefaultConfig)
 
@@ -116,3 +816,49 @@ func BenchmarkLoggerImpl_Errorf2(b *testing.B) {
 		logger.Errorf("test: %s", "test")
 	}
 }
+
+func benchmarkDisabled(b *testing.B, log func(logger Logger)) {
+	logger, _ := newLogger(LoggingConfig{
+		Service: "testing",
+		Level:   "info",
+	}, zapcore.AddSync(ioutil.Discard))
+	logger = logger.Namespace("test").With(Fields{"a": "b"})
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		log(logger)
+	}
+}
+
+// Disabled entries return before fields are prepared, the only allocation is the variadic slice of the call
+func BenchmarkLoggerImpl_DebugDisabled(b *testing.B) {
+	benchmarkDisabled(b, func(logger Logger) { logger.Debug("suppressed") })
+}
+
+func BenchmarkLoggerImpl_DebugfDisabled(b *testing.B) {
+	thing := &struct{ heavy string }{heavy: "computed"}
+	benchmarkDisabled(b, func(logger Logger) { logger.Debugf("expensive %v", thing) })
+}
+
+func TestLoggerImpl_DisabledAllocs(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	logger = logger.With(Fields{"a": "b"})
+
+	thing := &struct{ heavy string }{heavy: "computed"}
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		logger.Debug()
+		logger.Debugf("expensive")
+	}))
+	// The only allocation is the variadic slice built by the caller, which escapes through the interface call
+	assert.Equal(t, float64(1), testing.AllocsPerRun(100, func() {
+		logger.Debugf("expensive %v", thing)
+	}))
+
+	logger.Info("enabled")
+	assert.Len(t, buf.entries(t), 1)
+}
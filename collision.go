@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// What loggers do with fields whose keys collide with keys they write themselves: "@timestamp", "message",
+// "level" and "service"
+type FieldCollisionPolicy string
+
+const (
+	// Colliding fields are dropped, the default
+	FieldCollisionIgnore FieldCollisionPolicy = "ignore"
+	// Service field replaces the one of the config, other colliding fields are dropped like with FieldCollisionIgnore
+	FieldCollisionOverride FieldCollisionPolicy = "override"
+	// Colliding fields are written with RenamedFieldPrefix, e.g. "fields.service"
+	FieldCollisionRename FieldCollisionPolicy = "rename"
+)
+
+// Prefix of colliding fields renamed by FieldCollisionRename
+const RenamedFieldPrefix = "fields."
+
+// collisionState is the policy shared by loggers derived from the same New call
+type collisionState struct {
+	policy FieldCollisionPolicy
+
+	// 1 once the first collision is reported
+	reported int32
+}
+
+func newCollisionState(policy FieldCollisionPolicy) *collisionState {
+	if policy == "" {
+		policy = FieldCollisionIgnore
+	}
+	return &collisionState{policy: policy}
+}
+
+func (l loggerImpl) collisionPolicy() FieldCollisionPolicy {
+	if l.collisions == nil {
+		return FieldCollisionIgnore
+	}
+	return l.collisions.policy
+}
+
+// reportCollision logs at debug level the first collision of loggers derived from the same New call
+func (l loggerImpl) reportCollision(key string) {
+	if key == "" || l.collisions == nil || !atomic.CompareAndSwapInt32(&l.collisions.reported, 0, 1) {
+		return
+	}
+	l.zapLogger().Debug(fmt.Sprintf("field %q collides with a field of the logger, %s policy is applied, "+
+		"further collisions aren't reported", key, l.collisions.policy))
+}
+
+// eachResolved calls fn for fields in the order of Flatten, resolving keys which collide by the policy.
+// Returns the first colliding key, empty if there are none.
+func (f Fields) eachResolved(policy FieldCollisionPolicy, fn func(k string, v interface{})) (collision string) {
+	f.eachKey(func(k string, v interface{}) {
+		if _, ok := ignore[k]; !ok {
+			fn(k, v)
+			return
+		}
+
+		if collision == "" {
+			collision = k
+		}
+		switch {
+		case policy == FieldCollisionRename:
+			fn(RenamedFieldPrefix+k, v)
+		case policy == FieldCollisionOverride && k == "service":
+			fn(k, v)
+		}
+	})
+	return collision
+}
+
+// shadowCore adds base fields to entries unless fields added by With or fields of the entry have the same keys,
+// see FieldCollisionOverride
+type shadowCore struct {
+	// Core without base fields
+	bare zapcore.Core
+
+	// Base fields not shadowed yet and bare core with them
+	base     []zapcore.Field
+	withBase zapcore.Core
+}
+
+func newShadowCore(core zapcore.Core, base []zapcore.Field) zapcore.Core {
+	return &shadowCore{bare: core, base: base, withBase: core.With(base)}
+}
+
+func (c *shadowCore) Enabled(level zapcore.Level) bool {
+	return c.withBase.Enabled(level)
+}
+
+func (c *shadowCore) With(fields []zapcore.Field) zapcore.Core {
+	bare := c.bare.With(fields)
+	base := unshadowed(c.base, fields)
+	if len(base) == len(c.base) {
+		// Keeps base fields first
+		return &shadowCore{bare: bare, base: base, withBase: c.withBase.With(fields)}
+	}
+	return &shadowCore{bare: bare, base: base, withBase: bare.With(base)}
+}
+
+func (c *shadowCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *shadowCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	base := unshadowed(c.base, fields)
+	if len(base) == len(c.base) {
+		return c.withBase.Write(ent, fields)
+	}
+	return c.bare.With(base).Write(ent, fields)
+}
+
+func (c *shadowCore) Sync() error {
+	return c.bare.Sync()
+}
+
+// unshadowed returns base fields whose keys aren't used by fields, base itself if there are none
+func unshadowed(base, fields []zapcore.Field) []zapcore.Field {
+	var kept []zapcore.Field
+	for i, b := range base {
+		shadowed := false
+		for _, field := range fields {
+			if field.Key == b.Key {
+				shadowed = true
+				break
+			}
+		}
+
+		switch {
+		case shadowed && kept == nil:
+			kept = append(make([]zapcore.Field, 0, len(base)), base[:i]...)
+		case !shadowed && kept != nil:
+			kept = append(kept, b)
+		}
+	}
+
+	if kept == nil {
+		return base
+	}
+	return kept
+}
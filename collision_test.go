@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFieldCollision(t *testing.T) {
+	tests := []struct {
+		policy   FieldCollisionPolicy
+		expected map[string]interface{}
+	}{
+		{
+			policy:   "",
+			expected: map[string]interface{}{"service": "testing", "message": "handled"},
+		},
+		{
+			policy:   FieldCollisionIgnore,
+			expected: map[string]interface{}{"service": "testing", "message": "handled"},
+		},
+		{
+			policy:   FieldCollisionOverride,
+			expected: map[string]interface{}{"service": "other", "message": "handled"},
+		},
+		{
+			policy: FieldCollisionRename,
+			expected: map[string]interface{}{
+				"service":           "testing",
+				"message":           "handled",
+				"fields.service":    "other",
+				"fields.message":    "shadowed",
+				"fields.level":      "fatal",
+				"fields.@timestamp": "now",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", FieldCollision: tt.policy},
+				zapcore.AddSync(buf))
+			require.NoError(t, err)
+
+			logger.With(Fields{
+				"service":    "other",
+				"message":    "shadowed",
+				"level":      "fatal",
+				"@timestamp": "now",
+			}).Info("handled")
+
+			// Keys aren't duplicated
+			assert.Equal(t, 1, strings.Count(buf.String(), `"service":`))
+
+			entries := jsonEntries(t, bytes.NewBuffer(buf.Bytes()))
+			require.Len(t, entries, 1)
+			assert.Equal(t, "info", entries[0]["level"])
+			for key, value := range tt.expected {
+				assert.Equal(t, value, entries[0][key], key)
+			}
+			if tt.policy != FieldCollisionRename {
+				assert.NotContains(t, entries[0], "fields.service")
+			}
+		})
+	}
+}
+
+func TestFieldCollision_OverrideEntryFields(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Cluster: "blue", FieldCollision: FieldCollisionOverride},
+		zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Typed().Info("typed", zap.String("service", "typed"))
+	logger.With(Fields{"request_id": 1}).Info("base")
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "typed", entries[0]["service"])
+	assert.Equal(t, "blue", entries[0][ClusterKey])
+	assert.Equal(t, "testing", entries[1]["service"])
+	assert.Equal(t, "blue", entries[1][ClusterKey])
+}
+
+func TestFieldCollision_Reported(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "debug"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"service": "other"}).Info("first")
+	logger.With(Fields{"message": "other"}).Info("second")
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "debug", entries[0]["level"])
+	assert.Contains(t, entries[0]["message"], `"service"`)
+	assert.Contains(t, entries[0]["message"], "ignore")
+	assert.Equal(t, "first", entries[1]["message"])
+	assert.Equal(t, "second", entries[2]["message"])
+}
+
+func TestFieldCollision_Invalid(t *testing.T) {
+	_, err := newLogger(LoggingConfig{Service: "testing", FieldCollision: "merge"}, zapcore.AddSync(&bytes.Buffer{}))
+	assert.Error(t, err)
+}
+
+func TestUnshadowed(t *testing.T) {
+	base := []zapcore.Field{zap.String("service", "testing"), zap.String("region", "eu"), zap.String("cluster", "blue")}
+
+	assert.Equal(t, base, unshadowed(base, []zapcore.Field{zap.Int("request_id", 1)}))
+	assert.Equal(t, base[1:], unshadowed(base, []zapcore.Field{zap.String("service", "other")}))
+	assert.Equal(t, base[:1], unshadowed(base, []zapcore.Field{zap.String("cluster", "red"), zap.String("region", "us")}))
+}
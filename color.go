@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Colors of levels in FormatPretty entries with Color enabled, same as zap development preset.
+// LoggingConfig.LevelColors overrides them per level.
+var DefaultLevelColors = map[string]string{
+	"debug": "magenta",
+	"info":  "blue",
+	"warn":  "yellow",
+	"error": "red",
+	"panic": "red",
+	"fatal": "red",
+}
+
+// ANSI foreground codes of named colors accepted by LevelColors
+var namedColors = map[string]int{
+	"black":   30,
+	"red":     31,
+	"green":   32,
+	"yellow":  33,
+	"blue":    34,
+	"magenta": 35,
+	"cyan":    36,
+	"white":   37,
+	"gray":    90,
+}
+
+const colorReset = "\x1b[0m"
+
+// parseColor returns escape sequence of a named color, SGR parameters like "1;31" or a complete sequence
+func parseColor(color string) (string, error) {
+	if code, ok := namedColors[strings.ToLower(color)]; ok {
+		return fmt.Sprintf("\x1b[%dm", code), nil
+	}
+
+	if strings.HasPrefix(color, "\x1b[") && strings.HasSuffix(color, "m") {
+		return color, nil
+	}
+
+	if color != "" && strings.Trim(color, "0123456789;") == "" {
+		return "\x1b[" + color + "m", nil
+	}
+
+	return "", fmt.Errorf("bad color %q, must be a color name or ANSI code", color)
+}
+
+// newColorLevelEncoder returns level encoder wrapping lowercase level names into colors,
+// overrides take precedence over DefaultLevelColors
+func newColorLevelEncoder(overrides map[string]string) (zapcore.LevelEncoder, error) {
+	colored := make(map[zapcore.Level]string)
+	for _, colors := range []map[string]string{DefaultLevelColors, overrides} {
+		for name, color := range colors {
+			level, err := ParseLevel(name)
+			if err != nil {
+				return nil, err
+			}
+
+			sequence, err := parseColor(color)
+			if err != nil {
+				return nil, fmt.Errorf("level %v: %w", name, err)
+			}
+			colored[level] = sequence + level.String() + colorReset
+		}
+	}
+
+	return func(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+		name, ok := colored[level]
+		if !ok {
+			name = level.String()
+		}
+		enc.AppendString(name)
+	}, nil
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelColors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:      "testing",
+		Level:        "debug",
+		FormatStdout: FormatPretty,
+		Color:        true,
+		LevelColors:  map[string]string{"info": "green", "error": "1;31"},
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Debug("default")
+	logger.Info("named")
+	logger.Error("code")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "\t\x1b[35mdebug\x1b[0m\t")
+	assert.Contains(t, lines[1], "\t\x1b[32minfo\x1b[0m\t")
+	assert.Contains(t, lines[2], "\t\x1b[1;31merror\x1b[0m\t")
+}
+
+func TestLevelColors_Disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:      "testing",
+		FormatStdout: FormatPretty,
+		LevelColors:  map[string]string{"info": "green"},
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("plain")
+	assert.Contains(t, buf.String(), "\tinfo\t")
+	assert.NotContains(t, buf.String(), "\x1b[")
+}
+
+func TestLevelColors_Invalid(t *testing.T) {
+	for _, colors := range []map[string]string{
+		{"info": "purple"},
+		{"info": "31m"},
+		{"trace": "red"},
+	} {
+		_, err := newLogger(LoggingConfig{
+			Service:      "testing",
+			FormatStdout: FormatPretty,
+			Color:        true,
+			LevelColors:  colors,
+		}, zapcore.AddSync(&bytes.Buffer{}))
+		assert.Error(t, err, colors)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	for color, expected := range map[string]string{
+		"cyan":       "\x1b[36m",
+		"Gray":       "\x1b[90m",
+		"38;5;208":   "\x1b[38;5;208m",
+		"\x1b[4;33m": "\x1b[4;33m",
+	} {
+		sequence, err := parseColor(color)
+		require.NoError(t, err)
+		assert.Equal(t, expected, sequence)
+	}
+
+	_, err := parseColor("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,12 @@
+package logger
+
+// Key of the field set by WithComponent
+const ComponentKey = "component"
+
+func (l loggerImpl) WithComponent(name string) Logger {
+	if name == "" {
+		return l
+	}
+
+	return l.With(Fields{ComponentKey: name})
+}
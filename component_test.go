@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_WithComponent(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "orders", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	sql := logger.WithComponent("sql")
+	sql.Info("direct")
+	sql.With(Fields{"a": "b"}).AppendNamespace("replica").Info("chained")
+	sql.WithComponent("").Info("empty ignored")
+	sql.WithComponent("cache").Info("overridden")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "sql", entries[0][ComponentKey])
+	assert.Equal(t, "sql", entries[1][ComponentKey])
+	assert.Equal(t, "orders/replica", entries[1]["namespace"])
+	assert.Equal(t, "sql", entries[2][ComponentKey])
+	assert.Equal(t, "cache", entries[3][ComponentKey])
+
+	value, ok := sql.GetField(ComponentKey)
+	require.True(t, ok)
+	assert.Equal(t, "sql", value)
+}
@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Which of fields with the same key is written, e.g. a field of With and a typed field of the entry,
+// or fields returned by two WithLazy functions. See LoggingConfig.FieldConflict.
+type FieldConflictPolicy string
+
+const (
+	// The last field wins: fields of the entry replace ones of the logger, later fields replace earlier ones
+	FieldConflictLast FieldConflictPolicy = "last"
+	// The first field wins: fields of the logger are kept, later fields with the same key are dropped
+	FieldConflictFirst FieldConflictPolicy = "first"
+	// The last field wins like with FieldConflictLast, and the conflict is returned as write error printed to stderr
+	FieldConflictError FieldConflictPolicy = "error"
+)
+
+// conflictCore keeps fields added by With unencoded and writes them with fields of every entry,
+// so each key appears once resolved by the policy
+type conflictCore struct {
+	zapcore.Core
+
+	policy  FieldConflictPolicy
+	context []zapcore.Field
+}
+
+func newConflictCore(core zapcore.Core, policy FieldConflictPolicy) zapcore.Core {
+	return &conflictCore{Core: core, policy: policy}
+}
+
+func (c *conflictCore) With(fields []zapcore.Field) zapcore.Core {
+	return &conflictCore{
+		Core:    c.Core,
+		policy:  c.policy,
+		context: append(c.context[:len(c.context):len(c.context)], fields...),
+	}
+}
+
+func (c *conflictCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *conflictCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	all = append(all, fields...)
+
+	resolved, conflict := resolveConflicts(all, c.policy)
+	err := c.Core.Write(ent, resolved)
+	if conflict != "" && c.policy == FieldConflictError && err == nil {
+		err = fmt.Errorf("field %q is added to entry %q more than once, the last one is written", conflict, ent.Message)
+	}
+	return err
+}
+
+// resolveConflicts returns fields with every key once keeping their order, and the first conflicting key.
+// Keys are scoped by zap.Namespace fields preceding them, which are always kept.
+func resolveConflicts(fields []zapcore.Field, policy FieldConflictPolicy) ([]zapcore.Field, string) {
+	scoped := make([]string, len(fields))
+	// Index of the field written for the scoped key
+	winners := make(map[string]int, len(fields))
+	var conflict string
+
+	scope := ""
+	for i, field := range fields {
+		key := scope + field.Key
+		scoped[i] = key
+		switch field.Type {
+		case zapcore.SkipType:
+			continue
+		case zapcore.NamespaceType:
+			scope = key + "."
+			continue
+		}
+
+		if _, ok := winners[key]; ok {
+			if conflict == "" {
+				conflict = key
+			}
+			if policy == FieldConflictFirst {
+				continue
+			}
+		}
+		winners[key] = i
+	}
+	if conflict == "" {
+		return fields, ""
+	}
+
+	resolved := make([]zapcore.Field, 0, len(fields))
+	for i, field := range fields {
+		if field.Type == zapcore.NamespaceType || winners[scoped[i]] == i && field.Type != zapcore.SkipType {
+			resolved = append(resolved, field)
+		}
+	}
+	return resolved, conflict
+}
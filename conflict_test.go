@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFieldConflict(t *testing.T) {
+	tests := []struct {
+		policy   FieldConflictPolicy
+		expected float64
+	}{
+		{policy: FieldConflictLast, expected: 2},
+		{policy: FieldConflictFirst, expected: 1},
+		{policy: FieldConflictError, expected: 2},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", FieldConflict: tt.policy},
+				zapcore.AddSync(buf))
+			require.NoError(t, err)
+
+			derived := logger.With(Fields{"a": 1})
+			derived.Typed().Info("typed", zap.Int("a", 2))
+			derived.InfoZ("desugared", zap.Int("a", 2))
+			derived.WithLazy(func() Fields {
+				return Fields{"a": 2}
+			}).Info("lazy")
+
+			// Each entry has the key once
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			require.Len(t, lines, 3)
+			for _, line := range lines {
+				assert.Equal(t, 1, strings.Count(line, `"a":`), line)
+			}
+
+			entries := jsonEntries(t, buf)
+			for _, entry := range entries {
+				assert.Equal(t, tt.expected, entry["a"], entry["message"])
+				assert.Equal(t, "testing", entry["service"])
+			}
+		})
+	}
+}
+
+func TestFieldConflict_Disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"a": 1}).InfoZ("duplicated", zap.Int("a", 2))
+	assert.Equal(t, 2, strings.Count(buf.String(), `"a":`))
+}
+
+func TestFieldConflict_Invalid(t *testing.T) {
+	_, err := newLogger(LoggingConfig{Service: "testing", FieldConflict: "merge"}, zapcore.AddSync(&bytes.Buffer{}))
+	assert.Error(t, err)
+}
+
+func TestConflictCore_Error(t *testing.T) {
+	buf := &bytes.Buffer{}
+	inner := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.InfoLevel)
+	core := newConflictCore(inner, FieldConflictError).With([]zapcore.Field{zap.Int("a", 1)})
+
+	entry := zapcore.Entry{Level: zap.InfoLevel, Message: "conflicting"}
+	err := core.Write(entry, []zapcore.Field{zap.Int("a", 2)})
+	assert.EqualError(t, err, `field "a" is added to entry "conflicting" more than once, the last one is written`)
+	// The entry is written anyway
+	assert.Contains(t, buf.String(), `"a":2`)
+
+	assert.NoError(t, core.Write(entry, []zapcore.Field{zap.Int("b", 2)}))
+}
+
+func TestResolveConflicts(t *testing.T) {
+	fields := []zapcore.Field{
+		zap.Int("a", 1),
+		zap.Namespace("scope"),
+		zap.Int("a", 2),
+		zap.Skip(),
+		zap.Int("b", 3),
+		zap.Int("a", 4),
+	}
+
+	resolved, conflict := resolveConflicts(fields[:3], FieldConflictLast)
+	assert.Empty(t, conflict)
+	assert.Equal(t, fields[:3], resolved)
+
+	resolved, conflict = resolveConflicts(fields, FieldConflictLast)
+	assert.Equal(t, "scope.a", conflict)
+	assert.Equal(t, []zapcore.Field{fields[0], fields[1], fields[4], fields[5]}, resolved)
+
+	resolved, conflict = resolveConflicts(fields, FieldConflictFirst)
+	assert.Equal(t, "scope.a", conflict)
+	assert.Equal(t, []zapcore.Field{fields[0], fields[1], fields[2], fields[4]}, resolved)
+}
@@ -0,0 +1,106 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// Stores logger in the context, e.g. to pass request-scoped logger to handlers
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// Returns logger stored by NewContext
+func FromContext(ctx context.Context) (Logger, bool) {
+	l, ok := ctx.Value(contextKey{}).(Logger)
+	return l, ok
+}
+
+// Keys of fields added by WithTenant and WithUser
+const (
+	TenantKey = "tenant_id"
+	UserKey   = "user_id"
+)
+
+type tenantKey struct{}
+
+type userKey struct{}
+
+// Stores tenant id in the context to be attached to entries by WithTenant
+func ContextWithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// Returns tenant id stored by ContextWithTenant
+func TenantFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok
+}
+
+// Stores user id in the context to be attached to entries by WithUser
+func ContextWithUser(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userKey{}, id)
+}
+
+// Returns user id stored by ContextWithUser
+func UserFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(userKey{}).(string)
+	return id, ok
+}
+
+func (l loggerImpl) WithTenant(ctx context.Context) Logger {
+	id, ok := TenantFromContext(ctx)
+	if !ok {
+		return l
+	}
+	return l.With(Fields{TenantKey: id})
+}
+
+func (l loggerImpl) WithUser(ctx context.Context) Logger {
+	id, ok := UserFromContext(ctx)
+	if !ok {
+		return l
+	}
+	return l.With(Fields{UserKey: id})
+}
+
+// Keys of fields added by WithContextError
+const (
+	ContextErrorKey = "context_error"
+	ContextCauseKey = "context_cause"
+)
+
+func (l loggerImpl) WithContextError(ctx context.Context) Logger {
+	err := ctx.Err()
+	if err == nil {
+		return l
+	}
+	// Cause is the error itself unless the context is cancelled with a cause
+	return l.With(Fields{ContextErrorKey: err.Error(), ContextCauseKey: contextCause(ctx).Error()})
+}
+
+type mdcKey struct{}
+
+// Returns context with the field added to mapped diagnostic context (MDC) of the flow, attached to entries by WithMDC.
+// Fields pushed by callers are kept, the same key replaces their value in the returned context only.
+func PushField(ctx context.Context, key string, value interface{}) context.Context {
+	return PushFields(ctx, Fields{key: value})
+}
+
+// Returns context with the fields added to MDC of the flow like PushField
+func PushFields(ctx context.Context, fields Fields) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	// Copied, contexts derived before must not see the fields
+	return context.WithValue(ctx, mdcKey{}, MDCFromContext(ctx).Merge(fields))
+}
+
+// Returns fields pushed to the context by PushField and PushFields, must not be modified
+func MDCFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(mdcKey{}).(Fields)
+	return fields
+}
+
+func (l loggerImpl) WithMDC(ctx context.Context) Logger {
+	return l.With(MDCFromContext(ctx))
+}
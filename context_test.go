@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFromContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	logger, err := New(LoggingConfig{Namespace: "default"})
+	assert.NoError(t, err)
+
+	ctx := NewContext(context.Background(), logger.Namespace("request"))
+
+	got, ok := FromContext(ctx)
+	assert.True(t, ok)
+
+	namespace, _ := got.GetField("namespace")
+	assert.Equal(t, "request", namespace)
+}
+
+func TestLoggerImpl_WithTenantAndUser(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	ctx := ContextWithUser(ContextWithTenant(context.Background(), "acme"), "u-42")
+
+	tenant, ok := TenantFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "acme", tenant)
+
+	logger.WithTenant(ctx).WithUser(ctx).Info("stamped")
+	logger.WithTenant(context.Background()).WithUser(context.Background()).Info("no values")
+	logger.WithTenant(ContextWithTenant(ctx, "globex")).Info("overridden")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "acme", entries[0][TenantKey])
+	assert.Equal(t, "u-42", entries[0][UserKey])
+	assert.NotContains(t, entries[1], TenantKey)
+	assert.NotContains(t, entries[1], UserKey)
+	assert.Equal(t, "globex", entries[2][TenantKey])
+}
+
+func TestLoggerImpl_WithContextError(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	timedOut, cancel := context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-timedOut.Done()
+
+	logger.WithContextError(context.Background()).Info("running")
+	logger.WithContextError(cancelled).Info("cancelled")
+	logger.WithContextError(timedOut).Info("timed out")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 3)
+	assert.NotContains(t, entries[0], ContextErrorKey)
+	assert.NotContains(t, entries[0], ContextCauseKey)
+	assert.Equal(t, "context canceled", entries[1][ContextErrorKey])
+	assert.Equal(t, "context canceled", entries[1][ContextCauseKey])
+	assert.Equal(t, "context deadline exceeded", entries[2][ContextErrorKey])
+	assert.Equal(t, "context deadline exceeded", entries[2][ContextCauseKey])
+}
+
+func TestLoggerImpl_WithMDC(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	// Handler pushes request fields, nested calls add their own and log without passing loggers around
+	var handle, charge func(ctx context.Context)
+	handle = func(ctx context.Context) {
+		ctx = PushField(ctx, "request_id", "r-1")
+		charge(PushFields(ctx, Fields{"order_id": 7, "request_id": "r-2"}))
+		logger.WithMDC(ctx).Info("handled")
+	}
+	charge = func(ctx context.Context) {
+		logger.WithMDC(ctx).Info("charged")
+	}
+	handle(context.Background())
+	logger.WithMDC(context.Background()).Info("no fields")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "charged", entries[0]["message"])
+	assert.Equal(t, "r-2", entries[0]["request_id"])
+	assert.Equal(t, float64(7), entries[0]["order_id"])
+
+	// Fields pushed by nested calls don't leak into the caller's context
+	assert.Equal(t, "handled", entries[1]["message"])
+	assert.Equal(t, "r-1", entries[1]["request_id"])
+	assert.NotContains(t, entries[1], "order_id")
+
+	assert.NotContains(t, entries[2], "request_id")
+}
+
+func TestMDCFromContext(t *testing.T) {
+	assert.Nil(t, MDCFromContext(context.Background()))
+
+	ctx := PushField(context.Background(), "a", 1)
+	assert.Equal(t, ctx, PushFields(ctx, nil))
+
+	derived := PushField(ctx, "b", 2)
+	assert.Equal(t, Fields{"a": 1}, MDCFromContext(ctx))
+	assert.Equal(t, Fields{"a": 1, "b": 2}, MDCFromContext(derived))
+}
@@ -0,0 +1,11 @@
+//go:build go1.20
+// +build go1.20
+
+package logger
+
+import "context"
+
+// contextCause returns the cause of the done context, ctx.Err() if it has none
+func contextCause(ctx context.Context) error {
+	return context.Cause(ctx)
+}
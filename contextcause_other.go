@@ -0,0 +1,11 @@
+//go:build !go1.20
+// +build !go1.20
+
+package logger
+
+import "context"
+
+// contextCause returns ctx.Err(), causes are supported since Go 1.20
+func contextCause(ctx context.Context) error {
+	return ctx.Err()
+}
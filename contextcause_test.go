@@ -0,0 +1,36 @@
+//go:build go1.20
+// +build go1.20
+
+package logger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_WithContextErrorCause(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	cancelled, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("client disconnected"))
+	timedOut, cancelTimeout := context.WithTimeoutCause(context.Background(), 0, errors.New("upstream too slow"))
+	defer cancelTimeout()
+	<-timedOut.Done()
+
+	logger.WithContextError(cancelled).Info("cancelled")
+	logger.WithContextError(timedOut).Info("timed out")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "context canceled", entries[0][ContextErrorKey])
+	assert.Equal(t, "client disconnected", entries[0][ContextCauseKey])
+	assert.Equal(t, "context deadline exceeded", entries[1][ContextErrorKey])
+	assert.Equal(t, "upstream too slow", entries[1][ContextCauseKey])
+}
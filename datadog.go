@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"context"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Fields correlating entries with Datadog APM traces, see DatadogTraceFields
+const (
+	DatadogTraceIDKey = "dd.trace_id"
+	DatadogSpanIDKey  = "dd.span_id"
+)
+
+// Statuses recognized by Datadog log pipeline, written by DatadogCompat instead of zap level names
+var datadogStatuses = map[zapcore.Level]string{
+	zapcore.DebugLevel:  "debug",
+	zapcore.InfoLevel:   "info",
+	zapcore.WarnLevel:   "warning",
+	zapcore.ErrorLevel:  "error",
+	zapcore.DPanicLevel: "critical",
+	zapcore.PanicLevel:  "alert",
+	zapcore.FatalLevel:  "emergency",
+}
+
+// Returns ids of the active span in the context, e.g. from dd-trace-go:
+//
+//	func(ctx context.Context) (uint64, uint64, bool) {
+//		span, ok := tracer.SpanFromContext(ctx)
+//		if !ok {
+//			return 0, 0, false
+//		}
+//		return span.Context().TraceID(), span.Context().SpanID(), true
+//	}
+type DatadogTraceExtractor func(ctx context.Context) (traceID, spanID uint64, ok bool)
+
+// DatadogTraceFields returns dd.trace_id and dd.span_id of the span extracted from the context, e.g. for
+// log.With(logger.DatadogTraceFields(ctx, extract)). Ids are strings since JSON numbers lose precision
+// above 2^53. Fields are empty if there is no span.
+func DatadogTraceFields(ctx context.Context, extract DatadogTraceExtractor) Fields {
+	if extract == nil {
+		return Fields{}
+	}
+
+	traceID, spanID, ok := extract(ctx)
+	if !ok {
+		return Fields{}
+	}
+
+	return Fields{
+		DatadogTraceIDKey: strconv.FormatUint(traceID, 10),
+		DatadogSpanIDKey:  strconv.FormatUint(spanID, 10),
+	}
+}
+
+// newDatadogEncoderConfig writes level as "status" attribute with Datadog status names.
+// See https://docs.datadoghq.com/logs/log_configuration/attributes_naming_convention/#reserved-attributes
+func newDatadogEncoderConfig(encoderConfig zapcore.EncoderConfig) zapcore.EncoderConfig {
+	encoderConfig.LevelKey = "status"
+	encoderConfig.EncodeLevel = encodeDatadogStatus
+	return encoderConfig
+}
+
+func encodeDatadogStatus(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	status, ok := datadogStatuses[level]
+	if !ok {
+		status = "info"
+	}
+	enc.AppendString(status)
+}
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestDatadogCompat(t *testing.T) {
+	for _, sortKeys := range []bool{false, true} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{
+			Service:       "testing",
+			Level:         "debug",
+			SortKeys:      sortKeys,
+			DatadogCompat: true,
+		}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.Debug("debug")
+		logger.Warn("warn")
+		logger.Error("error")
+
+		entries := buf.entries(t)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "debug", entries[0]["status"])
+		assert.Equal(t, "warning", entries[1]["status"])
+		assert.Equal(t, "error", entries[2]["status"])
+		for _, entry := range entries {
+			assert.NotContains(t, entry, "level")
+			assert.Equal(t, "testing", entry["service"])
+		}
+	}
+}
+
+func TestDatadogTraceFields(t *testing.T) {
+	type spanKey struct{}
+	extract := func(ctx context.Context) (uint64, uint64, bool) {
+		ids, ok := ctx.Value(spanKey{}).([2]uint64)
+		return ids[0], ids[1], ok
+	}
+
+	ctx := context.WithValue(context.Background(), spanKey{}, [2]uint64{1<<63 + 1, 42})
+	assert.Equal(t, Fields{
+		DatadogTraceIDKey: "9223372036854775809",
+		DatadogSpanIDKey:  "42",
+	}, DatadogTraceFields(ctx, extract))
+
+	assert.Empty(t, DatadogTraceFields(context.Background(), extract))
+	assert.Empty(t, DatadogTraceFields(ctx, nil))
+}
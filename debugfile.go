@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DebugFiler is implemented by loggers returned by New, e.g. to write a verbose file during an incident while
+// other outputs stay at their level:
+//
+//	if d, ok := log.(logger.DebugFiler); ok {
+//		err = d.EnableDebugFile("/tmp/debug.log")
+//	}
+type DebugFiler interface {
+	// Starts writing entries of all levels as JSON to the file, appending to it. Replaces the debug file
+	// of LoggingConfig.DebugFilePath or of the previous call, which is closed. Affects all loggers derived
+	// from the same New call.
+	EnableDebugFile(path string) error
+
+	// Stops writing the debug file and closes it, no-op if it isn't written
+	DisableDebugFile() error
+}
+
+var _ DebugFiler = loggerImpl{}
+
+// Returned by DebugFiler methods of loggers which aren't built by New, e.g. test ones
+var errNoDebugFile = errors.New("logger can't write debug file")
+
+// debugFileState is the debug file shared by all loggers derived from the same New call and kept by Reconfigure.
+// Its core is always in outputs of New loggers, enabling entries of all levels only while a file is open.
+type debugFileState struct {
+	// 1 while file is open, checked by the core without the lock
+	enabled int32
+
+	// Guards file, writes hold it for reading, so the file isn't closed under them
+	mu   sync.RWMutex
+	file *os.File
+}
+
+func newDebugFileState() *debugFileState {
+	return &debugFileState{}
+}
+
+func (d *debugFileState) enable(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	old := d.file
+	d.file = file
+	atomic.StoreInt32(&d.enabled, 1)
+	if old != nil {
+		return old.Close()
+	}
+	return nil
+}
+
+func (d *debugFileState) disable() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.file == nil {
+		return nil
+	}
+
+	atomic.StoreInt32(&d.enabled, 0)
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+func (d *debugFileState) isEnabled() bool {
+	return atomic.LoadInt32(&d.enabled) == 1
+}
+
+// Write drops entries written while the file is being disabled
+func (d *debugFileState) Write(p []byte) (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.file == nil {
+		return len(p), nil
+	}
+	return d.file.Write(p)
+}
+
+func (d *debugFileState) Sync() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.file == nil {
+		return nil
+	}
+	return d.file.Sync()
+}
+
+// Close closes the file with the logger
+func (d *debugFileState) Close() error {
+	return d.disable()
+}
+
+// debugFileCore writes entries of all levels as JSON to the debug file while it's enabled. Fields added by With
+// are kept unencoded and encoded with every entry, so loggers don't pay for the file while it's disabled.
+type debugFileCore struct {
+	zapcore.Core
+
+	context []zapcore.Field
+}
+
+func newDebugFileCore(d *debugFileState, config LoggingConfig) zapcore.Core {
+	enabled := zap.LevelEnablerFunc(func(zapcore.Level) bool {
+		return d.isEnabled()
+	})
+	return &debugFileCore{Core: newCompatJSONCore(d, enabled, config)}
+}
+
+func (c *debugFileCore) With(fields []zapcore.Field) zapcore.Core {
+	return &debugFileCore{
+		Core:    c.Core,
+		context: append(c.context[:len(c.context):len(c.context)], fields...),
+	}
+}
+
+func (c *debugFileCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *debugFileCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if len(c.context) == 0 {
+		return c.Core.Write(ent, fields)
+	}
+
+	all := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	all = append(all, fields...)
+	return c.Core.Write(ent, all)
+}
+
+func (l loggerImpl) EnableDebugFile(path string) error {
+	if l.debugFile == nil {
+		return errNoDebugFile
+	}
+	return l.debugFile.enable(path)
+}
+
+func (l loggerImpl) DisableDebugFile() error {
+	if l.debugFile == nil {
+		return errNoDebugFile
+	}
+	return l.debugFile.disable()
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func newDebugFilePath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "debugfile")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return filepath.Join(dir, "debug.log")
+}
+
+// debugFileMessages returns messages of entries written to the file
+func debugFileMessages(t *testing.T, path string) []string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var messages []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		messages = append(messages, entry["message"].(string))
+	}
+	return messages
+}
+
+func TestDebugFile(t *testing.T) {
+	path := newDebugFilePath(t)
+	stdout := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DebugFilePath: path,
+	}, zapcore.AddSync(stdout))
+	require.NoError(t, err)
+
+	assert.True(t, logger.Enabled("debug"))
+	logger.With(Fields{"a": "b"}).Debug("verbose")
+	logger.Info("regular")
+	require.NoError(t, logger.Close())
+
+	entries := stdout.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "regular", entries[0]["message"])
+	assert.Equal(t, []string{"verbose", "regular"}, debugFileMessages(t, path))
+}
+
+func TestDebugFile_Runtime(t *testing.T) {
+	path := newDebugFilePath(t)
+	stdout := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(stdout))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	derived := logger.Namespace("derived")
+	derived.Debug("before")
+	assert.False(t, derived.Enabled("debug"))
+
+	require.NoError(t, logger.(DebugFiler).EnableDebugFile(path))
+	assert.True(t, derived.Enabled("debug"))
+	derived.Debug("enabled")
+
+	// Reconfigure keeps the debug file
+	require.NoError(t, logger.(Reconfigurer).Reconfigure(LoggingConfig{Service: "testing", Level: "warn"}))
+	derived.Info("reconfigured")
+
+	// Derived loggers share the debug file
+	require.NoError(t, derived.(DebugFiler).DisableDebugFile())
+	require.NoError(t, logger.(DebugFiler).DisableDebugFile())
+	assert.False(t, derived.Enabled("info"))
+	derived.Debug("after")
+
+	assert.Equal(t, []string{"enabled", "reconfigured"}, debugFileMessages(t, path))
+	assert.Empty(t, stdout.entries(t))
+}
+
+func TestDebugFile_OnlyOutput(t *testing.T) {
+	path := newDebugFilePath(t)
+	logger, err := New(LoggingConfig{Service: "testing", DisableStdout: true, DebugFilePath: path})
+	require.NoError(t, err)
+
+	logger.Debug("written")
+	require.NoError(t, logger.Close())
+	assert.Equal(t, []string{"written"}, debugFileMessages(t, path))
+}
+
+func TestDebugFile_Invalid(t *testing.T) {
+	_, err := New(LoggingConfig{Service: "testing", DebugFilePath: filepath.Join(newDebugFilePath(t), "missing")})
+	assert.Error(t, err)
+
+	var logger loggerImpl
+	assert.Equal(t, errNoDebugFile, logger.EnableDebugFile(newDebugFilePath(t)))
+	assert.Equal(t, errNoDebugFile, logger.DisableDebugFile())
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Key of the field with the number of suppressed entries, see LoggingConfig.DedupConsecutive
+const RepeatedKey = "repeated"
+
+// dedupCore suppresses entries identical to the previous one: same level, message and fields including ones
+// added by With. Once a different entry comes or the core is synced, the last suppressed entry is written
+// with RepeatedKey field counting suppressed entries. State is shared by all loggers derived from the same New call.
+type dedupCore struct {
+	zapcore.Core
+
+	// Encodes level, message and fields as the identity of entries, context is encoded by With
+	enc zapcore.Encoder
+
+	state *dedupState
+}
+
+type dedupState struct {
+	mu sync.Mutex
+
+	// Identity of the previous entry
+	last string
+
+	// Suppressed entries of the current run and the last of them
+	repeated int
+	core     zapcore.Core
+	ent      zapcore.Entry
+	fields   []zapcore.Field
+}
+
+func newDedupCore(core zapcore.Core) zapcore.Core {
+	return &dedupCore{
+		Core: core,
+		enc: zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+			LevelKey:    "level",
+			MessageKey:  "message",
+			EncodeLevel: zapcore.LowercaseLevelEncoder,
+		}),
+		state: &dedupState{},
+	}
+}
+
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return &dedupCore{Core: c.Core.With(fields), enc: enc, state: c.state}
+}
+
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *dedupCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(zapcore.Entry{Level: ent.Level, Message: ent.Message}, fields)
+	if err != nil {
+		return c.Core.Write(ent, fields)
+	}
+	identity := buf.String()
+	buf.Free()
+
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Panic and fatal entries are always written, the process may not log anything after them
+	if identity == s.last && ent.Level < zapcore.DPanicLevel {
+		s.repeated++
+		// Copied, callers may reuse their slices
+		s.core, s.ent, s.fields = c.Core, ent, append(s.fields[:0], fields...)
+		return nil
+	}
+
+	err = s.flush()
+	s.last = identity
+	return multierr.Append(err, c.Core.Write(ent, fields))
+}
+
+func (c *dedupCore) Sync() error {
+	c.state.mu.Lock()
+	err := c.state.flush()
+	c.state.mu.Unlock()
+
+	return multierr.Append(err, c.Core.Sync())
+}
+
+// flush writes the last suppressed entry with their number and ends the run, must be called with the lock held
+func (s *dedupState) flush() error {
+	if s.repeated == 0 {
+		return nil
+	}
+
+	withRepeated := make([]zapcore.Field, 0, len(s.fields)+1)
+	withRepeated = append(withRepeated, s.fields...)
+	withRepeated = append(withRepeated, zap.Int(RepeatedKey, s.repeated))
+	err := s.core.Write(s.ent, withRepeated)
+
+	s.repeated = 0
+	s.core, s.ent, s.fields = nil, zapcore.Entry{}, s.fields[:0]
+	// Entry with the summary is different from the suppressed ones
+	s.last = ""
+	return err
+}
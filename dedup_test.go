@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// jsonEntries parses JSON lines written by the logger
+func jsonEntries(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestDedupConsecutive(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		Level:            "info",
+		DedupConsecutive: true,
+		Sequence:         true,
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		logger.With(Fields{"attempt": "same"}).Warn("connection lost")
+	}
+	logger.With(Fields{"attempt": "other"}).Warn("connection lost")
+	logger.Error("connection lost")
+	logger.Error("connection lost")
+	logger.Info("reconnected")
+	require.NoError(t, logger.Close())
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 6)
+
+	assert.Equal(t, "connection lost", entries[0]["message"])
+	assert.NotContains(t, entries[0], RepeatedKey)
+
+	// Summary of the run is the last suppressed entry
+	assert.Equal(t, "warn", entries[1]["level"])
+	assert.Equal(t, "same", entries[1]["attempt"])
+	assert.Equal(t, float64(4), entries[1][RepeatedKey])
+
+	// Fields differ
+	assert.Equal(t, "other", entries[2]["attempt"])
+	assert.NotContains(t, entries[2], RepeatedKey)
+
+	// Level differs
+	assert.Equal(t, "error", entries[3]["level"])
+	assert.NotContains(t, entries[3], "attempt")
+	assert.Equal(t, float64(1), entries[4][RepeatedKey])
+
+	assert.Equal(t, "reconnected", entries[5]["message"])
+
+	// Suppressed entries don't consume sequence numbers
+	for i, entry := range entries {
+		assert.Equal(t, float64(i+1), entry["seq"])
+	}
+}
+
+func TestDedupConsecutive_Sync(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", DedupConsecutive: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("tick")
+	logger.Info("tick")
+	logger.Info("tick")
+	require.NoError(t, logger.Sync())
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 2)
+	assert.Equal(t, float64(2), entries[1][RepeatedKey])
+
+	// Nothing to summarize
+	require.NoError(t, logger.Sync())
+	assert.Empty(t, buf.String())
+}
+
+func TestDedupConsecutive_Disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("tick")
+	logger.Info("tick")
+
+	assert.Len(t, jsonEntries(t, buf), 2)
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Nesting of values copied by deepCopy, deeper ones are shared, e.g. of cyclic pointers
+const maxDeepCopyDepth = 32
+
+// Types encoded by their own methods, copying them could break the methods, e.g. by copying a locked mutex
+var methodEncodedTypes = []reflect.Type{
+	reflect.TypeOf((*error)(nil)).Elem(),
+	reflect.TypeOf((*fmt.Stringer)(nil)).Elem(),
+	reflect.TypeOf((*json.Marshaler)(nil)).Elem(),
+	reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*zapcore.ObjectMarshaler)(nil)).Elem(),
+	reflect.TypeOf((*zapcore.ArrayMarshaler)(nil)).Elem(),
+}
+
+// deepCopyFields returns fields with mutable values copied, see LoggingConfig.DeepCopyFields
+func deepCopyFields(fields Fields) Fields {
+	copied := make(Fields, len(fields))
+	for k, v := range fields {
+		copied[k] = deepCopyValue(v)
+	}
+	return copied
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, string, int, int64, bool, float64, time.Duration, time.Time, zapcore.Field:
+		return value
+	}
+
+	v := reflect.ValueOf(value)
+	if isMethodEncoded(v.Type()) {
+		return value
+	}
+	return deepCopy(v, 0).Interface()
+}
+
+func isMethodEncoded(typ reflect.Type) bool {
+	for _, encoded := range methodEncodedTypes {
+		if typ.Implements(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// deepCopy copies maps, slices, arrays, pointers and exported fields of structs recursively.
+// Values of types encoded by their methods and unexported fields are shared.
+func deepCopy(v reflect.Value, depth int) reflect.Value {
+	if depth > maxDeepCopyDepth || isMethodEncoded(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopy(iter.Value(), depth+1))
+		}
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i), depth+1))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(deepCopy(v.Index(i), depth+1))
+		}
+		return copied
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(deepCopy(v.Elem(), depth+1))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(deepCopy(v.Elem(), depth+1))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := copied.Field(i); field.CanSet() {
+				field.Set(deepCopy(v.Field(i), depth+1))
+			}
+		}
+		return copied
+	default:
+		return v
+	}
+}
@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+type lockedCounter struct {
+	mu sync.Mutex
+}
+
+func (c *lockedCounter) String() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return "counter"
+}
+
+func TestDeepCopyFields(t *testing.T) {
+	for _, deepCopy := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		logger, err := newLogger(LoggingConfig{Service: "testing", DeepCopyFields: deepCopy}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		req := map[string]interface{}{"path": "/orders"}
+		ids := []int{1, 2}
+		withReq := logger.With(Fields{"req": req, "ids": ids})
+
+		// Caller reuses its values before the first entry
+		req["path"] = "/users"
+		ids[0] = 3
+		withReq.Info("handled")
+
+		value, ok := withReq.GetField("req")
+		require.True(t, ok)
+
+		entries := jsonEntries(t, buf)
+		require.Len(t, entries, 1)
+		if deepCopy {
+			assert.Equal(t, map[string]interface{}{"path": "/orders"}, entries[0]["req"])
+			assert.Equal(t, []interface{}{float64(1), float64(2)}, entries[0]["ids"])
+			assert.Equal(t, map[string]interface{}{"path": "/orders"}, value)
+		} else {
+			// Aliased values are changed retroactively
+			assert.Equal(t, map[string]interface{}{"path": "/users"}, entries[0]["req"])
+			assert.Equal(t, []interface{}{float64(3), float64(2)}, entries[0]["ids"])
+			assert.Equal(t, map[string]interface{}{"path": "/users"}, value)
+		}
+	}
+}
+
+func TestDeepCopyValue(t *testing.T) {
+	type inner struct {
+		Tags   []string
+		hidden map[string]int
+	}
+	type outer struct {
+		Inner  *inner
+		Values [2][]int
+		Any    interface{}
+	}
+
+	hidden := map[string]int{"a": 1}
+	original := &outer{
+		Inner:  &inner{Tags: []string{"a"}, hidden: hidden},
+		Values: [2][]int{{1}, {2}},
+		Any:    map[string]string{"k": "v"},
+	}
+	copied := deepCopyValue(original).(*outer)
+
+	original.Inner.Tags[0] = "changed"
+	original.Values[0][0] = 10
+	original.Any.(map[string]string)["k"] = "changed"
+
+	assert.Equal(t, []string{"a"}, copied.Inner.Tags)
+	assert.Equal(t, 1, copied.Values[0][0])
+	assert.Equal(t, map[string]string{"k": "v"}, copied.Any)
+
+	// Unexported fields are shared
+	hidden["a"] = 2
+	assert.Equal(t, 2, copied.Inner.hidden["a"])
+
+	// Values encoded by their methods are shared, copying a locked mutex would deadlock String
+	counter := &lockedCounter{}
+	assert.Same(t, counter, deepCopyValue(counter))
+
+	type node struct{ Next *node }
+	cyclic := &node{}
+	cyclic.Next = cyclic
+	assert.NotNil(t, deepCopyValue(cyclic))
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TypedLogger is the strongly-typed API for hot paths, a thin layer over *zap.Logger.
+// Fields are passed as zap.Field, so they aren't boxed into interface{} and inspected by reflection,
+// and messages aren't formatted, see BenchmarkTypedLogger_Info compared with BenchmarkLoggerImpl_InfoField.
+// Entries go to the same outputs with the same fields as the Logger it was created from.
+type TypedLogger interface {
+	Debug(msg string, fields ...zap.Field)
+	Info(msg string, fields ...zap.Field)
+	Warn(msg string, fields ...zap.Field)
+	Error(msg string, fields ...zap.Field)
+	Panic(msg string, fields ...zap.Field)
+	Fatal(msg string, fields ...zap.Field)
+
+	// Returns logger with additional fields, a field replaces one with the same key like in Fields.
+	// zap.Namespace isn't supported, use Namespace of Logger instead.
+	With(fields ...zap.Field) TypedLogger
+
+	// Returns sugared logger with the same fields
+	Sugar() Logger
+}
+
+var _ TypedLogger = typedLogger{}
+
+// Field is strongly-typed field of TypedLogger and level methods like InfoZ, built by zap constructors, e.g. zap.Int
+type Field = zap.Field
+
+type typedLogger struct {
+	logger loggerImpl
+
+	// Prepared logger of logger, skips typedLogger methods
+	base *zap.Logger
+}
+
+// Typed returns typed façade of the logger
+func (l loggerImpl) Typed() TypedLogger {
+	return l.typed()
+}
+
+// typed builds typed logger once per prepared logger, so level methods taking fields don't clone zap logger
+func (l loggerImpl) typed() typedLogger {
+	if l.prepared != nil {
+		if base, ok := l.prepared.typed.Load().(*zap.Logger); ok {
+			return typedLogger{logger: l, base: base}
+		}
+	}
+
+	base := l.prepare().Desugar().WithOptions(zap.AddCallerSkip(1))
+	if l.prepared != nil {
+		l.prepared.typed.Store(base)
+	}
+	return typedLogger{logger: l, base: base}
+}
+
+func (l loggerImpl) DebugZ(msg string, fields ...Field) {
+	if l.disabled(zapcore.DebugLevel) {
+		return
+	}
+	l.typed().write(zapcore.DebugLevel, msg, fields)
+}
+
+func (l loggerImpl) InfoZ(msg string, fields ...Field) {
+	if l.disabled(zapcore.InfoLevel) {
+		return
+	}
+	l.typed().write(zapcore.InfoLevel, msg, fields)
+}
+
+func (l loggerImpl) WarnZ(msg string, fields ...Field) {
+	if l.disabled(zapcore.WarnLevel) {
+		return
+	}
+	l.typed().write(zapcore.WarnLevel, msg, fields)
+}
+
+func (l loggerImpl) ErrorZ(msg string, fields ...Field) {
+	if l.disabled(zapcore.ErrorLevel) {
+		return
+	}
+	l.typed().write(zapcore.ErrorLevel, msg, fields)
+}
+
+func (t typedLogger) write(level zapcore.Level, msg string, fields []zap.Field) {
+	ce := t.base.Check(level, msg)
+	if ce == nil {
+		return
+	}
+
+	if t.logger.lazy != nil {
+		lazy, collision := lazyFields(t.logger.lazy, t.logger.collisionPolicy())
+		t.logger.reportCollision(collision)
+		// Full slice expression so the caller's slice isn't modified
+		fields = append(fields[:len(fields):len(fields)], lazy...)
+	}
+	ce.Write(fields...)
+}
+
+func (t typedLogger) Debug(msg string, fields ...zap.Field) {
+	t.write(zapcore.DebugLevel, msg, fields)
+}
+
+func (t typedLogger) Info(msg string, fields ...zap.Field) {
+	t.write(zapcore.InfoLevel, msg, fields)
+}
+
+func (t typedLogger) Warn(msg string, fields ...zap.Field) {
+	t.write(zapcore.WarnLevel, msg, fields)
+}
+
+func (t typedLogger) Error(msg string, fields ...zap.Field) {
+	t.write(zapcore.ErrorLevel, msg, fields)
+}
+
+func (t typedLogger) Panic(msg string, fields ...zap.Field) {
+	t.write(zapcore.PanicLevel, msg, fields)
+}
+
+func (t typedLogger) Fatal(msg string, fields ...zap.Field) {
+	t.write(zapcore.FatalLevel, msg, fields)
+}
+
+func (t typedLogger) With(fields ...zap.Field) TypedLogger {
+	if len(fields) == 0 {
+		return t
+	}
+
+	// Kept in Fields, so Sugar and GetField see them
+	typed := make(Fields, len(fields))
+	for _, field := range fields {
+		typed[field.Key] = field
+	}
+	return t.logger.with(typed).Typed()
+}
+
+func (t typedLogger) Sugar() Logger {
+	return t.logger
+}
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTyped(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "info", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	typed := logger.With(Fields{"a": "b"}).
+		WithLazy(func() Fields { return Fields{"lazy": 1} }).
+		Typed().
+		With(zap.Int("attempt", 1))
+	typed.Debug("dropped")
+	typed.Info("typed", zap.String("key", "value"), zap.Bool("ok", true))
+	typed.With(zap.Int("attempt", 2)).Warn("retry")
+
+	sugared := typed.Sugar()
+	sugared.Info("sugared")
+	value, ok := sugared.GetField("attempt")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), value)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "typed", entries[0]["message"])
+	assert.Equal(t, "testing", entries[0]["service"])
+	assert.Equal(t, "default", entries[0]["namespace"])
+	assert.Equal(t, "b", entries[0]["a"])
+	assert.Equal(t, float64(1), entries[0]["attempt"])
+	assert.Equal(t, float64(1), entries[0]["lazy"])
+	assert.Equal(t, "value", entries[0]["key"])
+	assert.Equal(t, true, entries[0]["ok"])
+	assert.Contains(t, entries[0]["caller"], "desugar_test.go")
+
+	assert.Equal(t, "warn", entries[1]["level"])
+	assert.Equal(t, float64(2), entries[1]["attempt"])
+
+	assert.Equal(t, "sugared", entries[2]["message"])
+	assert.Equal(t, float64(1), entries[2]["attempt"])
+}
+
+func TestTyped_Panic(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	assert.Panics(t, func() { logger.Typed().Panic("boom", zap.Int("code", 1)) })
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "panic", entries[0]["level"])
+	assert.Equal(t, float64(1), entries[0]["code"])
+}
+
+func TestLoggerImpl_InfoZ(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "info", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	derived := logger.With(Fields{"a": "b"}).WithLazy(func() Fields { return Fields{"lazy": 1} })
+	derived.DebugZ("dropped", zap.Int("attempt", 0))
+	derived.InfoZ("typed", zap.Int("attempt", 1))
+	derived.WarnZ("retry", zap.Int("attempt", 2))
+	derived.ErrorZ("failed")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "typed", entries[0]["message"])
+	assert.Equal(t, "default", entries[0]["namespace"])
+	assert.Equal(t, "b", entries[0]["a"])
+	assert.Equal(t, float64(1), entries[0]["lazy"])
+	assert.Equal(t, float64(1), entries[0]["attempt"])
+	assert.Contains(t, entries[0]["caller"], "desugar_test.go")
+
+	assert.Equal(t, "warn", entries[1]["level"])
+	assert.Equal(t, float64(2), entries[1]["attempt"])
+	assert.Equal(t, "error", entries[2]["level"])
+	assert.Contains(t, entries[2]["caller"], "desugar_test.go")
+}
@@ -0,0 +1,101 @@
+// Package echoadapter logs echo requests with the same fields as logger.HTTPMiddleware and ginadapter
+package echoadapter
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/w84thesun/logger"
+)
+
+// Key of the request-scoped logger in echo.Context, see FromContext
+const ContextKey = "logger"
+
+// Key of the error returned by the handler
+const ErrorKey = "error"
+
+type options struct {
+	skipPaths map[string]struct{}
+}
+
+// Configures Middleware
+type Option func(*options)
+
+// Disables access entries for the paths, e.g. health checks. Handlers still get request-scoped logger.
+func WithSkipPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			o.skipPaths[path] = struct{}{}
+		}
+	}
+}
+
+// Middleware stores request-scoped logger in echo.Context and the request context,
+// logs an access entry per request and turns handler panics into 500 responses
+func Middleware(l logger.Logger, opts ...Option) echo.MiddlewareFunc {
+	o := options{skipPaths: map[string]struct{}{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			start := time.Now()
+			r := c.Request()
+
+			requestID := r.Header.Get(logger.RequestIDHeader)
+			if requestID == "" {
+				requestID = logger.NewRequestID()
+			}
+			c.Response().Header().Set(logger.RequestIDHeader, requestID)
+
+			requestLogger := l.With(logger.Fields{
+				logger.RequestIDKey: requestID,
+				logger.MethodKey:    r.Method,
+				logger.PathKey:      r.URL.Path,
+				logger.RouteKey:     c.Path(),
+				logger.ClientIPKey:  c.RealIP(),
+			})
+			c.Set(ContextKey, requestLogger)
+			c.SetRequest(r.WithContext(logger.NewContext(r.Context(), requestLogger)))
+
+			defer func() {
+				if i := recover(); i != nil {
+					logger.TraceHandlerPanic(requestLogger, i)
+					err = nil
+
+					if !c.Response().Committed {
+						c.Error(echo.NewHTTPError(http.StatusInternalServerError))
+					}
+					// Too late to change the response if committed, make the access entry reflect the failure
+					c.Response().Status = http.StatusInternalServerError
+				}
+
+				if _, ok := o.skipPaths[r.URL.Path]; ok {
+					return
+				}
+
+				accessLogger := requestLogger
+				if err != nil {
+					accessLogger = accessLogger.With(logger.Fields{ErrorKey: err.Error()})
+				}
+				logger.LogAccess(accessLogger, c.Response().Status, int(c.Response().Size), time.Since(start))
+			}()
+
+			if err = next(c); err != nil {
+				// Lets echo write the error response, so the entry has its status.
+				// Returns the error anyway like other echo middlewares do
+				c.Error(err)
+			}
+			return err
+		}
+	}
+}
+
+// Returns request-scoped logger stored by Middleware
+func FromContext(c echo.Context) (logger.Logger, bool) {
+	l, ok := c.Get(ContextKey).(logger.Logger)
+	return l, ok
+}
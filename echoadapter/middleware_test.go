@@ -0,0 +1,141 @@
+package echoadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func newServer(l logger.Logger, opts ...Option) *echo.Echo {
+	e := echo.New()
+	e.Use(Middleware(l, opts...))
+
+	e.POST("/orders/:id", func(c echo.Context) error {
+		requestLogger, ok := FromContext(c)
+		if !ok {
+			return echo.NewHTTPError(http.StatusInternalServerError, "no logger")
+		}
+		requestLogger.Info("handling")
+
+		ctxLogger, ok := logger.FromContext(c.Request().Context())
+		if !ok {
+			return echo.NewHTTPError(http.StatusInternalServerError, "no context logger")
+		}
+		ctxLogger.Debug("from request context")
+
+		return c.String(http.StatusCreated, "created")
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "no such order")
+	})
+	e.GET("/panic", func(c echo.Context) error {
+		panic("boom")
+	})
+	e.GET("/abort", func(c echo.Context) error {
+		panic(http.ErrAbortHandler)
+	})
+	e.GET("/health", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	return e
+}
+
+func serve(e *echo.Echo, method, target string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	r.Header.Set(echo.HeaderXForwardedFor, "203.0.113.7")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	l, entries := newTestLogger(t)
+	e := newServer(l)
+
+	w := serve(e, http.MethodPost, "/orders/42")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	requestID := w.Header().Get(logger.RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, "handling", got[0]["message"])
+	assert.Equal(t, "from request context", got[1]["message"])
+
+	access := got[2]
+	assert.Equal(t, "info", access["level"])
+	assert.Equal(t, "request completed", access["message"])
+	assert.Equal(t, requestID, access[logger.RequestIDKey])
+	assert.Equal(t, http.MethodPost, access[logger.MethodKey])
+	assert.Equal(t, "/orders/42", access[logger.PathKey])
+	assert.Equal(t, "/orders/:id", access[logger.RouteKey])
+	assert.Equal(t, "203.0.113.7", access[logger.ClientIPKey])
+	assert.Equal(t, float64(http.StatusCreated), access[logger.StatusKey])
+	assert.Equal(t, float64(len("created")), access[logger.BytesOutKey])
+	assert.Contains(t, access, logger.LatencyKey)
+	for _, entry := range got {
+		assert.Equal(t, requestID, entry[logger.RequestIDKey])
+	}
+}
+
+func TestMiddleware_Error(t *testing.T) {
+	l, entries := newTestLogger(t)
+	e := newServer(l)
+
+	w := serve(e, http.MethodGet, "/missing")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "warn", got[0]["level"])
+	assert.Equal(t, float64(http.StatusNotFound), got[0][logger.StatusKey])
+	assert.Contains(t, got[0][ErrorKey], "no such order")
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	l, entries := newTestLogger(t)
+	e := newServer(l)
+
+	w := serve(e, http.MethodGet, "/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "error", got[0]["level"])
+	assert.Contains(t, got[0]["message"], "boom")
+	assert.Equal(t, "error", got[1]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), got[1][logger.StatusKey])
+	assert.Equal(t, "/panic", got[1][logger.RouteKey])
+}
+
+// http.ErrAbortHandler reaches net/http, which aborts the response without logging
+func TestMiddleware_AbortHandler(t *testing.T) {
+	l, entries := newTestLogger(t)
+	e := newServer(l)
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		serve(e, http.MethodGet, "/abort")
+	})
+	assert.Empty(t, entries())
+}
+
+func TestMiddleware_SkipPaths(t *testing.T) {
+	l, entries := newTestLogger(t)
+	e := newServer(l, WithSkipPaths("/health"))
+
+	serve(e, http.MethodGet, "/health")
+	assert.Empty(t, entries())
+}
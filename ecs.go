@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Version of Elastic Common Schema written as ecs.version by ECSCompat
+const ECSVersion = "1.6.0"
+
+// ECS fields written by ECSCompat, dots are expanded to nested objects by Elasticsearch.
+// See https://www.elastic.co/guide/en/ecs-logging/overview/current/intro.html
+const (
+	ECSVersionKey    = "ecs.version"
+	ECSLevelKey      = "log.level"
+	ECSLoggerKey     = "log.logger"
+	ECSServiceKey    = "service.name"
+	ECSFileNameKey   = "log.origin.file.name"
+	ECSFileLineKey   = "log.origin.file.line"
+	ECSFunctionKey   = "log.origin.function"
+	ECSStacktraceKey = "error.stack_trace"
+)
+
+// newECSEncoderConfig uses ECS names for entry keys, caller is written by ecsCore
+func newECSEncoderConfig(encoderConfig zapcore.EncoderConfig) zapcore.EncoderConfig {
+	encoderConfig.TimeKey = "@timestamp"
+	encoderConfig.LevelKey = ECSLevelKey
+	encoderConfig.MessageKey = "message"
+	encoderConfig.NameKey = ECSLoggerKey
+	encoderConfig.CallerKey = ""
+	encoderConfig.StacktraceKey = ECSStacktraceKey
+	encoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+	return encoderConfig
+}
+
+// serviceKey returns key of the service field, ECS puts it into service object
+func serviceKey(config LoggingConfig) string {
+	if config.ECSCompat {
+		return ECSServiceKey
+	}
+	return "service"
+}
+
+// ecsCore adds ecs.version and splits caller into ECS log.origin fields, which a caller encoder can't do
+type ecsCore struct {
+	zapcore.Core
+}
+
+func newECSCore(core zapcore.Core) zapcore.Core {
+	return &ecsCore{Core: core.With([]zapcore.Field{zap.String(ECSVersionKey, ECSVersion)})}
+}
+
+func (c *ecsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &ecsCore{Core: c.Core.With(fields)}
+}
+
+func (c *ecsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *ecsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Caller.Defined {
+		file := ent.Caller.TrimmedPath()
+		if i := strings.LastIndexByte(file, ':'); i >= 0 {
+			file = file[:i]
+		}
+
+		fields = append(fields[:len(fields):len(fields)],
+			zap.String(ECSFileNameKey, file),
+			zap.Int(ECSFileLineKey, ent.Caller.Line),
+			zap.String(ECSFunctionKey, ent.Caller.Function),
+		)
+		ent.Caller = zapcore.EntryCaller{}
+	}
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// expandDots nests dotted keys like Elasticsearch does, e.g. "log.level" becomes {"log": {"level": ...}}
+func expandDots(t *testing.T, entry map[string]interface{}) map[string]interface{} {
+	nested := map[string]interface{}{}
+	for key, value := range entry {
+		parts := strings.Split(key, ".")
+		current := nested
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				require.NotContains(t, current, part, "key %v conflicts with object", key)
+				next = map[string]interface{}{}
+				current[part] = next
+			}
+			current = next
+		}
+		require.NotContains(t, current, parts[len(parts)-1], "object %v conflicts with key", key)
+		current[parts[len(parts)-1]] = value
+	}
+	return nested
+}
+
+func TestECSCompat(t *testing.T) {
+	for _, sortKeys := range []bool{false, true} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{
+			Service:         "testing",
+			Namespace:       "default",
+			Level:           "info",
+			SortKeys:        sortKeys,
+			Caller:          true,
+			StacktraceLevel: "error",
+			ECSCompat:       true,
+		}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.With(Fields{"order_id": 1}).Info("info")
+		logger.Error("error")
+
+		entries := buf.entries(t)
+		require.Len(t, entries, 2)
+
+		for _, entry := range entries {
+			nested := expandDots(t, entry)
+			assert.Contains(t, nested, "@timestamp")
+			assert.Equal(t, map[string]interface{}{"version": ECSVersion}, nested["ecs"])
+			assert.Equal(t, map[string]interface{}{"name": "testing"}, nested["service"])
+			assert.Equal(t, "default", nested["namespace"])
+
+			log := nested["log"].(map[string]interface{})
+			origin := log["origin"].(map[string]interface{})
+			file := origin["file"].(map[string]interface{})
+			assert.Contains(t, file["name"], "ecs_test.go")
+			assert.NotContains(t, file["name"], ":")
+			assert.Greater(t, file["line"], float64(0))
+			assert.Contains(t, origin["function"], "TestECSCompat")
+			assert.NotContains(t, entry, "caller")
+			assert.NotContains(t, entry, "level")
+		}
+
+		assert.Equal(t, "info", entries[0][ECSLevelKey])
+		assert.Equal(t, "info", entries[0]["message"])
+		assert.Equal(t, float64(1), entries[0]["order_id"])
+
+		assert.Equal(t, "error", entries[1][ECSLevelKey])
+		assert.Contains(t, entries[1][ECSStacktraceKey], "TestECSCompat")
+	}
+}
+
+func TestECSCompat_Datadog(t *testing.T) {
+	_, err := newLogger(LoggingConfig{Service: "testing", ECSCompat: true, DatadogCompat: true}, zapcore.AddSync(ioutil.Discard))
+	assert.Error(t, err)
+}
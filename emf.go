@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Message of entries written by EMF
+const emfMessage = "metrics"
+
+// Structure of "_aws" metadata, see
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html
+type emfMetadata struct {
+	Timestamp         int64                 `json:"Timestamp"`
+	CloudWatchMetrics []emfMetricsDirective `json:"CloudWatchMetrics"`
+}
+
+type emfMetricsDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+}
+
+func (l loggerImpl) EMF(namespace string, metrics Fields, dimensions Fields) error {
+	if namespace == "" {
+		return fmt.Errorf("emf namespace must not be empty")
+	}
+	if len(metrics) == 0 {
+		return fmt.Errorf("emf requires at least one metric")
+	}
+
+	fields := make(Fields, len(metrics)+len(dimensions)+1)
+
+	directive := emfMetricsDirective{
+		Namespace:  namespace,
+		Dimensions: [][]string{{}},
+	}
+
+	for _, name := range sortedKeys(metrics) {
+		value := metrics[name]
+		if err := validateEMFName(name); err != nil {
+			return err
+		}
+		if !isNumber(value) {
+			return fmt.Errorf("emf metric %v must be a finite number, got %T", name, value)
+		}
+
+		directive.Metrics = append(directive.Metrics, emfMetric{Name: name})
+		fields[name] = value
+	}
+
+	for _, name := range sortedKeys(dimensions) {
+		if err := validateEMFName(name); err != nil {
+			return err
+		}
+		if _, ok := metrics[name]; ok {
+			return fmt.Errorf("emf dimension %v is also a metric", name)
+		}
+
+		directive.Dimensions[0] = append(directive.Dimensions[0], name)
+		fields[name] = fmt.Sprint(dimensions[name])
+	}
+
+	fields["_aws"] = emfMetadata{
+		Timestamp:         time.Now().UnixNano() / int64(time.Millisecond),
+		CloudWatchMetrics: []emfMetricsDirective{directive},
+	}
+
+	l.skipCaller(1).With(fields).Info(emfMessage)
+
+	return nil
+}
+
+func validateEMFName(name string) error {
+	if _, ok := ignore[name]; ok || name == "_aws" {
+		return fmt.Errorf("emf name %v is reserved", name)
+	}
+	return nil
+}
+
+func isNumber(value interface{}) bool {
+	v := reflect.ValueOf(value)
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return !math.IsNaN(f) && !math.IsInf(f, 0)
+	default:
+		return false
+	}
+}
+
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
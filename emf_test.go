@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_EMF(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	err = logger.EMF("orders",
+		Fields{"latency": 12.5, "count": 3},
+		Fields{"region": "eu-west-1", "shard": 7},
+	)
+	require.NoError(t, err)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+
+	assert.Equal(t, 12.5, entry["latency"])
+	assert.Equal(t, float64(3), entry["count"])
+	assert.Equal(t, "eu-west-1", entry["region"])
+	assert.Equal(t, "7", entry["shard"])
+	assert.Equal(t, "default", entry["namespace"])
+
+	aws, ok := entry["_aws"].(map[string]interface{})
+	require.True(t, ok, "_aws envelope is missing")
+	assert.GreaterOrEqual(t, aws["Timestamp"], float64(before))
+
+	directives, ok := aws["CloudWatchMetrics"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, directives, 1)
+
+	assert.Equal(t, map[string]interface{}{
+		"Namespace":  "orders",
+		"Dimensions": []interface{}{[]interface{}{"region", "shard"}},
+		"Metrics": []interface{}{
+			map[string]interface{}{"Name": "count"},
+			map[string]interface{}{"Name": "latency"},
+		},
+	}, directives[0])
+}
+
+func TestLoggerImpl_EMFCaller(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	err = logger.EMF("orders", Fields{"count": 1}, nil)
+	_, _, line, _ := runtime.Caller(0)
+	require.NoError(t, err)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.True(t, strings.HasSuffix(entries[0]["caller"].(string), "emf_test.go:"+strconv.Itoa(line-1)), entries[0]["caller"])
+}
+
+func TestLoggerImpl_EMFInvalid(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		namespace  string
+		metrics    Fields
+		dimensions Fields
+	}{
+		{name: "empty namespace", namespace: "", metrics: Fields{"count": 1}},
+		{name: "no metrics", namespace: "orders"},
+		{name: "string metric", namespace: "orders", metrics: Fields{"count": "1"}},
+		{name: "nan metric", namespace: "orders", metrics: Fields{"count": math.NaN()}},
+		{name: "inf metric", namespace: "orders", metrics: Fields{"count": math.Inf(1)}},
+		{name: "reserved metric", namespace: "orders", metrics: Fields{"service": 1}},
+		{
+			name:       "dimension is metric",
+			namespace:  "orders",
+			metrics:    Fields{"count": 1},
+			dimensions: Fields{"count": "1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, logger.EMF(tt.namespace, tt.metrics, tt.dimensions))
+		})
+	}
+
+	assert.Empty(t, buf.entries(t))
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// emptyMessageCore warns once about the first entry with empty message, see LoggingConfig.WarnOnEmptyMessage
+type emptyMessageCore struct {
+	zapcore.Core
+
+	// Shared by derived cores, set once warned
+	warned *int32
+}
+
+func newEmptyMessageCore(core zapcore.Core) zapcore.Core {
+	return &emptyMessageCore{Core: core, warned: new(int32)}
+}
+
+func (c *emptyMessageCore) With(fields []zapcore.Field) zapcore.Core {
+	return &emptyMessageCore{Core: c.Core.With(fields), warned: c.warned}
+}
+
+func (c *emptyMessageCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Message == "" && atomic.LoadInt32(c.warned) == 0 && c.Enabled(ent.Level) &&
+		atomic.CompareAndSwapInt32(c.warned, 0, 1) {
+		log.Printf("entry with empty message logged at %s, further ones aren't reported", externalCaller())
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// externalCaller returns file:line of the first frame outside of the logger and zap
+func externalCaller() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, "go.uber.org/zap") ||
+			filepath.Dir(frame.File) == packageDir && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown caller"
+		}
+	}
+}
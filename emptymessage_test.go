@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// captureLog redirects the standard logger to the returned buffer for the test
+func captureLog(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	prev, prevFlags := log.Writer(), log.Flags()
+	log.SetOutput(buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(prev)
+		log.SetFlags(prevFlags)
+	})
+	return buf
+}
+
+func TestWarnOnEmptyMessage(t *testing.T) {
+	warnings := captureLog(t)
+
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", WarnOnEmptyMessage: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Debug()
+	logger.Info("not empty")
+	assert.Empty(t, warnings.String())
+
+	logger.With(Fields{"key": "value"}).Info()
+	logger.Warnf("")
+	logger.ErrorZ("")
+
+	lines := strings.Split(strings.TrimSpace(warnings.String()), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], "entry with empty message logged at ")
+	assert.Contains(t, lines[0], "emptymessage_test.go:")
+
+	// Entries are written anyway
+	assert.Len(t, jsonEntries(t, buf), 4)
+}
+
+func TestWarnOnEmptyMessage_Disabled(t *testing.T) {
+	warnings := captureLog(t)
+
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger.Info()
+	assert.Empty(t, warnings.String())
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Builds an encoder for custom FormatStdout registered with RegisterEncoder
+type EncoderConstructor func(zapcore.EncoderConfig) (zapcore.Encoder, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderConstructor{}
+)
+
+// Makes a custom encoder available as FormatStdout value by its name, replacing previous registration if any.
+// Only loggers constructed after registration are affected.
+func RegisterEncoder(name string, constructor EncoderConstructor) error {
+	if name == "" {
+		return fmt.Errorf("encoder name must not be empty")
+	}
+	if constructor == nil {
+		return fmt.Errorf("encoder %v constructor must not be nil", name)
+	}
+	if isBuiltinFormat(name) {
+		return fmt.Errorf("encoder %v is built in and can't be overridden", name)
+	}
+
+	encodersMu.Lock()
+	encoders[name] = constructor
+	encodersMu.Unlock()
+
+	return nil
+}
+
+func getEncoderConstructor(name string) (EncoderConstructor, bool) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	constructor, ok := encoders[name]
+	return constructor, ok
+}
+
+func isBuiltinFormat(name string) bool {
+	return name == FormatJSON || name == FormatPretty || name == FormatGCP
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+var kvPool = buffer.NewPool()
+
+// kvEncoder writes entries as sorted key=value pairs
+type kvEncoder struct {
+	*zapcore.MapObjectEncoder
+}
+
+func newKVEncoder(zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	return kvEncoder{zapcore.NewMapObjectEncoder()}, nil
+}
+
+func (e kvEncoder) Clone() zapcore.Encoder {
+	clone := zapcore.NewMapObjectEncoder()
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return kvEncoder{clone}
+}
+
+func (e kvEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := e.Clone().(kvEncoder)
+	final.AddString("level", ent.Level.String())
+	final.AddString("message", ent.Message)
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+
+	keys := make([]string, 0, len(final.Fields))
+	for k := range final.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf := kvPool.Get()
+	for i, k := range keys {
+		if i > 0 {
+			buf.AppendByte(' ')
+		}
+		buf.AppendString(k)
+		buf.AppendByte('=')
+		buf.AppendString(fmt.Sprint(final.Fields[k]))
+	}
+	buf.AppendByte('\n')
+
+	return buf, nil
+}
+
+func ExampleRegisterEncoder() {
+	if err := RegisterEncoder("kv", newKVEncoder); err != nil {
+		panic(err)
+	}
+
+	logger, err := New(LoggingConfig{
+		Service:      "example",
+		Namespace:    "default",
+		Level:        "info",
+		FormatStdout: "kv",
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	logger.With(Fields{"user": 42}).Info("registered")
+	// Output:
+	// level=info message=registered namespace=default service=example user=42
+}
+
+func TestRegisterEncoder_Invalid(t *testing.T) {
+	assert.Error(t, RegisterEncoder("", newKVEncoder))
+	assert.Error(t, RegisterEncoder("nil", nil))
+	assert.Error(t, RegisterEncoder(FormatJSON, newKVEncoder))
+	assert.Error(t, RegisterEncoder(FormatPretty, newKVEncoder))
+}
+
+func TestRegisterEncoder_UnknownFormat(t *testing.T) {
+	_, err := New(LoggingConfig{FormatStdout: "not-registered"})
+	assert.Error(t, err)
+}
+
+func TestRegisterEncoder_ConstructorError(t *testing.T) {
+	require.NoError(t, RegisterEncoder("broken", func(zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return nil, assert.AnError
+	}))
+
+	_, err := newLogger(LoggingConfig{FormatStdout: "broken"}, zapcore.AddSync(&bytes.Buffer{}))
+	assert.Equal(t, assert.AnError, err)
+}
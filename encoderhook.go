@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// encoderHookCore passes every written entry through LoggingConfig.EncoderHook once, before the outputs encode it
+type encoderHookCore struct {
+	zapcore.Core
+
+	hook func(ent *zapcore.Entry, fields []zapcore.Field) []zapcore.Field
+}
+
+func newEncoderHookCore(core zapcore.Core, hook func(*zapcore.Entry, []zapcore.Field) []zapcore.Field) zapcore.Core {
+	return &encoderHookCore{Core: core, hook: hook}
+}
+
+func (c *encoderHookCore) With(fields []zapcore.Field) zapcore.Core {
+	return &encoderHookCore{Core: c.Core.With(fields), hook: c.hook}
+}
+
+func (c *encoderHookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *encoderHookCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// Full slice expression, so fields appended by the hook don't overwrite the caller's array
+	fields = c.hook(&ent, fields[:len(fields):len(fields)])
+	return c.Core.Write(ent, fields)
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEncoderHook(t *testing.T) {
+	sink := &httpSink{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	var calls int32
+	hash := func(message string) string {
+		sum := sha256.Sum256([]byte(message))
+		return hex.EncodeToString(sum[:])
+	}
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:      "testing",
+		Level:        "info",
+		Sequence:     true,
+		HTTPEndpoint: server.URL,
+		EncoderHook: func(ent *zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+			atomic.AddInt32(&calls, 1)
+			ent.Message = strings.ToUpper(ent.Message)
+			return append(fields, zap.String("hash", hash(ent.Message)))
+		},
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Debug("dropped")
+	logger.With(Fields{"key": "value"}).Info("first")
+	logger.Warn("second")
+	require.NoError(t, logger.Close())
+
+	// Once per entry for both outputs
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	batches, _ := sink.received()
+	require.Len(t, batches, 1)
+	var sent []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(batches[0]), "\n") {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		sent = append(sent, entry)
+	}
+
+	for _, entries := range [][]map[string]interface{}{buf.entries(t), sent} {
+		require.Len(t, entries, 2)
+		assert.Equal(t, "FIRST", entries[0]["message"])
+		assert.Equal(t, hash("FIRST"), entries[0]["hash"])
+		assert.Equal(t, "value", entries[0]["key"])
+		assert.Equal(t, hash("SECOND"), entries[1]["hash"])
+		assert.Equal(t, float64(2), entries[1]["seq"])
+	}
+}
+
+func TestEncoderHook_CallerFields(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{
+		Service: "testing",
+		EncoderHook: func(_ *zapcore.Entry, fields []zapcore.Field) []zapcore.Field {
+			return append(fields, zap.Bool("hooked", true))
+		},
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	// Appended field doesn't overwrite spare capacity of the passed slice
+	fields := make([]zap.Field, 1, 2)
+	fields[0] = zap.Int("attempt", 1)
+	logger.Typed().Info("typed", fields...)
+	assert.Equal(t, zap.Field{}, fields[:2][1])
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Key of the field added with LoggingConfig.EntryID
+const EntryIDKey = "entry_id"
+
+// Crockford's base32 alphabet of ULIDs
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// entryIDCore attaches "entry_id" ULID to every written entry, e.g. for deduplication downstream.
+// Generator is shared by all loggers derived with With/Namespace from the same New call.
+type entryIDCore struct {
+	zapcore.Core
+
+	ids *entryIDGenerator
+}
+
+func newEntryIDCore(core zapcore.Core) zapcore.Core {
+	return &entryIDCore{Core: core, ids: newEntryIDGenerator()}
+}
+
+func (c *entryIDCore) With(fields []zapcore.Field) zapcore.Core {
+	return &entryIDCore{Core: c.Core.With(fields), ids: c.ids}
+}
+
+func (c *entryIDCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entryIDCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	withID := make([]zapcore.Field, 0, len(fields)+1)
+	withID = append(withID, fields...)
+	withID = append(withID, zap.String(EntryIDKey, c.ids.next(ent.Time)))
+
+	return c.Core.Write(ent, withID)
+}
+
+// entryIDGenerator makes ULIDs of 48 bits of milliseconds and 80 bits of entropy: 16 random bits fixed per generator
+// and a counter starting at a random value. Ids are unique within the generator without reading random bytes
+// per entry and sort by time, random start makes collisions of different processes unlikely.
+type entryIDGenerator struct {
+	counter uint64
+	node    uint16
+}
+
+func newEntryIDGenerator() *entryIDGenerator {
+	var seed [10]byte
+	// Ids stay unique within the process if reading fails, only collisions with other processes become likely
+	_, _ = rand.Read(seed[:])
+	return &entryIDGenerator{
+		counter: binary.BigEndian.Uint64(seed[:8]),
+		node:    binary.BigEndian.Uint16(seed[8:]),
+	}
+}
+
+func (g *entryIDGenerator) next(t time.Time) string {
+	hi := uint64(t.UnixNano()/int64(time.Millisecond))<<16 | uint64(g.node)
+	lo := atomic.AddUint64(&g.counter, 1)
+
+	// 26 characters of 5 bits encode 128 bits from the last one, the first character gets the top 3 bits
+	var id [26]byte
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(id[:])
+}
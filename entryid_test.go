@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestEntryID(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", EntryID: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	const goroutines, perGoroutine = 8, 500
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			derived := logger.With(Fields{"a": "b"}).Namespace("derived")
+			for i := 0; i < perGoroutine; i++ {
+				derived.Info("entry")
+				// Disabled entries must not consume ids
+				derived.Debug("skipped")
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries := buf.entries(t)
+	require.Len(t, entries, goroutines*perGoroutine)
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		id, ok := entry[EntryIDKey].(string)
+		require.True(t, ok, "entry_id is missing in %v", entry)
+		assert.Len(t, id, 26)
+		assert.False(t, seen[id], "duplicate entry_id %s", id)
+		seen[id] = true
+	}
+}
+
+func TestEntryID_Disabled(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("entry")
+	assert.NotContains(t, buf.entries(t)[0], EntryIDKey)
+}
+
+func TestEntryIDGenerator(t *testing.T) {
+	ids := newEntryIDGenerator()
+	other := newEntryIDGenerator()
+	start := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	var generated []string
+	seen := map[string]bool{}
+	for i := 0; i < 10000; i++ {
+		id := ids.next(start.Add(time.Duration(i) * time.Millisecond))
+		generated = append(generated, id)
+		seen[id] = true
+		seen[other.next(start)] = true
+	}
+	assert.Len(t, seen, 20000)
+
+	// Sorted by time, characters are of Crockford's alphabet
+	assert.True(t, sort.StringsAreSorted(generated))
+	for _, id := range generated {
+		for _, c := range id {
+			assert.Contains(t, crockfordAlphabet, string(c))
+		}
+	}
+
+	// The first 10 characters are milliseconds like in other ULID implementations
+	var ms int64
+	for _, c := range generated[0][:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockfordAlphabet, c))
+	}
+	assert.Equal(t, start.UnixNano()/int64(time.Millisecond), ms)
+}
+
+func BenchmarkEntryIDGenerator(b *testing.B) {
+	ids := newEntryIDGenerator()
+	now := time.Now()
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ids.next(now)
+		}
+	})
+}
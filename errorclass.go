@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+// Key of the field set by WithErrorClass
+const ErrorClassKey = "error.class"
+
+// Conventional classes of errors for alerting, see ClassifyError
+const (
+	// Failures likely to go away on retry, e.g. timeouts and dropped connections
+	ErrorClassTransient = "transient"
+	// Failures caused by the caller, e.g. malformed input or a cancelled request
+	ErrorClassClient = "client"
+	// Failures of the service itself, e.g. bugs and broken invariants
+	ErrorClassInternal = "internal"
+)
+
+// ErrorClasser is implemented by errors knowing their class, ClassifyError prefers it to the defaults
+type ErrorClasser interface {
+	ErrorClass() string
+}
+
+// ClassifyError returns the class of the error for WithErrorClass, empty for nil error. The first error of the chain
+// implementing ErrorClasser decides, otherwise:
+//   - deadlines, timeouts and network errors are transient
+//   - cancelled contexts, invalid JSON, numbers and URLs, missing files are client ones
+//   - the rest are internal
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var classer ErrorClasser
+	if errors.As(err, &classer) {
+		if class := classer.ErrorClass(); class != "" {
+			return class
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorClassClient
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTransient
+	}
+
+	// Not every net.Error is a network one, e.g. *os.PathError, so only timeouts are taken from it
+	var timeoutErr net.Error
+	if errors.As(err, &timeoutErr) && timeoutErr.Timeout() {
+		return ErrorClassTransient
+	}
+	var opErr *net.OpError
+	var dnsErr *net.DNSError
+	var urlErr *url.Error
+	if errors.As(err, &opErr) || errors.As(err, &dnsErr) {
+		return ErrorClassTransient
+	}
+	// Errors of http.Client, except parsing of the URL
+	if errors.As(err, &urlErr) {
+		if urlErr.Op == "parse" {
+			return ErrorClassClient
+		}
+		return ErrorClassTransient
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var numErr *strconv.NumError
+	var escapeErr url.EscapeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &numErr) ||
+		errors.As(err, &escapeErr) || errors.Is(err, os.ErrNotExist) {
+		return ErrorClassClient
+	}
+	return ErrorClassInternal
+}
+
+func (l loggerImpl) WithErrorClass(class string) Logger {
+	if class == "" {
+		return l
+	}
+
+	return l.With(Fields{ErrorClassKey: class})
+}
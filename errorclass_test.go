@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_WithErrorClass(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	transient := logger.WithErrorClass(ErrorClassTransient)
+	transient.Error("timed out")
+	transient.WithErrorClass("").Error("empty ignored")
+	transient.WithErrorClass(ErrorClassClient).Warn("overridden")
+	logger.WithErrorClass(ClassifyError(&json.SyntaxError{})).Warn("classified")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 4)
+	assert.Equal(t, "transient", entries[0][ErrorClassKey])
+	assert.Equal(t, "transient", entries[1][ErrorClassKey])
+	assert.Equal(t, "client", entries[2][ErrorClassKey])
+	assert.Equal(t, "client", entries[3][ErrorClassKey])
+
+	value, ok := transient.GetField(ErrorClassKey)
+	require.True(t, ok)
+	assert.Equal(t, ErrorClassTransient, value)
+}
+
+type classedError string
+
+func (e classedError) Error() string      { return string(e) }
+func (e classedError) ErrorClass() string { return "quota" }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyError(t *testing.T) {
+	_, numErr := strconv.Atoi("x")
+	_, parseErr := url.Parse("http://[::1")
+	_, openErr := os.Open("/nonexistent/file")
+	_, jsonErr := json.Marshal(make(chan int))
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{classedError("over quota"), "quota"},
+		{fmt.Errorf("charging: %w", classedError("over quota")), "quota"},
+		{context.DeadlineExceeded, ErrorClassTransient},
+		{fmt.Errorf("query: %w", context.Canceled), ErrorClassClient},
+		{timeoutError{}, ErrorClassTransient},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorClassTransient},
+		{&url.Error{Op: "Get", URL: "http://example.com", Err: timeoutError{}}, ErrorClassTransient},
+		{parseErr, ErrorClassClient},
+		{numErr, ErrorClassClient},
+		{json.Unmarshal([]byte("{"), &struct{}{}), ErrorClassClient},
+		{json.Unmarshal([]byte(`"a"`), new(int)), ErrorClassClient},
+		{openErr, ErrorClassClient},
+		{jsonErr, ErrorClassInternal},
+		{errors.New("nil map"), ErrorClassInternal},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ClassifyError(tt.err), "%v", tt.err)
+	}
+}
+
+func TestClassifyError_Deadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	assert.Equal(t, ErrorClassTransient, ClassifyError(ctx.Err()))
+}
@@ -0,0 +1,40 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+func (l loggerImpl) Event(fields Fields) {
+	if l.disabled(zapcore.InfoLevel) {
+		return
+	}
+
+	l.skipCaller(1).With(fields).Info()
+}
+
+// omitMessageCore writes entries with empty message through a core encoding no message key
+type omitMessageCore struct {
+	zapcore.Core
+
+	noMessage zapcore.Core
+}
+
+func newOmitMessageCore(core, noMessage zapcore.Core) zapcore.Core {
+	return &omitMessageCore{Core: core, noMessage: noMessage}
+}
+
+func (c *omitMessageCore) With(fields []zapcore.Field) zapcore.Core {
+	return &omitMessageCore{Core: c.Core.With(fields), noMessage: c.noMessage.With(fields)}
+}
+
+func (c *omitMessageCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *omitMessageCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Message == "" {
+		return c.noMessage.Write(ent, fields)
+	}
+	return c.Core.Write(ent, fields)
+}
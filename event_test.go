@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_Event(t *testing.T) {
+	for _, sortKeys := range []bool{false, true} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{
+			Service:          "testing",
+			Namespace:        "events",
+			Level:            "info",
+			SortKeys:         sortKeys,
+			OmitEmptyMessage: true,
+			Caller:           true,
+		}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.With(Fields{"source": "checkout"}).Event(Fields{"event": "order_paid", "amount": 42})
+		logger.Info("")
+		logger.Info("with message")
+
+		entries := buf.entries(t)
+		require.Len(t, entries, 3)
+		assert.NotContains(t, entries[0], "message")
+		assert.Equal(t, "order_paid", entries[0]["event"])
+		assert.Equal(t, float64(42), entries[0]["amount"])
+		assert.Equal(t, "checkout", entries[0]["source"])
+		assert.Equal(t, "info", entries[0]["level"])
+		assert.Equal(t, "events", entries[0]["namespace"])
+		assert.Contains(t, entries[0], "@timestamp")
+		assert.Contains(t, entries[0]["caller"], "/event_test.go:")
+
+		assert.NotContains(t, entries[1], "message")
+		assert.Equal(t, "with message", entries[2]["message"])
+	}
+}
+
+func TestLoggerImpl_EventMessageKey(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "warn"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Event(Fields{"event": "disabled"})
+	logger.Warn("")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "", entries[0]["message"])
+}
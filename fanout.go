@@ -0,0 +1,194 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// What FanOutWriter does when a sink's queue is full
+type FanOutPolicy string
+
+const (
+	// Write waits for the slow sink, so entries are never dropped but logging calls may be delayed
+	FanOutBlock FanOutPolicy = "block"
+	// Write drops the entry for the slow sink and reports ErrFanOutQueueFull, other sinks still get it
+	FanOutDrop FanOutPolicy = "drop"
+)
+
+// Entries buffered per sink by default
+const DefaultFanOutQueueSize = 1024
+
+// Returned by FanOutWriter.Write with FanOutDrop policy for entries not queued to some of the sinks
+var ErrFanOutQueueFull = errors.New("fan-out queue is full, entry dropped")
+
+type FanOutConfig struct {
+	// Entries buffered per sink, DefaultFanOutQueueSize if zero
+	QueueSize int
+
+	// FanOutBlock if empty
+	Policy FanOutPolicy
+}
+
+// FanOutWriter writes every entry to all sinks concurrently. Each sink has a bounded queue and its own worker,
+// so a slow sink doesn't delay others and entries of a sink keep their order.
+// Write errors of sinks are aggregated and returned by the next Sync.
+type FanOutWriter struct {
+	// First for 64-bit alignment of atomic operations
+	dropped uint64
+
+	sinks  []*fanOutSink
+	policy FanOutPolicy
+
+	// Write lock is taken by Close, so queues aren't closed while written to
+	mu     sync.RWMutex
+	closed bool
+}
+
+type fanOutSink struct {
+	ws    zapcore.WriteSyncer
+	queue chan fanOutJob
+	done  chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// fanOutJob is either an entry to write or a marker closed once all previous entries are written
+type fanOutJob struct {
+	p       []byte
+	written chan struct{}
+}
+
+func NewFanOutWriter(config FanOutConfig, sinks ...zapcore.WriteSyncer) *FanOutWriter {
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultFanOutQueueSize
+	}
+	if config.Policy == "" {
+		config.Policy = FanOutBlock
+	}
+
+	w := &FanOutWriter{policy: config.Policy}
+	for _, ws := range sinks {
+		s := &fanOutSink{
+			ws:    ws,
+			queue: make(chan fanOutJob, config.QueueSize),
+			done:  make(chan struct{}),
+		}
+		go s.run()
+		w.sinks = append(w.sinks, s)
+	}
+	return w
+}
+
+func (s *fanOutSink) run() {
+	defer close(s.done)
+
+	for job := range s.queue {
+		if job.written != nil {
+			close(job.written)
+			continue
+		}
+		if _, err := s.ws.Write(job.p); err != nil {
+			s.mu.Lock()
+			s.err = multierr.Append(s.err, err)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// takeErr returns write errors since the previous call
+func (s *fanOutSink) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Returned by FanOutWriter methods called after Close
+var errFanOutClosed = errors.New("fan-out writer is closed")
+
+// Write queues a copy of p to every sink
+func (w *FanOutWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errFanOutClosed
+	}
+
+	var err error
+	for _, s := range w.sinks {
+		// Zap reuses the buffer once Write returns
+		job := fanOutJob{p: append([]byte(nil), p...)}
+
+		if w.policy != FanOutDrop {
+			s.queue <- job
+			continue
+		}
+
+		select {
+		case s.queue <- job:
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+			err = ErrFanOutQueueFull
+		}
+	}
+	return len(p), err
+}
+
+// Sync waits until entries queued before the call are written, then syncs all sinks.
+// Returns errors of writes since the previous Sync.
+func (w *FanOutWriter) Sync() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return nil
+	}
+
+	var err error
+	for _, s := range w.sinks {
+		written := make(chan struct{})
+		s.queue <- fanOutJob{written: written}
+		<-written
+	}
+	for _, s := range w.sinks {
+		err = multierr.Append(err, s.takeErr())
+		err = multierr.Append(err, s.ws.Sync())
+	}
+	return err
+}
+
+// Dropped returns number of entries dropped by FanOutDrop policy, counted once per sink
+func (w *FanOutWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close writes queued entries and stops workers. Sinks implementing io.Closer are closed too.
+// Calling it more than once is no-op.
+func (w *FanOutWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	var err error
+	for _, s := range w.sinks {
+		close(s.queue)
+	}
+	for _, s := range w.sinks {
+		<-s.done
+		err = multierr.Append(err, s.takeErr())
+		if c, ok := s.ws.(io.Closer); ok {
+			err = multierr.Append(err, c.Close())
+		}
+	}
+	return err
+}
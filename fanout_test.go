@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"errors"
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// mockSink records written entries, optionally failing or blocking writes
+type mockSink struct {
+	mu      sync.Mutex
+	entries []string
+	closed  bool
+
+	err     error
+	release chan struct{}
+}
+
+func (s *mockSink) Write(p []byte) (int, error) {
+	if s.release != nil {
+		<-s.release
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, string(p))
+	return len(p), s.err
+}
+
+func (s *mockSink) Sync() error {
+	return nil
+}
+
+func (s *mockSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *mockSink) written() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.entries...)
+}
+
+func TestFanOutWriter(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	first, second := &mockSink{}, &mockSink{}
+	w := NewFanOutWriter(FanOutConfig{}, first, second)
+
+	buf := []byte("first\n")
+	_, err := w.Write(buf)
+	require.NoError(t, err)
+	// Writer keeps a copy, zap reuses the buffer
+	copy(buf, "xxxxx\n")
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, w.Sync())
+	assert.Equal(t, []string{"first\n", "second\n"}, first.written())
+	assert.Equal(t, []string{"first\n", "second\n"}, second.written())
+
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	assert.True(t, first.closed)
+	assert.True(t, second.closed)
+
+	_, err = w.Write([]byte("after close\n"))
+	assert.Error(t, err)
+	assertNoGoroutineLeak(t, goroutines)
+}
+
+func TestFanOutWriter_Errors(t *testing.T) {
+	failing := &mockSink{err: errors.New("connection reset")}
+	healthy := &mockSink{}
+	w := NewFanOutWriter(FanOutConfig{}, failing, healthy)
+	defer w.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := w.Write([]byte("entry\n"))
+		require.NoError(t, err)
+	}
+
+	err := w.Sync()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+	assert.Len(t, healthy.written(), 2)
+
+	// Errors are reported once
+	assert.NoError(t, w.Sync())
+}
+
+func TestFanOutWriter_Drop(t *testing.T) {
+	slow := &mockSink{release: make(chan struct{})}
+	w := NewFanOutWriter(FanOutConfig{QueueSize: 1, Policy: FanOutDrop}, slow)
+
+	var dropped int
+	for i := 0; i < 10; i++ {
+		if _, err := w.Write([]byte("entry\n")); err != nil {
+			assert.True(t, errors.Is(err, ErrFanOutQueueFull))
+			dropped++
+		}
+	}
+
+	// The slow sink holds at most one entry in the worker and one in the queue
+	assert.GreaterOrEqual(t, dropped, 8)
+	assert.Equal(t, uint64(dropped), w.Dropped())
+
+	close(slow.release)
+	require.NoError(t, w.Sync())
+	assert.Len(t, slow.written(), 10-dropped)
+	require.NoError(t, w.Close())
+}
+
+func TestFanOutWriter_Concurrent(t *testing.T) {
+	slow := &mockSink{release: make(chan struct{})}
+	fast := &mockSink{}
+	w := NewFanOutWriter(FanOutConfig{QueueSize: 10}, slow, fast)
+
+	for i := 0; i < 5; i++ {
+		_, err := w.Write([]byte("entry\n"))
+		require.NoError(t, err)
+	}
+
+	// The slow sink doesn't delay others
+	assert.Eventually(t, func() bool { return len(fast.written()) == 5 }, time.Second, time.Millisecond)
+	assert.Empty(t, slow.written())
+
+	close(slow.release)
+	require.NoError(t, w.Close())
+	assert.Len(t, slow.written(), 5)
+}
+
+func TestFanOut_InvalidPolicy(t *testing.T) {
+	_, err := newLogger(LoggingConfig{Service: "testing", FanOutPolicy: "retry"}, zapcore.AddSync(ioutil.Discard))
+	assert.Error(t, err)
+}
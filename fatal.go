@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// What zap does after writing fatal entries, replaced by tests to keep the process running
+var fatalAction = zapcore.WriteThenFatal
+
+// fatalHookCore runs the hook after fatal entries are written to all outputs and before zap exits.
+// It adds itself to checked fatal entries after other cores, writing nothing.
+type fatalHookCore struct {
+	zapcore.Core
+
+	hook func()
+
+	// Shared by derived cores, set once the hook started
+	called *int32
+}
+
+func newFatalHookCore(core zapcore.Core, hook func()) zapcore.Core {
+	return &fatalHookCore{Core: core, hook: hook, called: new(int32)}
+}
+
+func (c *fatalHookCore) With(fields []zapcore.Field) zapcore.Core {
+	return &fatalHookCore{Core: c.Core.With(fields), hook: c.hook, called: c.called}
+}
+
+func (c *fatalHookCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if ent.Level == zapcore.FatalLevel {
+		ce = ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *fatalHookCore) Write(ent zapcore.Entry, _ []zapcore.Field) error {
+	// Fatal entries logged by the hook itself exit right away instead of calling it again
+	if ent.Level == zapcore.FatalLevel && atomic.CompareAndSwapInt32(c.called, 0, 1) {
+		c.hook()
+	}
+	return nil
+}
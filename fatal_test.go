@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// runFatal calls fn in a goroutine exiting instead of the process on fatal entries
+func runFatal(t *testing.T, fn func()) {
+	t.Helper()
+
+	fatalAction = zapcore.WriteThenGoexit
+	defer func() { fatalAction = zapcore.WriteThenFatal }()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
+
+func TestOnFatal(t *testing.T) {
+	buf := &lockedBuffer{}
+	var entriesAtHook int
+	var calls int
+
+	var logger Logger
+	runFatal(t, func() {
+		var err error
+		logger, err = newLogger(LoggingConfig{
+			Service: "testing",
+			Level:   "info",
+			OnFatal: func() {
+				calls++
+				entriesAtHook = len(buf.entries(t))
+
+				// Must not call the hook again
+				logger.Fatal("fatal in hook")
+			},
+		}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.With(Fields{"a": "b"}).Fatal("no config")
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, entriesAtHook, "entry is written before the hook")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "no config", entries[0]["message"])
+	assert.Equal(t, "fatal in hook", entries[1]["message"])
+
+	// Hook runs once per New call
+	runFatal(t, func() {
+		logger.Fatalf("again %d", 2)
+	})
+	assert.Equal(t, 1, calls)
+}
+
+func TestOnFatal_OtherLevels(t *testing.T) {
+	buf := &lockedBuffer{}
+	calls := 0
+	logger, err := newLogger(LoggingConfig{
+		Service: "testing",
+		Level:   "debug",
+		OnFatal: func() { calls++ },
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Error("error")
+	assert.Panics(t, func() { logger.Panic("panic") })
+	assert.Equal(t, 0, calls)
+	assert.Len(t, buf.entries(t), 2)
+}
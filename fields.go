@@ -1,6 +1,12 @@
 package logger
 
-import "sync"
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
 
 type Fields map[string]interface{}
 
@@ -21,6 +27,28 @@ func (f Fields) Merge(newValues Fields) Fields {
 	return copied
 }
 
+// Returns value of the field, typed fields like Bool are unwrapped into plain values like GetField does
+func (f Fields) Get(key string) (interface{}, bool) {
+	value, ok := f[key]
+	if !ok {
+		return nil, false
+	}
+	return fieldValue(value), true
+}
+
+// Reports whether the field is set, even to nil
+func (f Fields) Has(key string) bool {
+	_, ok := f[key]
+	return ok
+}
+
+// Returns a copy without the field, the receiver isn't modified
+func (f Fields) Delete(key string) Fields {
+	copied := f.Copy()
+	delete(copied, key)
+	return copied
+}
+
 //nolint
 var ignore = map[string]struct{}{
 	"@timestamp": {},
@@ -29,27 +57,94 @@ var ignore = map[string]struct{}{
 	"service":    {},
 }
 
-// Flattens map to loosely coupled k-v pairs to pass into .With
+// Flattens map to loosely coupled k-v pairs to pass into .With.
+// Zap fields, e.g. created by Bool and Time, are passed as is instead of pairs, as well as fields of values
+// which would fail to be encoded, e.g. channels or funcs, replaced by placeholders.
+// Keys written by loggers themselves, e.g. service, are dropped like FieldCollisionIgnore does.
+// Keys are in map order unless SetDeterministicFlatten is enabled.
+// Every call returns a new slice, loggers flatten their fields once when they change.
 func (f Fields) Flatten() []interface{} {
-	list := flattenPool.Get().([]interface{})
-
-	for k, v := range f {
-		if _, ok := ignore[k]; ok {
-			continue
+	list := make([]interface{}, 0, 2*len(f))
+	f.each(func(k string, v interface{}) {
+		if field, ok := typedField(k, v); ok {
+			list = append(list, field)
+			return
+		}
+		if field, ok := sanitizedField(k, v); ok {
+			list = append(list, field)
+			return
 		}
 		list = append(list, k, v)
+	})
+	return list
+}
+
+// zapFields converts fields to zap fields like Flatten does, so sugared logger doesn't convert pairs on every entry.
+// Keys colliding with ones of the logger are resolved by the policy, the first of them is returned.
+func (f Fields) zapFields(policy FieldCollisionPolicy) ([]zap.Field, string) {
+	list := make([]zap.Field, 0, len(f))
+	collision := f.eachResolved(policy, func(k string, v interface{}) {
+		list = append(list, zapField(k, v))
+	})
+	return list, collision
+}
+
+// each calls fn for fields which aren't ignored, in the order of Flatten
+func (f Fields) each(fn func(k string, v interface{})) {
+	f.eachResolved(FieldCollisionIgnore, fn)
+}
+
+// eachKey calls fn for all fields, sorted if SetDeterministicFlatten is enabled
+func (f Fields) eachKey(fn func(k string, v interface{})) {
+	if atomic.LoadInt32(&deterministicFlatten) == 1 {
+		keys := make([]string, 0, len(f))
+		for k := range f {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			fn(k, f[k])
+		}
+		return
 	}
 
-	return list
+	for k, v := range f {
+		fn(k, v)
+	}
 }
 
-var flattenPool = sync.Pool{
-	New: func() interface{} {
-		return []interface{}{}
-	},
+// zapField converts a value the same way zap.Any does, common types skip its type switch.
+// Values which would fail to be encoded are sanitized, see sanitizedField.
+func zapField(k string, v interface{}) zap.Field {
+	switch value := v.(type) {
+	case string:
+		return zap.String(k, value)
+	case int:
+		return zap.Int(k, value)
+	case int64:
+		return zap.Int64(k, value)
+	case bool:
+		return zap.Bool(k, value)
+	case time.Duration:
+		return zap.Duration(k, value)
+	}
+
+	if field, ok := typedField(k, v); ok {
+		return field
+	}
+	field, _ := sanitizedField(k, v)
+	return field
 }
 
-func putFlatten(flatten []interface{}) {
-	//nolint
-	flattenPool.Put(flatten[:0])
+var deterministicFlatten int32
+
+// SetDeterministicFlatten makes Flatten return keys in sorted order, e.g. for golden tests against exact output.
+// It is a test hook affecting the whole process and costs a sort per call, keep it off in production.
+func SetDeterministicFlatten(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&deterministicFlatten, v)
 }
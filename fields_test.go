@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFields_Get(t *testing.T) {
+	fields := Fields{"user": "alice", "empty": nil}.Merge(Bool("cached", true))
+
+	value, ok := fields.Get("user")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", value)
+
+	// Typed fields are unwrapped
+	value, ok = fields.Get("cached")
+	assert.True(t, ok)
+	assert.Equal(t, true, value)
+
+	value, ok = fields.Get("empty")
+	assert.True(t, ok)
+	assert.Nil(t, value)
+
+	_, ok = fields.Get("absent")
+	assert.False(t, ok)
+
+	_, ok = Fields(nil).Get("user")
+	assert.False(t, ok)
+}
+
+func TestFields_Has(t *testing.T) {
+	fields := Fields{"user": "alice", "empty": nil}
+
+	assert.True(t, fields.Has("user"))
+	assert.True(t, fields.Has("empty"))
+	assert.False(t, fields.Has("absent"))
+	assert.False(t, Fields(nil).Has("user"))
+}
+
+func TestFields_Delete(t *testing.T) {
+	fields := Fields{"user": "alice", "token": "secret"}
+
+	deleted := fields.Delete("token")
+	assert.Equal(t, Fields{"user": "alice"}, deleted)
+	assert.Equal(t, Fields{"user": "alice", "token": "secret"}, fields, "receiver isn't modified")
+
+	assert.Equal(t, fields, fields.Delete("absent"))
+	assert.Equal(t, Fields{}, Fields(nil).Delete("user"))
+}
@@ -0,0 +1,64 @@
+package logger
+
+import "flag"
+
+// Prefix of flags registered by RegisterFlags
+const FlagPrefix = "log."
+
+// Registers logging flags on the flag set, e.g. -log.level and -log.format, with defaults from DefaultConfig.
+// Returned config is populated once fs.Parse is called.
+func RegisterFlags(fs *flag.FlagSet) *LoggingConfig {
+	config := DefaultConfig
+	RegisterFlagsVar(fs, &config)
+	return &config
+}
+
+// Same as RegisterFlags, but binds flags to the config, current values are used as defaults
+func RegisterFlagsVar(fs *flag.FlagSet, config *LoggingConfig) {
+	fs.StringVar(&config.Service, FlagPrefix+"service", config.Service, "service name")
+	fs.StringVar(&config.Level, FlagPrefix+"level", config.Level, "minimum log level: debug, info, warn, error, panic or fatal")
+	fs.StringVar(&config.Namespace, FlagPrefix+"namespace", config.Namespace, "default namespace")
+	fs.StringVar(&config.Region, FlagPrefix+"region", config.Region, "region tag, AWS_REGION if empty")
+	fs.StringVar(&config.AvailabilityZone, FlagPrefix+"availability-zone", config.AvailabilityZone, "availability zone tag")
+	fs.StringVar(&config.Cluster, FlagPrefix+"cluster", config.Cluster, "cluster tag")
+
+	fs.BoolVar(&config.AnnounceConfig, FlagPrefix+"announce-config", config.AnnounceConfig, "log the effective config once the logger is built")
+	fs.BoolVar(&config.DisableStdout, FlagPrefix+"disable-stdout", config.DisableStdout, "disable stdout output")
+	fs.BoolVar(&config.AllowNoOutputs, FlagPrefix+"allow-no-outputs", config.AllowNoOutputs, "only warn if no output is enabled")
+	fs.StringVar(&config.FormatStdout, FlagPrefix+"format", config.FormatStdout, "stdout format: json, pretty, gcp or a registered encoder")
+
+	fs.StringVar(&config.LogstashURI, FlagPrefix+"logstash-uri", config.LogstashURI, "logstash address, not used if empty")
+	fs.StringVar(&config.LogstashProtocol, FlagPrefix+"logstash-protocol", config.LogstashProtocol, "logstash protocol: udp or tcp")
+	fs.BoolVar(&config.LogstashTLS, FlagPrefix+"logstash-tls", config.LogstashTLS, "dial logstash with TLS")
+	fs.StringVar(&config.LogstashTLSCA, FlagPrefix+"logstash-tls-ca", config.LogstashTLSCA, "PEM file of logstash CA, system roots if empty")
+	fs.StringVar(&config.NATSURL, FlagPrefix+"nats-url", config.NATSURL, "NATS server URL, requires natsadapter")
+	fs.StringVar(&config.NATSSubject, FlagPrefix+"nats-subject", config.NATSSubject, "NATS subject entries are published to")
+	fs.BoolVar(&config.NATSJetStream, FlagPrefix+"nats-jetstream", config.NATSJetStream, "publish NATS entries to JetStream")
+	fs.StringVar(&config.AuditFile, FlagPrefix+"audit-file", config.AuditFile, "file audit entries are appended to")
+	fs.StringVar(&config.AuditURI, FlagPrefix+"audit-uri", config.AuditURI, "tcp address audit entries are sent to")
+	fs.StringVar(&config.DebugFilePath, FlagPrefix+"debug-file", config.DebugFilePath, "file entries of all levels are appended to")
+	fs.BoolVar(&config.Journald, FlagPrefix+"journald", config.Journald, "write entries to systemd journal if available")
+
+	fs.StringVar(&config.ConsoleSeparator, FlagPrefix+"console-separator", config.ConsoleSeparator, "separator of pretty format elements, tab if empty")
+	fs.BoolVar(&config.Color, FlagPrefix+"color", config.Color, "color levels of pretty format")
+	fs.BoolVar(&config.SortKeys, FlagPrefix+"sort-keys", config.SortKeys, "emit JSON keys in a deterministic order")
+	fs.BoolVar(&config.Sequence, FlagPrefix+"sequence", config.Sequence, "add seq field increasing with every entry")
+	fs.BoolVar(&config.EntryID, FlagPrefix+"entry-id", config.EntryID, "add entry_id field unique for every entry")
+	fs.BoolVar(&config.DedupConsecutive, FlagPrefix+"dedup-consecutive", config.DedupConsecutive, "suppress entries identical to the previous one")
+	fs.BoolVar(&config.WarnOnEmptyMessage, FlagPrefix+"warn-on-empty-message", config.WarnOnEmptyMessage, "warn about the first entry with empty message")
+	fs.StringVar((*string)(&config.FieldCollision), FlagPrefix+"field-collision", string(config.FieldCollision), "policy of fields colliding with service and others: ignore, override or rename")
+	fs.StringVar((*string)(&config.FieldConflict), FlagPrefix+"field-conflict", string(config.FieldConflict), "which of fields with the same key is written: last, first or error")
+	fs.BoolVar(&config.DeepCopyFields, FlagPrefix+"deep-copy-fields", config.DeepCopyFields, "copy maps and slices passed to With")
+	fs.DurationVar(&config.FlushInterval, FlagPrefix+"flush-interval", config.FlushInterval, "sync outputs in background with the interval")
+
+	fs.BoolVar(&config.Caller, FlagPrefix+"caller", config.Caller, "add caller field")
+	fs.StringVar(&config.StacktraceLevel, FlagPrefix+"stacktrace-level", config.StacktraceLevel, "add stacktrace field to entries of the level and above")
+	fs.BoolVar(&config.StructuredStack, FlagPrefix+"structured-stack", config.StructuredStack, "log stacks of Trace as arrays of frames")
+	fs.BoolVar(&config.RecoverStructured, FlagPrefix+"recover-structured", config.RecoverStructured, "log recovered panics as a single entry with panic fields")
+	fs.BoolVar(&config.Sampling, FlagPrefix+"sampling", config.Sampling, "sample repeated entries")
+	fs.BoolVar(&config.DatadogCompat, FlagPrefix+"datadog-compat", config.DatadogCompat, "write level as Datadog status attribute")
+	fs.BoolVar(&config.ECSCompat, FlagPrefix+"ecs-compat", config.ECSCompat, "write Elastic Common Schema keys")
+	fs.BoolVar(&config.Interactive, FlagPrefix+"interactive", config.Interactive, "write and sync every entry on its own line")
+	fs.BoolVar(&config.AsyncStdout, FlagPrefix+"async-stdout", config.AsyncStdout, "buffer stdout entries, may lose them on crash")
+	fs.BoolVar(&config.OmitEmptyMessage, FlagPrefix+"omit-empty-message", config.OmitEmptyMessage, "omit message key of entries with empty message")
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config := RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{
+		"-log.service", "cli",
+		"-log.level=warn",
+		"-log.format", "pretty",
+		"-log.sort-keys",
+		"-log.flush-interval", "5s",
+		"arg",
+	}))
+
+	assert.Equal(t, "cli", config.Service)
+	assert.Equal(t, "warn", config.Level)
+	assert.Equal(t, FormatPretty, config.FormatStdout)
+	assert.True(t, config.SortKeys)
+	assert.Equal(t, 5*time.Second, config.FlushInterval)
+	assert.Equal(t, []string{"arg"}, fs.Args())
+
+	// Not passed flags keep defaults
+	assert.Equal(t, DefaultConfig.Namespace, config.Namespace)
+	assert.Equal(t, DefaultConfig.LogstashProtocol, config.LogstashProtocol)
+	assert.False(t, config.Sequence)
+
+	l, err := New(*config)
+	require.NoError(t, err)
+	assert.False(t, l.Enabled("info"))
+	assert.True(t, l.Enabled("warn"))
+}
+
+func TestRegisterFlags_Invalid(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&lockedBuffer{})
+	config := RegisterFlags(fs)
+
+	assert.Error(t, fs.Parse([]string{"-log.flush-interval", "soon"}))
+
+	require.NoError(t, fs.Parse([]string{"-log.level", "loud"}))
+	_, err := New(*config)
+	assert.Error(t, err)
+}
+
+func TestRegisterFlagsVar(t *testing.T) {
+	config := DefaultConfig
+	config.Service = "preset"
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlagsVar(fs, &config)
+	require.NoError(t, fs.Parse([]string{"-log.level=debug"}))
+
+	assert.Equal(t, "debug", config.Level)
+	// Current values are defaults of not passed flags
+	assert.Equal(t, "preset", config.Service)
+	assert.Equal(t, "preset", fs.Lookup(FlagPrefix+"service").DefValue)
+}
@@ -0,0 +1,117 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// Returned by Reconfigure of closed loggers
+var errLoggerClosed = errors.New("logger is closed")
+
+// closer owns resources shared by all loggers derived from the same New call
+type closer struct {
+	base *zap.Logger
+
+	// Guards closers replaced by Reconfigure
+	mu      sync.Mutex
+	closers []io.Closer
+	closed  bool
+
+	// Closed after closers, kept by Reconfigure, e.g. the audit output
+	kept []io.Closer
+
+	once sync.Once
+	err  error
+
+	// Closed to stop background flushing
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newCloser(base *zap.Logger, flushInterval time.Duration, closers []io.Closer, kept ...io.Closer) *closer {
+	c := &closer{
+		base:    base,
+		closers: closers,
+		kept:    kept,
+	}
+
+	if flushInterval > 0 {
+		c.stop = make(chan struct{})
+		c.done = make(chan struct{})
+		go c.flush(flushInterval)
+	}
+
+	return c
+}
+
+func (c *closer) flush(interval time.Duration) {
+	defer close(c.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Nowhere to report errors, next Sync or Close reports them
+			_ = c.base.Sync()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *closer) Close() error {
+	c.once.Do(func() {
+		if c.stop != nil {
+			close(c.stop)
+			<-c.done
+		}
+
+		c.mu.Lock()
+		c.closed = true
+		closers := c.closers
+		c.mu.Unlock()
+
+		c.err = c.base.Sync()
+		for _, cl := range closers {
+			c.err = multierr.Append(c.err, cl.Close())
+		}
+		for _, cl := range c.kept {
+			c.err = multierr.Append(c.err, cl.Close())
+		}
+	})
+
+	return c.err
+}
+
+// replace calls swap, which makes outputs of the closers current, and returns closers of the replaced outputs.
+// Fails if the logger is closed, swap isn't called then.
+func (c *closer) replace(closers []io.Closer, swap func()) ([]io.Closer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, errLoggerClosed
+	}
+
+	swap()
+	old := c.closers
+	c.closers = closers
+	return old, nil
+}
+
+func (l loggerImpl) Sync() error {
+	return l.zapLogger().Sync()
+}
+
+func (l loggerImpl) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
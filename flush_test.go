@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"io/ioutil"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSink counts Sync calls
+type countingSink struct {
+	syncs int64
+}
+
+func (s *countingSink) Write(p []byte) (int, error) {
+	return ioutil.Discard.Write(p)
+}
+
+func (s *countingSink) Sync() error {
+	atomic.AddInt64(&s.syncs, 1)
+	return nil
+}
+
+func (s *countingSink) count() int64 {
+	return atomic.LoadInt64(&s.syncs)
+}
+
+func TestFlushInterval(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	sink := &countingSink{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", FlushInterval: time.Millisecond}, sink)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return sink.count() >= 3
+	}, time.Second, time.Millisecond)
+
+	// Closing derived logger stops the shared flusher
+	require.NoError(t, logger.With(Fields{"a": "b"}).Close())
+	stopped := sink.count()
+
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, sink.count())
+
+	assertNoGoroutineLeak(t, goroutines)
+}
+
+// assertNoGoroutineLeak waits for goroutines started by the test to exit
+func assertNoGoroutineLeak(t *testing.T, goroutines int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines, "goroutine leaked")
+}
+
+func TestClose_Idempotent(t *testing.T) {
+	sink := &countingSink{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", FlushInterval: time.Hour}, sink)
+	require.NoError(t, err)
+
+	require.NoError(t, logger.Close())
+	require.NoError(t, logger.Close())
+	require.NoError(t, logger.Namespace("derived").Close())
+
+	// Only the first Close syncs
+	assert.Equal(t, int64(1), sink.count())
+}
+
+func TestClose_NoFlushInterval(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	sink := &countingSink{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, sink)
+	require.NoError(t, err)
+
+	assert.Equal(t, goroutines, runtime.NumGoroutine())
+
+	require.NoError(t, logger.Sync())
+	require.NoError(t, logger.Close())
+	assert.Equal(t, int64(2), sink.count())
+}
@@ -0,0 +1,97 @@
+//go:build go1.18
+// +build go1.18
+
+package logger
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func FuzzParseLevel(f *testing.F) {
+	for _, seed := range []string{"", "debug", " INFO ", "Warn\n", "fatal", "dpanic", "verbose", "\x00"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		level, err := ParseLevel(input)
+		if err != nil {
+			if !strings.Contains(err.Error(), "must be debug, info, warn, error, panic or fatal") {
+				t.Errorf("error of %q doesn't list levels: %v", input, err)
+			}
+			return
+		}
+
+		// Accepted levels round-trip through their canonical names
+		parsed, err := ParseLevel(level.String())
+		if err != nil || parsed != level {
+			t.Errorf("level %q parsed as %v doesn't round-trip: %v, %v", input, level, parsed, err)
+		}
+	})
+}
+
+func FuzzParseFormat(f *testing.F) {
+	for _, seed := range []string{"", "json", " Pretty", "GCP", "yaml", "\x00"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		format, err := ParseFormat(input)
+		if err != nil {
+			if !strings.Contains(err.Error(), "registered encoder") {
+				t.Errorf("error of %q doesn't list formats: %v", input, err)
+			}
+			return
+		}
+
+		parsed, err := ParseFormat(format)
+		if err != nil || parsed != format {
+			t.Errorf("format %q parsed as %q doesn't round-trip: %q, %v", input, format, parsed, err)
+		}
+	})
+}
+
+// New never panics on values typed by humans, accepted configs log entries
+func FuzzNewConfig(f *testing.F) {
+	f.Add("info", "json", "testing", "default", "", "", "", "")
+	f.Add(" DEBUG", "Pretty", "", "", "error", "rename", "last", "tcp")
+	f.Add("loud", "yaml", "svc\n", "a/b", "verbose", "merge", "both", "tpc")
+
+	f.Fuzz(func(t *testing.T, level, format, service, namespace, stacktraceLevel, collision, conflict, protocol string) {
+		config := LoggingConfig{
+			Service:          service,
+			Namespace:        namespace,
+			Level:            level,
+			FormatStdout:     format,
+			StacktraceLevel:  stacktraceLevel,
+			FieldCollision:   FieldCollisionPolicy(collision),
+			FieldConflict:    FieldConflictPolicy(conflict),
+			LogstashProtocol: protocol,
+		}
+		if strings.TrimSpace(level) == "" {
+			// Warned about on every call otherwise
+			config.Level = "info"
+		}
+
+		logger, err := newLogger(config, zapcore.AddSync(ioutil.Discard))
+		if err != nil {
+			if err.Error() == "" {
+				t.Errorf("empty error of %+v", config)
+			}
+			return
+		}
+		defer logger.Close()
+
+		parsed, err := ParseLevel(config.Level)
+		if err != nil {
+			t.Fatalf("level %q is accepted by New, but not by ParseLevel: %v", config.Level, err)
+		}
+		if !logger.Enabled(parsed.String()) {
+			t.Errorf("level %v isn't enabled", parsed)
+		}
+		logger.With(Fields{"fuzz": true}).Error("fuzzed")
+	})
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"runtime"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Severities of Google Cloud Logging LogEntry
+var gcpSeverities = map[zapcore.Level]string{
+	zapcore.DebugLevel:  "DEBUG",
+	zapcore.InfoLevel:   "INFO",
+	zapcore.WarnLevel:   "WARNING",
+	zapcore.ErrorLevel:  "ERROR",
+	zapcore.DPanicLevel: "CRITICAL",
+	zapcore.PanicLevel:  "ALERT",
+	zapcore.FatalLevel:  "EMERGENCY",
+}
+
+// newGCPEncoderConfig uses keys recognized by Cloud Logging agents in structured JSON payloads.
+// See https://cloud.google.com/logging/docs/structured-logging
+func newGCPEncoderConfig() zapcore.EncoderConfig {
+	gcpEncoderConfig := newEncoderConfig()
+	gcpEncoderConfig.LevelKey = "severity"
+	gcpEncoderConfig.TimeKey = "time"
+	gcpEncoderConfig.MessageKey = "message"
+	gcpEncoderConfig.CallerKey = "logging.googleapis.com/sourceLocation"
+	gcpEncoderConfig.StacktraceKey = "stack_trace"
+	gcpEncoderConfig.EncodeLevel = encodeGCPSeverity
+	gcpEncoderConfig.EncodeCaller = encodeGCPSourceLocation
+	return gcpEncoderConfig
+}
+
+// gcpSourceLocation is LogEntrySourceLocation, Cloud Logging parses it only as an object
+type gcpSourceLocation zapcore.EntryCaller
+
+func (l gcpSourceLocation) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("file", l.File)
+	// int64 is a string in JSON of LogEntry
+	enc.AddString("line", strconv.Itoa(l.Line))
+	function := l.Function
+	if function == "" {
+		if fn := runtime.FuncForPC(l.PC); fn != nil {
+			function = fn.Name()
+		}
+	}
+	if function != "" {
+		enc.AddString("function", function)
+	}
+	return nil
+}
+
+// encodeGCPSourceLocation writes the caller as sourceLocation object, JSON encoders pass themselves as array ones
+func encodeGCPSourceLocation(caller zapcore.EntryCaller, enc zapcore.PrimitiveArrayEncoder) {
+	if arr, ok := enc.(zapcore.ArrayEncoder); ok {
+		_ = arr.AppendObject(gcpSourceLocation(caller))
+		return
+	}
+	enc.AppendString(caller.TrimmedPath())
+}
+
+func encodeGCPSeverity(level zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	severity, ok := gcpSeverities[level]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	enc.AppendString(severity)
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFormatGCP(t *testing.T) {
+	for _, sortKeys := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		logger, err := newLogger(LoggingConfig{
+			Service:      "testing",
+			Namespace:    "default",
+			Level:        "debug",
+			FormatStdout: FormatGCP,
+			SortKeys:     sortKeys,
+		}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error")
+
+		scanner := bufio.NewScanner(buf)
+		var severities, messages []string
+		for scanner.Scan() {
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+
+			assert.NotContains(t, entry, "level")
+			assert.NotContains(t, entry, "@timestamp")
+			assert.Equal(t, "testing", entry["service"])
+
+			_, err := time.Parse(time.RFC3339Nano, entry["time"].(string))
+			assert.NoError(t, err)
+
+			severities = append(severities, entry["severity"].(string))
+			messages = append(messages, entry["message"].(string))
+		}
+		assert.Equal(t, []string{"DEBUG", "INFO", "WARNING", "ERROR"}, severities)
+		assert.Equal(t, []string{"debug", "info", "warn", "error"}, messages)
+	}
+}
+
+func TestFormatGCP_SourceLocation(t *testing.T) {
+	for _, sortKeys := range []bool{false, true} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{
+			Service:      "testing",
+			Level:        "info",
+			FormatStdout: FormatGCP,
+			Caller:       true,
+			SortKeys:     sortKeys,
+		}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.Info("located")
+
+		entries := buf.entries(t)
+		require.Len(t, entries, 1)
+		location, ok := entries[0]["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+		require.True(t, ok, entries[0])
+		assert.Contains(t, location["file"], "gcp_test.go")
+		assert.NotEmpty(t, location["line"])
+		assert.Contains(t, location["function"], "TestFormatGCP_SourceLocation")
+	}
+}
+
+func TestEncodeGCPSeverity(t *testing.T) {
+	names := encodeLevelNames(encodeGCPSeverity)
+
+	assert.Equal(t, "CRITICAL", names[zapcore.DPanicLevel])
+	assert.Equal(t, "ALERT", names[zapcore.PanicLevel])
+	assert.Equal(t, "EMERGENCY", names[zapcore.FatalLevel])
+}
@@ -0,0 +1,106 @@
+// Package ginadapter logs gin requests with the same fields as logger.HTTPMiddleware and echoadapter
+package ginadapter
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/w84thesun/logger"
+)
+
+// Key of the request-scoped logger in gin.Context, see FromContext
+const ContextKey = "logger"
+
+// Key of errors attached to gin.Context by handlers
+const ErrorKey = "error"
+
+type options struct {
+	skipPaths map[string]struct{}
+}
+
+// Configures Middleware
+type Option func(*options)
+
+// Disables access entries for the paths, e.g. health checks. Handlers still get request-scoped logger.
+func WithSkipPaths(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			o.skipPaths[path] = struct{}{}
+		}
+	}
+}
+
+// Middleware stores request-scoped logger in gin.Context and the request context,
+// logs an access entry per request and turns handler panics into 500 responses
+func Middleware(l logger.Logger, opts ...Option) gin.HandlerFunc {
+	o := options{skipPaths: map[string]struct{}{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		r := c.Request
+
+		requestID := r.Header.Get(logger.RequestIDHeader)
+		if requestID == "" {
+			requestID = logger.NewRequestID()
+		}
+		c.Header(logger.RequestIDHeader, requestID)
+
+		requestLogger := l.With(logger.Fields{
+			logger.RequestIDKey: requestID,
+			logger.MethodKey:    r.Method,
+			logger.PathKey:      r.URL.Path,
+			logger.RouteKey:     c.FullPath(),
+			logger.ClientIPKey:  c.ClientIP(),
+		})
+		c.Set(ContextKey, requestLogger)
+		c.Request = r.WithContext(logger.NewContext(r.Context(), requestLogger))
+
+		defer func() {
+			status := c.Writer.Status()
+
+			if i := recover(); i != nil {
+				logger.TraceHandlerPanic(requestLogger, i)
+
+				if !c.Writer.Written() {
+					c.AbortWithStatus(http.StatusInternalServerError)
+				}
+				// Too late to change the response if written, make the access entry reflect the failure
+				status = http.StatusInternalServerError
+			}
+
+			if _, ok := o.skipPaths[r.URL.Path]; ok {
+				return
+			}
+
+			accessLogger := requestLogger
+			if len(c.Errors) > 0 {
+				accessLogger = accessLogger.With(logger.Fields{ErrorKey: strings.Join(c.Errors.Errors(), "; ")})
+			}
+
+			// Size is -1 until the body is written
+			size := c.Writer.Size()
+			if size < 0 {
+				size = 0
+			}
+			logger.LogAccess(accessLogger, status, size, time.Since(start))
+		}()
+
+		c.Next()
+	}
+}
+
+// Returns request-scoped logger stored by Middleware
+func FromContext(c *gin.Context) (logger.Logger, bool) {
+	v, ok := c.Get(ContextKey)
+	if !ok {
+		return nil, false
+	}
+	l, ok := v.(logger.Logger)
+	return l, ok
+}
@@ -5,5 +5,6 @@ go 1.14
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.6.1
+	go.uber.org/multierr v1.5.0
 	go.uber.org/zap v1.16.0
 )
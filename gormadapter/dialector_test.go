@@ -0,0 +1,47 @@
+package gormadapter
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// dryRunDialector is enough to build statements with gorm.Config.DryRun
+type dryRunDialector struct{}
+
+func (dryRunDialector) Name() string {
+	return "dryrun"
+}
+
+func (dryRunDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	return nil
+}
+
+func (dryRunDialector) Migrator(*gorm.DB) gorm.Migrator {
+	return nil
+}
+
+func (dryRunDialector) DataTypeOf(*schema.Field) string {
+	return ""
+}
+
+func (dryRunDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (dryRunDialector) BindVarTo(writer clause.Writer, _ *gorm.Statement, _ interface{}) {
+	_ = writer.WriteByte('?')
+}
+
+func (dryRunDialector) QuoteTo(writer clause.Writer, str string) {
+	_ = writer.WriteByte('`')
+	_, _ = writer.WriteString(str)
+	_ = writer.WriteByte('`')
+}
+
+func (dryRunDialector) Explain(sql string, vars ...interface{}) string {
+	return gormlogger.ExplainSQL(sql, nil, `"`, vars...)
+}
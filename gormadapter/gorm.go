@@ -0,0 +1,151 @@
+// Package gormadapter provides gorm logger.Interface on top of logger.Logger,
+// logging queries as structured entries.
+package gormadapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/w84thesun/logger"
+)
+
+// Fields attached to query entries
+const (
+	SQLKey          = "sql"
+	RowsAffectedKey = "rows_affected"
+	ElapsedKey      = "elapsed_ms"
+)
+
+type Config struct {
+	// Queries slower than the threshold are logged at warn level, zero disables it
+	SlowThreshold time.Duration
+
+	// Logs SQL with placeholders instead of parameter values, e.g. to avoid logging PII
+	ParameterizedQueries bool
+
+	// Doesn't log gorm.ErrRecordNotFound as an error
+	IgnoreRecordNotFoundError bool
+
+	// Which gorm messages are logged at all, gormlogger.Warn by default.
+	// With gormlogger.Info every query is logged at debug level.
+	LogLevel gormlogger.LogLevel
+}
+
+var DefaultConfig = Config{
+	SlowThreshold:             200 * time.Millisecond,
+	ParameterizedQueries:      false,
+	IgnoreRecordNotFoundError: false,
+	LogLevel:                  gormlogger.Warn,
+}
+
+type gormLogger struct {
+	base   logger.Logger
+	config Config
+}
+
+// Uses logger from the context if stored with logger.NewContext, l otherwise.
+func New(l logger.Logger, config Config) gormlogger.Interface {
+	if config.LogLevel == 0 {
+		config.LogLevel = DefaultConfig.LogLevel
+	}
+
+	return gormLogger{
+		base:   l.WithComponent("gorm"),
+		config: config,
+	}
+}
+
+func (g gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	g.config.LogLevel = level
+	return g
+}
+
+func (g gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if g.config.LogLevel >= gormlogger.Info {
+		logger.WithCallerOutside(g.logger(ctx), internalFunctions...).Infof(msg, data...)
+	}
+}
+
+func (g gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if g.config.LogLevel >= gormlogger.Warn {
+		logger.WithCallerOutside(g.logger(ctx), internalFunctions...).Warnf(msg, data...)
+	}
+}
+
+func (g gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if g.config.LogLevel >= gormlogger.Error {
+		logger.WithCallerOutside(g.logger(ctx), internalFunctions...).Errorf(msg, data...)
+	}
+}
+
+func (g gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if g.config.LogLevel <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := g.config.SlowThreshold != 0 && elapsed > g.config.SlowThreshold
+	failed := err != nil && !(g.config.IgnoreRecordNotFoundError && errors.Is(err, gorm.ErrRecordNotFound))
+
+	var level string
+	switch {
+	case failed && g.config.LogLevel >= gormlogger.Error:
+		level = "error"
+	case slow && g.config.LogLevel >= gormlogger.Warn:
+		level = "warn"
+	case g.config.LogLevel >= gormlogger.Info:
+		level = "debug"
+	default:
+		return
+	}
+
+	l := g.logger(ctx)
+	if !l.Enabled(level) {
+		return
+	}
+
+	l = logger.WithCallerOutside(l, internalFunctions...)
+	sql, rows := fc()
+	fields := logger.Fields{
+		SQLKey:          sql,
+		RowsAffectedKey: rows,
+		ElapsedKey:      float64(elapsed) / float64(time.Millisecond),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	l = l.With(fields)
+
+	switch level {
+	case "error":
+		l.Error("query failed")
+	case "warn":
+		l.Warnf("slow query, threshold %v", g.config.SlowThreshold)
+	default:
+		l.Debug("query")
+	}
+}
+
+// ParamsFilter is called by gorm to build logged SQL, drops parameter values for ParameterizedQueries
+func (g gormLogger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if g.config.ParameterizedQueries {
+		return sql, nil
+	}
+	return sql, params
+}
+
+// Prefixes of functions of gorm and the adapter, skipped when reporting the caller
+var internalFunctions = []string{"gorm.io/", "github.com/w84thesun/logger/gormadapter."}
+
+func (g gormLogger) logger(ctx context.Context) logger.Logger {
+	if ctx != nil {
+		if l, ok := logger.FromContext(ctx); ok {
+			return l.WithComponent("gorm")
+		}
+	}
+	return g.base
+}
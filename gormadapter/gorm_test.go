@@ -0,0 +1,149 @@
+package gormadapter
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils/tests"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func query(sql string) func() (string, int64) {
+	return func() (string, int64) { return sql, 3 }
+}
+
+func TestTrace(t *testing.T) {
+	l, entries := newTestLogger(t)
+	g := New(l, Config{SlowThreshold: time.Second, LogLevel: gormlogger.Info})
+
+	ctx := context.Background()
+	g.Trace(ctx, time.Now(), query("SELECT 1"), nil)
+	g.Trace(ctx, time.Now().Add(-2*time.Second), query("SELECT slow"), nil)
+	g.Trace(ctx, time.Now(), query("SELECT failed"), errors.New("boom"))
+
+	got := entries()
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "debug", got[0]["level"])
+	assert.Equal(t, "SELECT 1", got[0][SQLKey])
+	assert.Equal(t, float64(3), got[0][RowsAffectedKey])
+	assert.Contains(t, got[0], ElapsedKey)
+	assert.Equal(t, "gorm", got[0]["component"])
+
+	assert.Equal(t, "warn", got[1]["level"])
+	assert.Equal(t, "SELECT slow", got[1][SQLKey])
+	assert.GreaterOrEqual(t, got[1][ElapsedKey], float64(2000))
+
+	assert.Equal(t, "error", got[2]["level"])
+	assert.Equal(t, "boom", got[2]["error"])
+}
+
+func TestTrace_LogLevels(t *testing.T) {
+	l, entries := newTestLogger(t)
+	g := New(l, Config{SlowThreshold: time.Second, IgnoreRecordNotFoundError: true})
+
+	called := false
+	g.Trace(context.Background(), time.Now(), func() (string, int64) {
+		called = true
+		return "SELECT 1", 1
+	}, nil)
+	assert.False(t, called, "query shouldn't be built for not logged entries")
+
+	g.Trace(context.Background(), time.Now(), query("SELECT missing"), gorm.ErrRecordNotFound)
+	g.LogMode(gormlogger.Silent).Trace(context.Background(), time.Now(), query("SELECT silent"), errors.New("boom"))
+	g.Trace(context.Background(), time.Now(), query("SELECT failed"), errors.New("boom"))
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "SELECT failed", got[0][SQLKey])
+}
+
+func TestLogMode(t *testing.T) {
+	l, entries := newTestLogger(t)
+	g := New(l, DefaultConfig)
+
+	g.LogMode(gormlogger.Info).Info(context.Background(), "hello %v", "info")
+	g.Info(context.Background(), "parent is not affected")
+	g.Warn(context.Background(), "warn %v", 1)
+	g.LogMode(gormlogger.Error).Warn(context.Background(), "warn is disabled")
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "hello info", got[0]["message"])
+	assert.Equal(t, "warn 1", got[1]["message"])
+	assert.Equal(t, "warn", got[1]["level"])
+}
+
+func TestTrace_ContextLogger(t *testing.T) {
+	l, entries := newTestLogger(t)
+	g := New(l, Config{LogLevel: gormlogger.Info})
+
+	ctx := logger.NewContext(context.Background(), l.With(logger.Fields{"request_id": "abc"}))
+	g.Trace(ctx, time.Now(), query("SELECT 1"), nil)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "abc", got[0]["request_id"])
+	assert.Equal(t, "gorm", got[0]["component"])
+}
+
+type user struct {
+	ID   int
+	Name string
+}
+
+func TestParameterizedQueries(t *testing.T) {
+	for _, parameterized := range []bool{false, true} {
+		l, entries := newTestLogger(t)
+		db, err := gorm.Open(dryRunDialector{}, &gorm.Config{
+			DryRun: true,
+			Logger: New(l, Config{LogLevel: gormlogger.Info, ParameterizedQueries: parameterized}),
+		})
+		require.NoError(t, err)
+
+		db.Where("name = ?", "secret").Find(&[]user{})
+
+		got := entries()
+		require.Len(t, got, 1)
+		if parameterized {
+			assert.Equal(t, "SELECT * FROM `users` WHERE name = ?", got[0][SQLKey])
+		} else {
+			assert.Equal(t, "SELECT * FROM `users` WHERE name = \"secret\"", got[0][SQLKey])
+		}
+	}
+}
+
+func TestCaller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "debug", Caller: true})
+	db, err := gorm.Open(tests.DummyDialector{}, &gorm.Config{
+		DryRun: true,
+		Logger: New(l, Config{LogLevel: gormlogger.Info}),
+	})
+	require.NoError(t, err)
+
+	var users []map[string]interface{}
+	db.Table("users").Where("id = ?", 1).Find(&users)
+	_, _, line, _ := runtime.Caller(0)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "query", got[0]["message"])
+	// Reported at the call of Find, not in gorm callbacks or the adapter
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "gorm_test.go:"+strconv.Itoa(line-1)), got[0]["caller"])
+}
@@ -0,0 +1,130 @@
+package grpcadapter
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/w84thesun/logger"
+)
+
+// Field with grpc-go component, e.g. "core" or "transport"
+const ComponentKey = logger.ComponentKey
+
+// Component of entries logged by grpc-go without one
+const DefaultComponent = "grpc"
+
+type grpcLogger struct {
+	base      logger.Logger
+	verbosity int
+}
+
+// Routes grpc-go internal logs to the logger, calls with V(l) for l <= verbosity are enabled.
+// Install it once in main with grpclog.SetLoggerV2(grpcadapter.NewGRPCLoggerV2(log, 0)).
+// Caller field points to the code calling grpclog or a component logger of grpc-go.
+func NewGRPCLoggerV2(l logger.Logger, verbosity int) grpclog.LoggerV2 {
+	// Skips the method of the adapter and the function of grpclog calling it
+	return grpcLogger{base: l.WithCallerSkip(2), verbosity: verbosity}
+}
+
+var _ grpclog.DepthLoggerV2 = grpcLogger{}
+
+func (g grpcLogger) Info(args ...interface{}) {
+	l, args := g.component(args)
+	l.Info(fmt.Sprint(args...))
+}
+
+func (g grpcLogger) Infoln(args ...interface{}) {
+	l, args := g.component(args)
+	l.Info(sprintln(args))
+}
+
+func (g grpcLogger) Infof(format string, args ...interface{}) {
+	g.base.With(logger.Fields{ComponentKey: DefaultComponent}).Infof(format, args...)
+}
+
+func (g grpcLogger) InfoDepth(depth int, args ...interface{}) {
+	l, args := g.component(args)
+	l.WithCallerSkip(depth).Info(sprintln(args))
+}
+
+func (g grpcLogger) Warning(args ...interface{}) {
+	l, args := g.component(args)
+	l.Warn(fmt.Sprint(args...))
+}
+
+func (g grpcLogger) Warningln(args ...interface{}) {
+	l, args := g.component(args)
+	l.Warn(sprintln(args))
+}
+
+func (g grpcLogger) Warningf(format string, args ...interface{}) {
+	g.base.With(logger.Fields{ComponentKey: DefaultComponent}).Warnf(format, args...)
+}
+
+func (g grpcLogger) WarningDepth(depth int, args ...interface{}) {
+	l, args := g.component(args)
+	l.WithCallerSkip(depth).Warn(sprintln(args))
+}
+
+func (g grpcLogger) Error(args ...interface{}) {
+	l, args := g.component(args)
+	l.Error(fmt.Sprint(args...))
+}
+
+func (g grpcLogger) Errorln(args ...interface{}) {
+	l, args := g.component(args)
+	l.Error(sprintln(args))
+}
+
+func (g grpcLogger) Errorf(format string, args ...interface{}) {
+	g.base.With(logger.Fields{ComponentKey: DefaultComponent}).Errorf(format, args...)
+}
+
+func (g grpcLogger) ErrorDepth(depth int, args ...interface{}) {
+	l, args := g.component(args)
+	l.WithCallerSkip(depth).Error(sprintln(args))
+}
+
+// Fatal goes through logger's Fatal, so it exits the same way as any other fatal entry
+func (g grpcLogger) Fatal(args ...interface{}) {
+	l, args := g.component(args)
+	l.Fatal(fmt.Sprint(args...))
+}
+
+func (g grpcLogger) Fatalln(args ...interface{}) {
+	l, args := g.component(args)
+	l.Fatal(sprintln(args))
+}
+
+func (g grpcLogger) Fatalf(format string, args ...interface{}) {
+	g.base.With(logger.Fields{ComponentKey: DefaultComponent}).Fatalf(format, args...)
+}
+
+func (g grpcLogger) FatalDepth(depth int, args ...interface{}) {
+	l, args := g.component(args)
+	l.WithCallerSkip(depth).Fatal(sprintln(args))
+}
+
+func (g grpcLogger) V(l int) bool {
+	return l <= g.verbosity
+}
+
+// component extracts "[name]" prefix grpc-go component loggers put as the first argument
+func (g grpcLogger) component(args []interface{}) (logger.Logger, []interface{}) {
+	component := DefaultComponent
+
+	if len(args) > 0 {
+		if s, ok := args[0].(string); ok && len(s) > 2 && strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+			component = s[1 : len(s)-1]
+			args = args[1:]
+		}
+	}
+
+	return g.base.With(logger.Fields{ComponentKey: component}), args
+}
+
+func sprintln(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
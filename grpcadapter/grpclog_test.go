@@ -0,0 +1,105 @@
+package grpcadapter
+
+import (
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/grpclog"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+func TestGRPCLoggerV2(t *testing.T) {
+	l, entries := newTestLogger(t)
+	g := NewGRPCLoggerV2(l, 2)
+
+	g.Infoln("[core]", "Channel created", 1)
+	g.Warningf("retrying %v", "dial")
+	g.Error("plain", "error")
+	g.(grpclog.DepthLoggerV2).ErrorDepth(1, "[transport]", "closing")
+
+	got := entries()
+	require.Len(t, got, 4)
+
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "Channel created 1", got[0]["message"])
+	assert.Equal(t, "core", got[0][ComponentKey])
+
+	assert.Equal(t, "warn", got[1]["level"])
+	assert.Equal(t, "retrying dial", got[1]["message"])
+	assert.Equal(t, DefaultComponent, got[1][ComponentKey])
+
+	assert.Equal(t, "error", got[2]["level"])
+	assert.Equal(t, "plainerror", got[2]["message"])
+	assert.Equal(t, DefaultComponent, got[2][ComponentKey])
+
+	assert.Equal(t, "closing", got[3]["message"])
+	assert.Equal(t, "transport", got[3][ComponentKey])
+
+	assert.True(t, g.V(0))
+	assert.True(t, g.V(2))
+	assert.False(t, g.V(3))
+}
+
+func TestGRPCLoggerV2_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "info", Caller: true})
+	grpclog.SetLoggerV2(NewGRPCLoggerV2(l, 0))
+	t.Cleanup(func() {
+		grpclog.SetLoggerV2(grpclog.NewLoggerV2(ioutil.Discard, ioutil.Discard, os.Stderr))
+	})
+
+	grpclog.Component("core").Infof("channel %d created", 1)
+	_, _, component, _ := runtime.Caller(0)
+	grpclog.Warning("plain")
+	_, _, plain, _ := runtime.Caller(0)
+
+	// Reported at the calls of grpclog, not in grpclog or the adapter
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "core", got[0][ComponentKey])
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "grpclog_test.go:"+strconv.Itoa(component-1)), got[0]["caller"])
+	assert.True(t, strings.HasSuffix(got[1]["caller"].(string), "grpclog_test.go:"+strconv.Itoa(plain-1)), got[1]["caller"])
+}
+
+// fatalRecorder records Fatal calls instead of exiting
+type fatalRecorder struct {
+	logger.Logger
+
+	fatals *[]string
+	fields logger.Fields
+}
+
+func (r fatalRecorder) With(fields logger.Fields) logger.Logger {
+	r.fields = fields
+	return r
+}
+
+func (r fatalRecorder) WithCallerSkip(int) logger.Logger {
+	return r
+}
+
+func (r fatalRecorder) Fatal(message ...interface{}) {
+	*r.fatals = append(*r.fatals, r.fields[ComponentKey].(string)+": "+message[0].(string))
+}
+
+func (r fatalRecorder) Fatalf(format string, args ...interface{}) {
+	*r.fatals = append(*r.fatals, r.fields[ComponentKey].(string)+": "+format)
+}
+
+func TestGRPCLoggerV2_Fatal(t *testing.T) {
+	var fatals []string
+	g := NewGRPCLoggerV2(fatalRecorder{fatals: &fatals}, 0)
+
+	g.Fatalln("[core]", "failed")
+	g.Fatal("failed")
+	g.Fatalf("failed %v", 1)
+
+	assert.Equal(t, []string{"core: failed", "grpc: failed", "grpc: failed %v"}, fatals)
+}
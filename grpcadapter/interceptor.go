@@ -0,0 +1,149 @@
+// Package grpcadapter provides gRPC server interceptors logging every call
+// and passing request-scoped logger to handlers via logger.FromContext.
+package grpcadapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/w84thesun/logger"
+)
+
+// Fields attached to call entries
+const (
+	MethodKey      = "grpc.method"
+	CodeKey        = "grpc.code"
+	DurationKey    = "grpc.duration_ms"
+	PeerKey        = "peer.address"
+	MetadataPrefix = "grpc.metadata."
+)
+
+// Logs one entry per unary call, handlers can get request-scoped logger with logger.FromContext.
+func UnaryServerInterceptor(l logger.Logger, opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (resp interface{}, err error) {
+		start := time.Now()
+		callLogger := o.callLogger(ctx, l, info.FullMethod)
+
+		defer func() {
+			if o.recovery {
+				if r := recover(); r != nil {
+					err = recovered(callLogger, r)
+				}
+			}
+			o.log(callLogger, "finished unary call", err, start)
+		}()
+
+		return handler(logger.NewContext(ctx, callLogger), req)
+	}
+}
+
+// Logs one entry per streaming call, handlers can get request-scoped logger with logger.FromContext.
+func StreamServerInterceptor(l logger.Logger, opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(
+		srv interface{},
+		stream grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) (err error) {
+		start := time.Now()
+		callLogger := o.callLogger(stream.Context(), l, info.FullMethod)
+
+		defer func() {
+			if o.recovery {
+				if r := recover(); r != nil {
+					err = recovered(callLogger, r)
+				}
+			}
+			o.log(callLogger, "finished streaming call", err, start)
+		}()
+
+		return handler(srv, &loggingStream{
+			ServerStream: stream,
+			ctx:          logger.NewContext(stream.Context(), callLogger),
+		})
+	}
+}
+
+type loggingStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *loggingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (o options) callLogger(ctx context.Context, l logger.Logger, method string) logger.Logger {
+	fields := logger.Fields{MethodKey: method}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[PeerKey] = p.Addr.String()
+	}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, key := range o.metadataKeys {
+			if values := md.Get(key); len(values) > 0 {
+				fields[MetadataPrefix+strings.ToLower(key)] = strings.Join(values, ",")
+			}
+		}
+	}
+
+	return l.With(fields)
+}
+
+func (o options) log(l logger.Logger, msg string, err error, start time.Time) {
+	code := status.Code(err)
+
+	l = l.With(logger.Fields{
+		CodeKey:     code.String(),
+		DurationKey: float64(time.Since(start)) / float64(time.Millisecond),
+	})
+	if err != nil {
+		l = l.With(logger.Fields{"error": err.Error()})
+	}
+
+	switch o.levelFunc(code) {
+	case "debug":
+		l.Debug(msg)
+	case "warn":
+		l.Warn(msg)
+	case "error":
+		l.Error(msg)
+	default:
+		l.Info(msg)
+	}
+}
+
+// recovered logs the panic trace the same way logger.Recover does, but returns an error instead of panicking.
+// The panic value may hold internal data, so it's only logged and clients get a fixed message.
+func recovered(l logger.Logger, r interface{}) error {
+	switch v := r.(type) {
+	case error:
+		l.Trace(v)
+	case string:
+		l.Trace(errors.New(v))
+	default:
+		l.Trace(fmt.Errorf("%v", v))
+	}
+
+	return status.Error(codes.Internal, "internal error")
+}
@@ -0,0 +1,161 @@
+package grpcadapter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func incomingContext() context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000},
+	})
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "abc", "authorization", "secret"))
+}
+
+var unaryInfo = &grpc.UnaryServerInfo{FullMethod: "/orders.Orders/Create"}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	l, entries := newTestLogger(t)
+	interceptor := UnaryServerInterceptor(l, WithMetadataKeys("X-Request-ID"))
+
+	resp, err := interceptor(incomingContext(), "req", unaryInfo, func(ctx context.Context, req interface{}) (interface{}, error) {
+		callLogger, ok := logger.FromContext(ctx)
+		require.True(t, ok)
+		callLogger.Info("handling")
+		return "resp", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+
+	got := entries()
+	require.Len(t, got, 2)
+
+	assert.Equal(t, "handling", got[0]["message"])
+	assert.Equal(t, "/orders.Orders/Create", got[0][MethodKey])
+	assert.Equal(t, "abc", got[0][MetadataPrefix+"x-request-id"])
+
+	assert.Equal(t, "finished unary call", got[1]["message"])
+	assert.Equal(t, "info", got[1]["level"])
+	assert.Equal(t, "OK", got[1][CodeKey])
+	assert.Equal(t, "10.0.0.1:5000", got[1][PeerKey])
+	assert.Equal(t, "abc", got[1][MetadataPrefix+"x-request-id"])
+	assert.NotContains(t, got[1], MetadataPrefix+"authorization")
+	assert.Contains(t, got[1], DurationKey)
+}
+
+func TestUnaryServerInterceptor_Levels(t *testing.T) {
+	tests := []struct {
+		code  codes.Code
+		level string
+	}{
+		{code: codes.InvalidArgument, level: "warn"},
+		{code: codes.Internal, level: "error"},
+		{code: codes.NotFound, level: "warn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			l, entries := newTestLogger(t)
+			interceptor := UnaryServerInterceptor(l)
+
+			_, err := interceptor(incomingContext(), "req", unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+				return nil, status.Error(tt.code, "failed")
+			})
+			assert.Equal(t, tt.code, status.Code(err))
+
+			got := entries()
+			require.Len(t, got, 1)
+			assert.Equal(t, tt.level, got[0]["level"])
+			assert.Equal(t, tt.code.String(), got[0][CodeKey])
+		})
+	}
+}
+
+func TestUnaryServerInterceptor_CustomLevels(t *testing.T) {
+	l, entries := newTestLogger(t)
+	interceptor := UnaryServerInterceptor(l, WithLevels(func(codes.Code) string { return "debug" }))
+
+	_, err := interceptor(incomingContext(), "req", unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Internal, "failed")
+	})
+	require.Error(t, err)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "debug", got[0]["level"])
+}
+
+func TestUnaryServerInterceptor_Recovery(t *testing.T) {
+	l, entries := newTestLogger(t)
+	interceptor := UnaryServerInterceptor(l)
+
+	_, err := interceptor(incomingContext(), "req", unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "error", got[0]["level"])
+	assert.Contains(t, got[0]["message"], "boom")
+	assert.Equal(t, "error", got[1]["level"])
+	assert.Equal(t, "Internal", got[1][CodeKey])
+}
+
+func TestUnaryServerInterceptor_NoRecovery(t *testing.T) {
+	l, _ := newTestLogger(t)
+	interceptor := UnaryServerInterceptor(l, WithRecovery(false))
+
+	assert.Panics(t, func() {
+		_, _ = interceptor(incomingContext(), "req", unaryInfo, func(context.Context, interface{}) (interface{}, error) {
+			panic("boom")
+		})
+	})
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	l, entries := newTestLogger(t)
+	interceptor := StreamServerInterceptor(l)
+
+	info := &grpc.StreamServerInfo{FullMethod: "/orders.Orders/Watch", IsServerStream: true}
+	err := interceptor(nil, fakeStream{ctx: incomingContext()}, info, func(srv interface{}, stream grpc.ServerStream) error {
+		_, ok := logger.FromContext(stream.Context())
+		assert.True(t, ok)
+		return status.Error(codes.Unavailable, "gone")
+	})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "finished streaming call", got[0]["message"])
+	assert.Equal(t, "error", got[0]["level"])
+	assert.Equal(t, "/orders.Orders/Watch", got[0][MethodKey])
+	assert.Equal(t, "rpc error: code = Unavailable desc = gone", got[0]["error"])
+}
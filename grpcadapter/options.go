@@ -0,0 +1,60 @@
+package grpcadapter
+
+import (
+	"google.golang.org/grpc/codes"
+)
+
+type options struct {
+	levelFunc    func(codes.Code) string
+	metadataKeys []string
+	recovery     bool
+}
+
+// Configures UnaryServerInterceptor and StreamServerInterceptor
+type InterceptorOption func(*options)
+
+func newOptions(opts []InterceptorOption) options {
+	o := options{
+		levelFunc: DefaultCodeToLevel,
+		recovery:  true,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Overrides level of the entry logged for every call, DefaultCodeToLevel is used by default
+func WithLevels(f func(codes.Code) string) InterceptorOption {
+	return func(o *options) {
+		o.levelFunc = f
+	}
+}
+
+// Adds values of incoming metadata keys to the call fields as "grpc.metadata.<key>"
+func WithMetadataKeys(keys ...string) InterceptorOption {
+	return func(o *options) {
+		o.metadataKeys = append(o.metadataKeys, keys...)
+	}
+}
+
+// Enables or disables turning handler panics into error entries and codes.Internal, enabled by default
+func WithRecovery(enabled bool) InterceptorOption {
+	return func(o *options) {
+		o.recovery = enabled
+	}
+}
+
+// Maps client mistakes to warn and server failures to error level
+func DefaultCodeToLevel(code codes.Code) string {
+	switch code {
+	case codes.OK:
+		return "info"
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.ResourceExhausted,
+		codes.FailedPrecondition, codes.Aborted, codes.OutOfRange:
+		return "warn"
+	default:
+		return "error"
+	}
+}
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+// healthConn tracks whether the network output is usable: it isn't closed and the last write succeeded.
+// There is no reconnection, so it stays unhealthy until a write succeeds again, e.g. UDP peer comes back.
+type healthConn struct {
+	net.Conn
+
+	// 1 if healthy
+	state int32
+}
+
+func newHealthConn(conn net.Conn) *healthConn {
+	return &healthConn{Conn: conn, state: 1}
+}
+
+func (c *healthConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if err != nil {
+		atomic.StoreInt32(&c.state, 0)
+	} else {
+		atomic.StoreInt32(&c.state, 1)
+	}
+	return n, err
+}
+
+func (c *healthConn) Close() error {
+	atomic.StoreInt32(&c.state, -1)
+	return c.Conn.Close()
+}
+
+func (c *healthConn) healthy() bool {
+	return atomic.LoadInt32(&c.state) == 1
+}
+
+// sinksHealthy reports whether the last write of every output succeeded and network outputs are connected
+func (s *statsState) sinksHealthy() bool {
+	for _, sink := range s.snapshot().Sinks {
+		if !sink.FailingSince.IsZero() || !sink.Connected {
+			return false
+		}
+	}
+	return true
+}
+
+func (l loggerImpl) SinkHealthy() bool {
+	if l.stats == nil {
+		return true
+	}
+	return l.stats.sinksHealthy()
+}
@@ -0,0 +1,38 @@
+package logger
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSinkHealthy_Stdout(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger.Info("hello")
+	assert.True(t, logger.SinkHealthy())
+	require.NoError(t, logger.Close())
+	assert.True(t, logger.SinkHealthy())
+}
+
+func TestSinkHealthy_FailingOutput(t *testing.T) {
+	sink := &mockSink{err: errors.New("disk full")}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, sink)
+	require.NoError(t, err)
+
+	// Any output counts, not only network ones
+	logger.Info("lost")
+	assert.False(t, logger.SinkHealthy())
+	assert.False(t, logger.Namespace("derived").SinkHealthy())
+
+	sink.mu.Lock()
+	sink.err = nil
+	sink.mu.Unlock()
+	logger.Info("written")
+	assert.True(t, logger.SinkHealthy())
+}
@@ -0,0 +1,229 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Header holding request id, generated by HTTPMiddleware if missing
+const RequestIDHeader = "X-Request-ID"
+
+// Fields attached by HTTPMiddleware, framework adapters use the same keys
+const (
+	RequestIDKey  = "request_id"
+	MethodKey     = "method"
+	PathKey       = "path"
+	QueryKey      = "query"
+	RemoteAddrKey = "remote_addr"
+	StatusKey     = "status"
+	BytesOutKey   = "bytes_out"
+	LatencyKey    = "latency_ms"
+
+	// Route pattern, e.g. "/orders/:id", set by framework adapters
+	RouteKey = "route"
+	// Client address resolved by framework from forwarding headers
+	ClientIPKey = "client_ip"
+)
+
+// Replaces values of redacted query parameters
+const redacted = "REDACTED"
+
+type httpOptions struct {
+	skipPaths map[string]struct{}
+	redact    map[string]struct{}
+}
+
+// Configures HTTPMiddleware
+type HTTPOption func(*httpOptions)
+
+// Disables access entries for the paths, e.g. health checks. Handlers still get request-scoped logger.
+func HTTPSkipPaths(paths ...string) HTTPOption {
+	return func(o *httpOptions) {
+		for _, path := range paths {
+			o.skipPaths[path] = struct{}{}
+		}
+	}
+}
+
+// Replaces values of the query parameters in logged query, e.g. for tokens
+func HTTPRedactQuery(params ...string) HTTPOption {
+	return func(o *httpOptions) {
+		for _, param := range params {
+			o.redact[param] = struct{}{}
+		}
+	}
+}
+
+// HTTPMiddleware stores request-scoped logger in the request context (see FromContext),
+// logs an access entry per request and turns handler panics into 500 responses.
+func HTTPMiddleware(l Logger, opts ...HTTPOption) func(http.Handler) http.Handler {
+	o := httpOptions{
+		skipPaths: map[string]struct{}{},
+		redact:    map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" {
+				requestID = NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			fields := Fields{
+				RequestIDKey:  requestID,
+				MethodKey:     r.Method,
+				PathKey:       r.URL.Path,
+				RemoteAddrKey: r.RemoteAddr,
+			}
+			if r.URL.RawQuery != "" {
+				fields[QueryKey] = o.redactQuery(r.URL.Query())
+			}
+			requestLogger := l.With(fields)
+
+			rw := &responseWriter{ResponseWriter: w}
+
+			defer func() {
+				if i := recover(); i != nil {
+					TraceHandlerPanic(requestLogger, i)
+
+					if rw.status == 0 {
+						http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					} else {
+						// Too late to change the response, make the access entry reflect the failure
+						rw.status = http.StatusInternalServerError
+					}
+				}
+
+				if _, ok := o.skipPaths[r.URL.Path]; ok {
+					return
+				}
+				status := rw.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				LogAccess(requestLogger, status, rw.bytes, time.Since(start))
+			}()
+
+			next.ServeHTTP(rw, r.WithContext(NewContext(r.Context(), requestLogger)))
+		})
+	}
+}
+
+// LogAccess logs "request completed" entry with status, bytes_out and latency_ms fields,
+// at error level for 5xx statuses, warn for 4xx and info otherwise
+func LogAccess(l Logger, status, bytesOut int, latency time.Duration) {
+	l = l.With(Fields{
+		StatusKey:   status,
+		BytesOutKey: bytesOut,
+		LatencyKey:  float64(latency) / float64(time.Millisecond),
+	})
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		l.Error("request completed")
+	case status >= http.StatusBadRequest:
+		l.Warn("request completed")
+	default:
+		l.Info("request completed")
+	}
+}
+
+func (o httpOptions) redactQuery(query url.Values) string {
+	for param := range query {
+		if _, ok := o.redact[param]; ok {
+			query[param] = []string{redacted}
+		}
+	}
+	return query.Encode()
+}
+
+// TracePanic logs value recovered from a handler panic with Trace, converting it to error like Recover does
+func TracePanic(l Logger, i interface{}) {
+	switch v := i.(type) {
+	case error:
+		l.Trace(v)
+	case string:
+		l.Trace(errors.New(v))
+	default:
+		l.Trace(fmt.Errorf("%v", v))
+	}
+}
+
+// TraceHandlerPanic logs value recovered from a handler panic by a middleware with TracePanic.
+// http.ErrAbortHandler is re-panicked without logging, so net/http aborts the response quietly.
+func TraceHandlerPanic(l Logger, i interface{}) {
+	if i == http.ErrAbortHandler {
+		panic(i)
+	}
+	TracePanic(l, i)
+}
+
+// NewRequestID returns random hex id for requests without RequestIDHeader
+func NewRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(id)
+}
+
+// responseWriter records status and size of the response
+type responseWriter struct {
+	http.ResponseWriter
+
+	status int
+	bytes  int
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection, e.g. for websocket upgrades
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer doesn't support hijacking")
+	}
+	conn, rw, err := h.Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,184 @@
+package logger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func newHTTPTestLogger(t *testing.T) (Logger, *lockedBuffer) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "http", Level: "debug"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	return logger, buf
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestLogger, ok := FromContext(r.Context())
+		require.True(t, ok)
+		requestLogger.Info("handling")
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set(RequestIDHeader, "abc")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "abc", rec.Header().Get(RequestIDHeader))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "handling", entries[0]["message"])
+	assert.Equal(t, "abc", entries[0][RequestIDKey])
+
+	access := entries[1]
+	assert.Equal(t, "info", access["level"])
+	assert.Equal(t, "abc", access[RequestIDKey])
+	assert.Equal(t, http.MethodPost, access[MethodKey])
+	assert.Equal(t, "/orders", access[PathKey])
+	assert.Equal(t, req.RemoteAddr, access[RemoteAddrKey])
+	assert.Equal(t, float64(http.StatusCreated), access[StatusKey])
+	assert.Equal(t, float64(5), access[BytesOutKey])
+	assert.Contains(t, access, LatencyKey)
+	assert.Equal(t, "http", access["namespace"])
+}
+
+func TestHTTPMiddleware_StatusLevels(t *testing.T) {
+	tests := []struct {
+		status int
+		level  string
+	}{
+		{status: http.StatusOK, level: "info"},
+		{status: http.StatusFound, level: "info"},
+		{status: http.StatusNotFound, level: "warn"},
+		{status: http.StatusServiceUnavailable, level: "error"},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			logger, buf := newHTTPTestLogger(t)
+
+			handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := buf.entries(t)
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.level, entries[0]["level"])
+			assert.Equal(t, float64(tt.status), entries[0][StatusKey])
+		})
+	}
+}
+
+func TestHTTPMiddleware_GeneratedRequestID(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	handler := HTTPMiddleware(logger)(http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	requestID := rec.Header().Get(RequestIDHeader)
+	assert.Len(t, requestID, 32)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, requestID, entries[0][RequestIDKey])
+}
+
+func TestHTTPMiddleware_SkipPaths(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	handler := HTTPMiddleware(logger, HTTPSkipPaths("/healthz"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, ok := FromContext(r.Context())
+		assert.True(t, ok)
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/orders", entries[0][PathKey])
+}
+
+func TestHTTPMiddleware_RedactQuery(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	handler := HTTPMiddleware(logger, HTTPRedactQuery("token"))(http.NotFoundHandler())
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders?token=secret&page=2", nil))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "page=2&token=REDACTED", entries[0][QueryKey])
+}
+
+func TestHTTPMiddleware_Recover(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Contains(t, entries[0]["message"], "boom")
+	assert.Equal(t, "/orders", entries[0][PathKey])
+
+	assert.Equal(t, "error", entries[1]["level"])
+	assert.Equal(t, float64(http.StatusInternalServerError), entries[1][StatusKey])
+}
+
+// http.ErrAbortHandler reaches net/http, which aborts the response without logging
+func TestHTTPMiddleware_AbortHandler(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	handler := HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/orders", nil))
+	})
+	assert.Empty(t, buf.entries(t))
+}
+
+func TestHTTPMiddleware_Hijack(t *testing.T) {
+	logger, buf := newHTTPTestLogger(t)
+
+	server := httptest.NewServer(HTTPMiddleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		_ = rw.Flush()
+	})))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/ws")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	// Logged once the handler returns, which may be after the client got the response
+	require.Eventually(t, func() bool { return len(buf.entries(t)) == 1 }, time.Second, time.Millisecond)
+	entries := buf.entries(t)
+	assert.Equal(t, float64(http.StatusSwitchingProtocols), entries[0][StatusKey])
+}
@@ -0,0 +1,357 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Defaults of the HTTP output, see LoggingConfig.HTTPEndpoint
+const (
+	DefaultHTTPBatchSize     = 500
+	DefaultHTTPFlushInterval = time.Second
+	DefaultHTTPRetries       = 3
+	DefaultHTTPTimeout       = 10 * time.Second
+
+	// Consecutive failed batches opening the circuit breaker
+	DefaultHTTPBreakerFailures = 5
+)
+
+// Delays of the HTTP output, replaced by tests. Retries wait backoff doubled on every attempt,
+// the open circuit breaker drops batches for cooldown and then lets one of them try.
+var (
+	httpRetryBackoff    = 100 * time.Millisecond
+	httpBreakerCooldown = 30 * time.Second
+)
+
+// Returned by Sync for batches dropped without sending while the HTTP endpoint keeps failing
+var ErrHTTPCircuitOpen = errors.New("http output circuit breaker is open, batch dropped")
+
+// Returned by writes of the HTTP output for batches dropped since its queue is full, e.g. while the endpoint hangs
+var ErrHTTPQueueFull = errors.New("http output queue is full, batch dropped")
+
+// Returned by httpWriter methods called after Close
+var errHTTPClosed = errors.New("http writer is closed")
+
+// httpStatusError is returned for batches rejected by the endpoint
+type httpStatusError struct {
+	status int
+}
+
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("http endpoint responded with status %d", e.status)
+}
+
+// httpWriter buffers entries and POSTs them as NDJSON batches of up to batchSize entries, at least every
+// flush interval. Batches are sent in background in order, failed ones are retried with exponential backoff.
+// Batches not fitting the queue are dropped, so writes never wait for the endpoint. Errors are returned
+// by the next Write or Sync.
+type httpWriter struct {
+	// Entries written but not sent yet and ones of batches failed to be sent, reported to Stats.
+	// First, so they are 64-bit aligned for atomic operations.
+	pending int64
+	dropped uint64
+
+	client    *http.Client
+	endpoint  string
+	headers   map[string]string
+	batchSize int
+	retries   int
+
+	// Guards the batch being filled, non-blocking queue sends and closed
+	mu     sync.Mutex
+	buf    []byte
+	count  int
+	closed bool
+	// Sync calls sending to the queue without the lock, waited by Close before closing the queue
+	senders sync.WaitGroup
+
+	errMu sync.Mutex
+	err   error
+
+	queue chan httpBatch
+	stop  chan struct{}
+	done  chan struct{}
+
+	// Used only by the sending goroutine
+	failures  int
+	openUntil time.Time
+}
+
+// httpBatch is either entries to send or a marker closed once all previous batches are sent
+type httpBatch struct {
+	body    []byte
+	entries int
+	sent    chan struct{}
+}
+
+func newHTTPWriter(config LoggingConfig) (*httpWriter, error) {
+	endpoint, err := url.Parse(config.HTTPEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, fmt.Errorf("invalid HTTPEndpoint %v, must be http or https URL", config.HTTPEndpoint)
+	}
+
+	batchSize := config.HTTPBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultHTTPBatchSize
+	}
+	interval := config.HTTPFlushInterval
+	if interval <= 0 {
+		interval = DefaultHTTPFlushInterval
+	}
+	retries := config.HTTPRetries
+	switch {
+	case retries == 0:
+		retries = DefaultHTTPRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = config.HTTPTLSConfig
+
+	w := &httpWriter{
+		client:    &http.Client{Transport: transport, Timeout: DefaultHTTPTimeout},
+		endpoint:  endpoint.String(),
+		headers:   config.HTTPHeaders,
+		batchSize: batchSize,
+		retries:   retries,
+		queue:     make(chan httpBatch, 4),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go w.send()
+	go w.flushEvery(interval)
+	return w, nil
+}
+
+func (w *httpWriter) send() {
+	defer close(w.done)
+
+	for batch := range w.queue {
+		if batch.sent != nil {
+			close(batch.sent)
+			continue
+		}
+		err := w.sendBatch(batch.body)
+		atomic.AddInt64(&w.pending, -int64(batch.entries))
+		if err != nil {
+			atomic.AddUint64(&w.dropped, uint64(batch.entries))
+			w.setErr(err)
+		}
+	}
+}
+
+// sendBatch posts the batch with retries unless the circuit breaker is open
+func (w *httpWriter) sendBatch(body []byte) error {
+	if time.Now().Before(w.openUntil) {
+		return ErrHTTPCircuitOpen
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = w.post(body)
+		if err == nil {
+			w.failures = 0
+			return nil
+		}
+
+		var statusErr httpStatusError
+		retryable := !errors.As(err, &statusErr) ||
+			statusErr.status >= http.StatusInternalServerError || statusErr.status == http.StatusTooManyRequests
+		if !retryable || attempt >= w.retries {
+			break
+		}
+		time.Sleep(httpRetryBackoff << uint(attempt))
+	}
+
+	// Stays open after cooldown until a batch succeeds
+	w.failures++
+	if w.failures >= DefaultHTTPBreakerFailures {
+		w.openUntil = time.Now().Add(httpBreakerCooldown)
+	}
+	return err
+}
+
+func (w *httpWriter) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Drained, so the connection is reused
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return httpStatusError{status: resp.StatusCode}
+	}
+	return nil
+}
+
+func (w *httpWriter) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.mu.Lock()
+			if !w.closed {
+				if err := w.enqueue(); err != nil {
+					w.setErr(err)
+				}
+			}
+			w.mu.Unlock()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// enqueue queues the batch being filled or drops it if the queue is full, must be called with the lock held
+func (w *httpWriter) enqueue() error {
+	batch, ok := w.take()
+	if !ok {
+		return nil
+	}
+
+	select {
+	case w.queue <- batch:
+		return nil
+	default:
+		atomic.AddInt64(&w.pending, -int64(batch.entries))
+		atomic.AddUint64(&w.dropped, uint64(batch.entries))
+		return ErrHTTPQueueFull
+	}
+}
+
+// take returns the batch being filled and starts a new one, must be called with the lock held
+func (w *httpWriter) take() (httpBatch, bool) {
+	if w.count == 0 {
+		return httpBatch{}, false
+	}
+
+	batch := httpBatch{body: w.buf, entries: w.count}
+	w.buf = nil
+	w.count = 0
+	return batch, true
+}
+
+func (w *httpWriter) setErr(err error) {
+	w.errMu.Lock()
+	w.err = multierr.Append(w.err, err)
+	w.errMu.Unlock()
+}
+
+// takeErr returns errors of batches since the previous call
+func (w *httpWriter) takeErr() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+
+	err := w.err
+	w.err = nil
+	return err
+}
+
+func (w *httpWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errHTTPClosed
+	}
+
+	// Zap reuses p once Write returns, so it's copied into the batch
+	w.buf = append(w.buf, p...)
+	w.count++
+	atomic.AddInt64(&w.pending, 1)
+	if w.count >= w.batchSize {
+		if err := w.enqueue(); err != nil {
+			return len(p), multierr.Append(err, w.takeErr())
+		}
+	}
+	return len(p), w.takeErr()
+}
+
+// Sync sends buffered entries and waits until all batches are sent, returns their errors
+func (w *httpWriter) Sync() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	batch, ok := w.take()
+	w.senders.Add(1)
+	w.mu.Unlock()
+
+	// Writes aren't blocked while the queue is full
+	if ok {
+		w.queue <- batch
+	}
+	sent := make(chan struct{})
+	w.queue <- httpBatch{sent: sent}
+	w.senders.Done()
+
+	<-sent
+	return w.takeErr()
+}
+
+// queueStats reports entries waiting to be sent to Stats, with capacity of the batch and queued batches
+// after which batches are dropped
+func (w *httpWriter) queueStats() (int, int, uint64) {
+	return int(atomic.LoadInt64(&w.pending)), w.batchSize * (cap(w.queue) + 1), atomic.LoadUint64(&w.dropped)
+}
+
+// Close sends buffered entries and stops background sending, calling it more than once is no-op
+func (w *httpWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	batch, ok := w.take()
+	w.mu.Unlock()
+
+	w.senders.Wait()
+	if ok {
+		w.queue <- batch
+	}
+	close(w.queue)
+	close(w.stop)
+
+	<-w.done
+	w.client.CloseIdleConnections()
+	return w.takeErr()
+}
+
+// newHTTPCore builds JSON core of the HTTP output, with the same keys as logstash one
+func newHTTPCore(zapLevel zapcore.Level, config LoggingConfig) (zapcore.Core, io.Closer, error) {
+	w, err := newHTTPWriter(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newCompatJSONCore(w, zapLevel, config), w, nil
+}
@@ -0,0 +1,312 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// httpSink records batches POSTed to it, responding with the statuses in order and 200 afterwards
+type httpSink struct {
+	mu       sync.Mutex
+	batches  []string
+	requests int
+	header   http.Header
+	statuses []int
+}
+
+func (s *httpSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	s.header = r.Header
+
+	if len(s.statuses) > 0 {
+		status := s.statuses[0]
+		s.statuses = s.statuses[1:]
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+	}
+	s.batches = append(s.batches, string(body))
+}
+
+func (s *httpSink) received() ([]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.batches...), s.requests
+}
+
+// withHTTPDelays makes retries and circuit breaker cooldown fast for the test
+func withHTTPDelays(t *testing.T, backoff, cooldown time.Duration) {
+	prevBackoff, prevCooldown := httpRetryBackoff, httpBreakerCooldown
+	httpRetryBackoff, httpBreakerCooldown = backoff, cooldown
+	t.Cleanup(func() {
+		httpRetryBackoff, httpBreakerCooldown = prevBackoff, prevCooldown
+	})
+}
+
+func TestHTTPOutput_Batching(t *testing.T) {
+	sink := &httpSink{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	logger, err := newLogger(LoggingConfig{
+		Service:           "testing",
+		DisableStdout:     true,
+		HTTPEndpoint:      server.URL,
+		HTTPHeaders:       map[string]string{"Authorization": "Bearer token"},
+		HTTPBatchSize:     3,
+		HTTPFlushInterval: time.Hour,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	for i := 0; i < 7; i++ {
+		logger.Info("batched")
+	}
+	require.NoError(t, logger.Sync())
+
+	batches, _ := sink.received()
+	require.Len(t, batches, 3)
+	for i, lines := range []int{3, 3, 1} {
+		assert.Equal(t, lines, strings.Count(batches[i], "\n"))
+		assert.Contains(t, batches[i], `"message":"batched"`)
+	}
+	assert.Equal(t, "Bearer token", sink.header.Get("Authorization"))
+	assert.Equal(t, "application/x-ndjson", sink.header.Get("Content-Type"))
+
+	logger.Info("closed")
+	require.NoError(t, logger.Close())
+	batches, _ = sink.received()
+	assert.Len(t, batches, 4)
+}
+
+func TestHTTPOutput_FlushInterval(t *testing.T) {
+	sink := &httpSink{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	logger, err := newLogger(LoggingConfig{
+		Service:           "testing",
+		DisableStdout:     true,
+		HTTPEndpoint:      server.URL,
+		HTTPFlushInterval: 10 * time.Millisecond,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("flushed")
+	assert.Eventually(t, func() bool {
+		batches, _ := sink.received()
+		return len(batches) == 1
+	}, 2*time.Second, time.Millisecond)
+}
+
+func TestHTTPOutput_Retry(t *testing.T) {
+	withHTTPDelays(t, time.Millisecond, time.Hour)
+
+	sink := &httpSink{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		DisableStdout: true,
+		HTTPEndpoint:  server.URL,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("retried")
+	require.NoError(t, logger.Sync())
+
+	batches, requests := sink.received()
+	assert.Len(t, batches, 1)
+	assert.Equal(t, 3, requests)
+}
+
+func TestHTTPOutput_NotRetried(t *testing.T) {
+	withHTTPDelays(t, time.Millisecond, time.Hour)
+
+	sink := &httpSink{statuses: []int{http.StatusBadRequest}}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		DisableStdout: true,
+		HTTPEndpoint:  server.URL,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("rejected")
+	assert.Error(t, logger.Sync())
+
+	_, requests := sink.received()
+	assert.Equal(t, 1, requests)
+}
+
+func TestHTTPOutput_CircuitBreaker(t *testing.T) {
+	withHTTPDelays(t, time.Millisecond, 50*time.Millisecond)
+
+	var statuses []int
+	for i := 0; i < DefaultHTTPBreakerFailures+1; i++ {
+		statuses = append(statuses, http.StatusInternalServerError)
+	}
+	sink := &httpSink{statuses: statuses}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		DisableStdout: true,
+		HTTPEndpoint:  server.URL,
+		HTTPRetries:   -1,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	for i := 0; i < DefaultHTTPBreakerFailures; i++ {
+		logger.Info("failed")
+		assert.Error(t, logger.Sync())
+	}
+
+	// Dropped without requests while open
+	logger.Info("dropped")
+	assert.Equal(t, ErrHTTPCircuitOpen, logger.Sync())
+	_, requests := sink.received()
+	assert.Equal(t, DefaultHTTPBreakerFailures, requests)
+
+	// One batch tries after cooldown, failure opens the breaker again
+	time.Sleep(60 * time.Millisecond)
+	logger.Info("half-open")
+	assert.Error(t, logger.Sync())
+	logger.Info("dropped again")
+	assert.Equal(t, ErrHTTPCircuitOpen, logger.Sync())
+
+	// Success closes it
+	time.Sleep(60 * time.Millisecond)
+	logger.Info("recovered")
+	require.NoError(t, logger.Sync())
+	logger.Info("delivered")
+	require.NoError(t, logger.Sync())
+
+	batches, requests := sink.received()
+	assert.Len(t, batches, 2)
+	assert.Equal(t, DefaultHTTPBreakerFailures+3, requests)
+}
+
+func TestHTTPOutput_StalledEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	w, err := newHTTPWriter(LoggingConfig{HTTPEndpoint: server.URL, HTTPBatchSize: 1, HTTPFlushInterval: time.Hour})
+	require.NoError(t, err)
+
+	// The first batch hangs in the sending goroutine, the next ones fill the queue and the rest are dropped
+	start := time.Now()
+	var full int
+	for i := 0; i < 20; i++ {
+		if _, err := w.Write([]byte("{}\n")); err == ErrHTTPQueueFull {
+			full++
+		}
+	}
+	assert.True(t, time.Since(start) < time.Second, time.Since(start))
+	assert.GreaterOrEqual(t, full, 20-cap(w.queue)-1)
+
+	_, _, dropped := w.queueStats()
+	assert.Equal(t, uint64(full), dropped)
+
+	close(release)
+	assert.NoError(t, w.Close())
+}
+
+// Sync and Close waiting for the full queue don't block writes
+func TestHTTPOutput_SyncStalledEndpoint(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	w, err := newHTTPWriter(LoggingConfig{HTTPEndpoint: server.URL, HTTPBatchSize: 1, HTTPFlushInterval: time.Hour})
+	require.NoError(t, err)
+
+	// The first batch hangs in the sending goroutine, the next ones fill the queue
+	for i := 0; i <= cap(w.queue); i++ {
+		_, err := w.Write([]byte("{}\n"))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(w.queue) == i }, time.Second, time.Millisecond)
+	}
+
+	synced := make(chan error)
+	go func() { synced <- w.Sync() }()
+	time.Sleep(10 * time.Millisecond)
+
+	written := make(chan error)
+	go func() {
+		_, err := w.Write([]byte("{}\n"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		assert.Equal(t, ErrHTTPQueueFull, err)
+	case <-time.After(time.Second):
+		t.Fatal("write is blocked by sync")
+	}
+
+	closed := make(chan error)
+	go func() { closed <- w.Close() }()
+	time.Sleep(10 * time.Millisecond)
+	_, err = w.Write([]byte("{}\n"))
+	assert.Equal(t, errHTTPClosed, err)
+
+	close(release)
+	assert.NoError(t, <-synced)
+	assert.NoError(t, <-closed)
+}
+
+func TestHTTPOutput_TLS(t *testing.T) {
+	sink := &httpSink{}
+	server := httptest.NewTLSServer(sink)
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		DisableStdout: true,
+		HTTPEndpoint:  server.URL,
+		HTTPTLSConfig: &tls.Config{RootCAs: roots},
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger.Info("secure")
+	require.NoError(t, logger.Close())
+
+	batches, _ := sink.received()
+	assert.Len(t, batches, 1)
+}
+
+func TestHTTPOutput_InvalidEndpoint(t *testing.T) {
+	_, err := newLogger(LoggingConfig{Service: "testing", HTTPEndpoint: "logstash:5000"}, zapcore.AddSync(ioutil.Discard))
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Stdout writes to os.Stdout under the lock of Interactive loggers, so prompts and other output of CLI tools
+// written through it never interleave with entries: fmt.Fprint(logger.Stdout, "Continue? [y/N] ")
+var Stdout io.Writer = interactiveStdout
+
+var interactiveStdout = newInteractiveWriter(stdoutFile{os.Stdout})
+
+// interactiveWriter serializes writes of entries and other output to the same console
+type interactiveWriter struct {
+	mu  sync.Mutex
+	out zapcore.WriteSyncer
+
+	// Last output didn't end with newline, e.g. a prompt waiting for input
+	midLine bool
+}
+
+func newInteractiveWriter(out io.Writer) *interactiveWriter {
+	return &interactiveWriter{out: zapcore.AddSync(out)}
+}
+
+// Write writes p fully before any other output, it isn't syncing and may leave the line unfinished
+func (w *interactiveWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.write(p)
+}
+
+// write retries short writes, so an entry is never split by other output
+func (w *interactiveWriter) write(p []byte) (int, error) {
+	var written int
+	for written < len(p) {
+		n, err := w.out.Write(p[written:])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		if n == 0 {
+			return written, io.ErrShortWrite
+		}
+	}
+
+	if written > 0 {
+		w.midLine = p[written-1] != '\n'
+	}
+	return written, nil
+}
+
+// entries returns syncer for the console core of Interactive loggers
+func (w *interactiveWriter) entries() zapcore.WriteSyncer {
+	return interactiveEntries{w}
+}
+
+// interactiveEntries writes every entry on its own line and syncs it before the next one
+type interactiveEntries struct {
+	w *interactiveWriter
+}
+
+func (e interactiveEntries) Write(p []byte) (int, error) {
+	e.w.mu.Lock()
+	defer e.w.mu.Unlock()
+
+	if e.w.midLine {
+		if _, err := e.w.write([]byte{'\n'}); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := e.w.write(p)
+	if err != nil {
+		return n, err
+	}
+
+	// Terminals and pipes don't support fsync, errors are ignored like on Sync of os.Stdout
+	_ = e.w.out.Sync()
+	return n, nil
+}
+
+func (e interactiveEntries) Sync() error {
+	e.w.mu.Lock()
+	defer e.w.mu.Unlock()
+
+	return e.w.out.Sync()
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chunkedConsole accepts a few bytes per write like a slow terminal and counts syncs
+type chunkedConsole struct {
+	mu    sync.Mutex
+	buf   bytes.Buffer
+	syncs int
+}
+
+func (c *chunkedConsole) Write(p []byte) (int, error) {
+	if len(p) > 7 {
+		p = p[:7]
+	}
+	// Gives other writers a chance to interleave
+	runtime.Gosched()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buf.Write(p)
+}
+
+func (c *chunkedConsole) Sync() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.syncs++
+	return nil
+}
+
+func TestInteractive(t *testing.T) {
+	console := &chunkedConsole{}
+	w := newInteractiveWriter(console)
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Interactive: true}, w.entries())
+	require.NoError(t, err)
+
+	const goroutines, entries = 8, 20
+
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				logger.With(Fields{"goroutine": g, "entry": i}).Info("entry of goroutine " + strconv.Itoa(g))
+			}
+		}(g)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < entries; i++ {
+				_, err := w.Write([]byte("prompt of goroutine " + strconv.Itoa(g) + "\n"))
+				assert.NoError(t, err)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(console.buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2*goroutines*entries)
+
+	var logged int
+	for _, line := range lines {
+		if strings.HasPrefix(line, "prompt of goroutine ") {
+			continue
+		}
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		assert.Equal(t, "entry of goroutine "+strconv.Itoa(int(entry["goroutine"].(float64))), entry["message"])
+		logged++
+	}
+	assert.Equal(t, goroutines*entries, logged)
+	assert.Equal(t, goroutines*entries, console.syncs)
+}
+
+func TestInteractive_NewLine(t *testing.T) {
+	console := &chunkedConsole{}
+	w := newInteractiveWriter(console)
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Interactive: true}, w.entries())
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("Continue? [y/N] "))
+	require.NoError(t, err)
+	logger.Info("first")
+	logger.Info("second")
+
+	lines := strings.Split(console.buf.String(), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "Continue? [y/N] ", lines[0])
+	assert.Contains(t, lines[1], `"message":"first"`)
+	assert.Contains(t, lines[2], `"message":"second"`)
+	assert.Empty(t, lines[3])
+}
+
+// Other syncers are serialized and synced per entry too
+func TestInteractive_Syncer(t *testing.T) {
+	console := &chunkedConsole{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Interactive: true}, console)
+	require.NoError(t, err)
+
+	logger.Info("synced")
+	assert.Contains(t, console.buf.String(), `"message":"synced"`)
+	assert.Equal(t, 1, console.syncs)
+}
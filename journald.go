@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Native protocol socket of systemd-journald
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// Syslog priorities of journald PRIORITY field
+var journaldPriorities = map[zapcore.Level]int{
+	zapcore.DebugLevel:  7,
+	zapcore.InfoLevel:   6,
+	zapcore.WarnLevel:   4,
+	zapcore.ErrorLevel:  3,
+	zapcore.DPanicLevel: 2,
+	zapcore.PanicLevel:  2,
+	zapcore.FatalLevel:  2,
+}
+
+// journaldCore writes entries as journald native protocol datagrams, one field per line with uppercase names,
+// e.g. "request_id" becomes REQUEST_ID. Fields are kept unencoded like in sortedCore.
+// See https://systemd.io/JOURNAL_NATIVE_PROTOCOL/
+type journaldCore struct {
+	zapcore.LevelEnabler
+
+	conn       io.Writer
+	identifier string
+	context    []zapcore.Field
+}
+
+// newJournaldCore connects to the journald socket, an error is returned if it's absent or not supported
+func newJournaldCore(enab zapcore.LevelEnabler, identifier string, socket string) (zapcore.Core, io.Closer, error) {
+	conn, err := dialJournald(socket)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &journaldCore{LevelEnabler: enab, conn: conn, identifier: identifier}, conn, nil
+}
+
+func (c *journaldCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+
+	clone := *c
+	clone.context = context
+	return &clone
+}
+
+func (c *journaldCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *journaldCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf := &bytes.Buffer{}
+	appendJournaldField(buf, "MESSAGE", ent.Message)
+	appendJournaldField(buf, "PRIORITY", strconv.Itoa(journaldPriorities[ent.Level]))
+	appendJournaldField(buf, "SYSLOG_IDENTIFIER", c.identifier)
+	if ent.LoggerName != "" {
+		appendJournaldField(buf, "LOGGER", ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		appendJournaldField(buf, "CODE_FILE", ent.Caller.File)
+		appendJournaldField(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		appendJournaldField(buf, "CODE_FUNC", ent.Caller.Function)
+	}
+	if ent.Stack != "" {
+		appendJournaldField(buf, "STACKTRACE", ent.Stack)
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range append(c.context[:len(c.context):len(c.context)], fields...) {
+		field.AddTo(enc)
+	}
+	for key, value := range enc.Fields {
+		name := journaldFieldName(key)
+		if name == "" {
+			continue
+		}
+		appendJournaldField(buf, name, journaldValue(value))
+	}
+
+	_, err := c.conn.Write(buf.Bytes())
+	return err
+}
+
+func (c *journaldCore) Sync() error {
+	return nil
+}
+
+// journaldFieldName converts key to a journald field name: uppercase letters, digits and underscores not starting
+// with underscore or digit, at most 64 characters. Empty if nothing is left.
+func journaldFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	// Fields starting with underscore are trusted ones set by journald itself
+	name = strings.TrimLeft(name, "_0123456789")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// journaldValue formats field value, objects and arrays as JSON
+func journaldValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}, map[string]interface{}:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// appendJournaldField writes NAME=value line, values with newlines are written with explicit length
+func appendJournaldField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
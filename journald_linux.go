@@ -0,0 +1,14 @@
+//go:build linux
+// +build linux
+
+package logger
+
+import (
+	"io"
+	"net"
+)
+
+// dialJournald connects to the datagram socket of journald
+func dialJournald(socket string) (io.WriteCloser, error) {
+	return net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package logger
+
+import (
+	"errors"
+	"io"
+)
+
+// dialJournald always fails, journald runs only on Linux
+func dialJournald(socket string) (io.WriteCloser, error) {
+	return nil, errors.New("journald is supported only on linux")
+}
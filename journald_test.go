@@ -0,0 +1,123 @@
+//go:build linux
+// +build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// listenJournald listens on a temporary datagram socket like journald
+func listenJournald(t *testing.T) (string, *net.UnixConn, func()) {
+	dir, err := ioutil.TempDir("", "journald")
+	require.NoError(t, err)
+
+	socket := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	require.NoError(t, err)
+
+	return socket, conn, func() {
+		_ = conn.Close()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// readJournald reads a datagram and parses its fields, including ones with explicit length
+func readJournald(t *testing.T, conn *net.UnixConn) map[string]string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	buf := make([]byte, 64<<10)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	fields := make(map[string]string)
+	data := buf[:n]
+	for len(data) > 0 {
+		line := bytes.IndexByte(data, '\n')
+		require.True(t, line >= 0, "unterminated field")
+
+		if eq := bytes.IndexByte(data[:line], '='); eq >= 0 {
+			fields[string(data[:eq])] = string(data[eq+1 : line])
+			data = data[line+1:]
+			continue
+		}
+
+		name := string(data[:line])
+		data = data[line+1:]
+		size := binary.LittleEndian.Uint64(data[:8])
+		fields[name] = string(data[8 : 8+size])
+		data = data[8+size+1:]
+	}
+	return fields
+}
+
+func TestJournaldCore(t *testing.T) {
+	socket, conn, cleanup := listenJournald(t)
+	defer cleanup()
+
+	core, closer, err := newJournaldCore(zapcore.InfoLevel, "testing", socket)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	logger := zap.New(core).With(zap.String("namespace", "default"))
+	logger.Debug("disabled")
+	logger.Warn("hello",
+		zap.Int("request_id", 42),
+		zap.String("multi", "first\nsecond"),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.String("_trusted", "value"),
+	)
+
+	fields := readJournald(t, conn)
+	assert.Equal(t, map[string]string{
+		"MESSAGE":           "hello",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "testing",
+		"NAMESPACE":         "default",
+		"REQUEST_ID":        "42",
+		"MULTI":             "first\nsecond",
+		"TAGS":              `["a","b"]`,
+		"TRUSTED":           "value",
+	}, fields)
+}
+
+func TestJournaldCore_Absent(t *testing.T) {
+	_, _, err := newJournaldCore(zapcore.InfoLevel, "testing", "/nonexistent/journal/socket")
+	assert.Error(t, err)
+}
+
+func TestJournald_Fallback(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Journald: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	// Stdout is written whether journald is available or not
+	logger.Info("hello")
+	assert.Contains(t, buf.String(), `"message":"hello"`)
+}
+
+func TestJournaldFieldName(t *testing.T) {
+	for key, expected := range map[string]string{
+		"request_id":            "REQUEST_ID",
+		"http.status":           "HTTP_STATUS",
+		"__internal":            "INTERNAL",
+		"1st":                   "ST",
+		"@":                     "",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	} {
+		assert.Equal(t, expected, journaldFieldName(key), key)
+	}
+}
@@ -0,0 +1,41 @@
+// Package kafkaadapter routes diagnostics of sarama and kafka-go clients into the logger
+package kafkaadapter
+
+import (
+	"log"
+
+	"github.com/IBM/sarama"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/w84thesun/logger"
+)
+
+// Value of the component field attached to all entries
+const Component = "kafka"
+
+// Returns logger for sarama.Logger logging at info level:
+//
+//	sarama.Logger = kafkaadapter.NewSaramaLogger(log)
+//
+// Multi-line messages are logged as separate entries, one per line.
+func NewSaramaLogger(l logger.Logger) sarama.StdLogger {
+	return newStdLogger(l, "info")
+}
+
+// Returns logger for sarama.DebugLogger logging at debug level
+func NewSaramaDebugLogger(l logger.Logger) sarama.StdLogger {
+	return newStdLogger(l, "debug")
+}
+
+// Returns kafka-go logger logging at the level, e.g. "debug" for Logger and "error" for ErrorLogger of kafka.ReaderConfig.
+// Multi-line messages are logged as separate entries, one per line.
+func KafkaGoLogger(l logger.Logger, level string) kafka.LoggerFunc {
+	// Skips Printf of kafka.LoggerFunc, which kafka-go calls through its Logger interface
+	std := newStdLogger(l.WithCallerSkip(1), level)
+	return std.Printf
+}
+
+// newStdLogger relies on logger.StdLogger to split lines and add missing trailing newlines
+func newStdLogger(l logger.Logger, level string) *log.Logger {
+	return l.WithComponent(Component).StdLogger(level)
+}
@@ -0,0 +1,86 @@
+package kafkaadapter
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func TestSaramaLogger(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	var log sarama.StdLogger = NewSaramaLogger(l)
+	log.Print("Connected to broker at ", "localhost:9092")
+	log.Printf("client/metadata fetching metadata for %v\n", []string{"orders"})
+	log.Println("consumer/broker/1 abandoned subscription\nto orders/0 because consuming was taking too long")
+	NewSaramaDebugLogger(l).Printf("debug %d", 1)
+
+	got := entries()
+	require.Len(t, got, 5)
+
+	messages := make([]interface{}, 0, len(got))
+	for _, entry := range got {
+		messages = append(messages, entry["message"])
+		assert.Equal(t, Component, entry["component"])
+	}
+	assert.Equal(t, []interface{}{
+		"Connected to broker at localhost:9092",
+		"client/metadata fetching metadata for [orders]",
+		"consumer/broker/1 abandoned subscription",
+		"to orders/0 because consuming was taking too long",
+		"debug 1",
+	}, messages)
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "debug", got[4]["level"])
+}
+
+func TestKafkaGoLogger(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	config := kafka.ReaderConfig{
+		Logger:      KafkaGoLogger(l, "debug"),
+		ErrorLogger: KafkaGoLogger(l, "error"),
+	}
+	config.Logger.Printf("joined group %s", "orders")
+	config.ErrorLogger.Printf("failed to commit offsets:\n%v", "timeout")
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, "debug", got[0]["level"])
+	assert.Equal(t, "joined group orders", got[0]["message"])
+	assert.Equal(t, "error", got[1]["level"])
+	assert.Equal(t, "failed to commit offsets:", got[1]["message"])
+	assert.Equal(t, "timeout", got[2]["message"])
+	for _, entry := range got {
+		assert.Equal(t, Component, entry["component"])
+	}
+}
+
+func TestLoggers_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "debug", Caller: true})
+
+	_, _, line, _ := runtime.Caller(0)
+	NewSaramaLogger(l).Printf("sarama %d", 1)
+	KafkaGoLogger(l, "info").Printf("kafka-go %d", 2)
+
+	// Reported at the calls of the clients, not in the adapter or the standard logger
+	got := entries()
+	require.Len(t, got, 2)
+	for i, entry := range got {
+		assert.True(t, strings.HasSuffix(entry["caller"].(string), "kafka_test.go:"+strconv.Itoa(line+1+i)), entry["caller"])
+	}
+}
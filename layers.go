@@ -0,0 +1,77 @@
+package logger
+
+import "sync/atomic"
+
+// Layers walked by lookups before With flattens the chain into a single layer
+const maxFieldLayers = 16
+
+// fieldLayers is immutable set of logger fields, every With adds a layer overriding fields of its parent
+// instead of copying the whole map. The merged map is built only when all fields are needed, e.g. for logging.
+type fieldLayers struct {
+	parent *fieldLayers
+	layer  Fields
+	depth  int
+
+	// Fields, read-only once built
+	merged atomic.Value
+}
+
+func newFieldLayers(fields Fields) *fieldLayers {
+	return &fieldLayers{layer: fields.Copy(), depth: 1}
+}
+
+// with returns layers with fields added, fields are copied so callers may reuse the map
+func (f *fieldLayers) with(fields Fields) *fieldLayers {
+	if len(fields) == 0 {
+		return f
+	}
+	if f == nil {
+		return newFieldLayers(fields)
+	}
+	if f.depth >= maxFieldLayers {
+		return newFieldLayers(f.fields().Merge(fields))
+	}
+
+	return &fieldLayers{parent: f, layer: fields.Copy(), depth: f.depth + 1}
+}
+
+// get looks the key up from the last layer without merging them
+func (f *fieldLayers) get(key string) (interface{}, bool) {
+	for l := f; l != nil; l = l.parent {
+		if value, ok := l.layer[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// fields returns merged fields of all layers, must not be modified. Nil layers have no fields.
+func (f *fieldLayers) fields() Fields {
+	if f == nil {
+		return Fields{}
+	}
+	if merged, ok := f.merged.Load().(Fields); ok {
+		return merged
+	}
+
+	// Starts from the closest layer merged before, concurrent calls may merge twice with the same result
+	var base Fields
+	var layers []Fields
+	for l := f; l != nil; l = l.parent {
+		if merged, ok := l.merged.Load().(Fields); ok {
+			base = merged
+			break
+		}
+		layers = append(layers, l.layer)
+	}
+
+	merged := base.Copy()
+	for i := len(layers) - 1; i >= 0; i-- {
+		for k, v := range layers[i] {
+			merged[k] = v
+		}
+	}
+
+	f.merged.Store(merged)
+	return merged
+}
@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestFieldLayers(t *testing.T) {
+	input := Fields{"a": 1, "b": 1}
+	root := newFieldLayers(input)
+	// Layers keep copies of passed fields
+	input["a"] = 2
+
+	child := root.with(Fields{"b": 2, "c": 2})
+	sibling := root.with(Fields{"b": 3})
+	assert.Same(t, root, root.with(nil))
+
+	assert.Equal(t, Fields{"a": 1, "b": 1}, root.fields())
+	assert.Equal(t, Fields{"a": 1, "b": 2, "c": 2}, child.fields())
+	assert.Equal(t, Fields{"a": 1, "b": 3}, sibling.fields())
+
+	value, ok := child.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+	_, ok = sibling.get("c")
+	assert.False(t, ok)
+}
+
+func TestFieldLayers_Deep(t *testing.T) {
+	layers := newFieldLayers(Fields{"namespace": "default"})
+	for i := 0; i < 5*maxFieldLayers; i++ {
+		layers = layers.with(Fields{"layer_" + strconv.Itoa(i): i, "last": i})
+		assert.LessOrEqual(t, layers.depth, maxFieldLayers)
+	}
+
+	fields := layers.fields()
+	assert.Len(t, fields, 5*maxFieldLayers+2)
+	assert.Equal(t, 5*maxFieldLayers-1, fields["last"])
+	assert.Equal(t, "default", fields["namespace"])
+}
+
+// Derived loggers never change fields of their parents, a With chain logs the same as With of merged fields
+func TestLoggerImpl_WithChain(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	parent := logger.With(Fields{"request_id": "1"})
+	parent.Info("parent")
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			chained := parent.Namespace("handler")
+			for i := 0; i < 20; i++ {
+				chained = chained.With(Fields{"layer_" + strconv.Itoa(i): i, "goroutine": g})
+			}
+			chained.Info("chained")
+		}(g)
+	}
+	wg.Wait()
+	parent.Info("parent")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 10)
+	for _, entry := range entries {
+		assert.Equal(t, "1", entry["request_id"])
+		if entry["message"] == "parent" {
+			assert.Equal(t, "default", entry["namespace"])
+			assert.NotContains(t, entry, "layer_0")
+			continue
+		}
+		assert.Equal(t, "handler", entry["namespace"])
+		assert.Equal(t, float64(19), entry["layer_19"])
+	}
+
+	assert.Equal(t, Fields{"service": "testing", "namespace": "default", "request_id": "1"}, parent.GetFields())
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func (l loggerImpl) WithLazy(fn func() Fields) Logger {
+	if fn == nil {
+		return l
+	}
+
+	// Full slice expression forces a copy on append, so siblings never share lazy functions
+	l.lazy = append(l.lazy[:len(l.lazy):len(l.lazy)], fn)
+
+	return l
+}
+
+// logLazy replaces sugared methods when lazy fields are attached.
+// Lazy functions are called only after the entry passed the level check
+// and their fields are added to the entry after the regular ones.
+func (l loggerImpl) logLazy(level zapcore.Level, template string, args []interface{}) {
+	// Skips the level method calling logLazy
+	base := l.prepare().Desugar().WithOptions(zap.AddCallerSkip(1))
+
+	// Panic and fatal entries are always checked, like sugared logger does
+	if level < zapcore.DPanicLevel && !base.Core().Enabled(level) {
+		return
+	}
+
+	ce := base.Check(level, getMessage(template, args))
+	if ce == nil {
+		return
+	}
+
+	fields, collision := lazyFields(l.lazy, l.collisionPolicy())
+	l.reportCollision(collision)
+	ce.Write(fields...)
+}
+
+// lazyFields calls lazy functions and converts their fields, returns the first key colliding like zapFields
+func lazyFields(lazy []func() Fields, policy FieldCollisionPolicy) ([]zap.Field, string) {
+	var fields []zap.Field
+	var collision string
+	for _, fn := range lazy {
+		resolved, fnCollision := fn().zapFields(policy)
+		fields = append(fields, resolved...)
+		if collision == "" {
+			collision = fnCollision
+		}
+	}
+	return fields, collision
+}
+
+// getMessage formats message the same way sugared logger does
+func getMessage(template string, args []interface{}) string {
+	if len(args) == 0 {
+		return template
+	}
+
+	if template != "" {
+		return fmt.Sprintf(template, args...)
+	}
+
+	return fmt.Sprint(args...)
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_WithLazy(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service: "testing",
+		Level:   "info",
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	calls := 0
+	lazy := logger.With(Fields{"eager": 1}).WithLazy(func() Fields {
+		calls++
+		return Fields{"heavy": "computed", "service": "ignored"}
+	})
+
+	lazy.Debug("suppressed")
+	lazy.Debugf("suppressed %v", 1)
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, buf.String())
+
+	lazy.Infof("emitted %v", 1)
+	assert.Equal(t, 1, calls)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "emitted 1", entry["message"])
+	assert.Equal(t, "computed", entry["heavy"])
+	assert.Equal(t, float64(1), entry["eager"])
+	assert.Equal(t, "testing", entry["service"])
+}
+
+func TestLoggerImpl_WithLazyChained(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	parent := logger.WithLazy(func() Fields { return Fields{"first": 1} })
+	parent.WithLazy(func() Fields { return Fields{"sibling": 1} })
+	parent.WithLazy(func() Fields { return Fields{"second": 2} }).Info("chained")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, float64(1), entry["first"])
+	assert.Equal(t, float64(2), entry["second"])
+	assert.NotContains(t, entry, "sibling")
+}
+
+func TestLoggerImpl_WithLazyPanic(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Level: "fatal"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		logger.WithLazy(func() Fields { return nil }).Panic("boom")
+	})
+}
+
+func TestLoggerImpl_WithLazyNil(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	lazy := logger.WithLazy(nil)
+	assert.NotPanics(t, func() {
+		lazy.Info("emitted")
+		_ = lazy.Audit("audited", nil)
+	})
+	assert.Contains(t, buf.String(), "emitted")
+}
+
+// Lazy function must never be called for disabled entries
+func BenchmarkLoggerImpl_WithLazyDisabled(b *testing.B) {
+	logger, _ := newLogger(LoggingConfig{
+		Service: "testing",
+		Level:   "info",
+	}, zapcore.AddSync(ioutil.Discard))
+
+	calls := 0
+	lazy := logger.WithLazy(func() Fields {
+		calls++
+		return Fields{"heavy": "computed"}
+	})
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		lazy.Debug("suppressed")
+	}
+
+	if calls != 0 {
+		b.Fatalf("lazy function called %d times for disabled level", calls)
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/w84thesun/logger/logradapter
+
+go 1.18
+
+require (
+	github.com/go-logr/logr v1.4.2
+	github.com/stretchr/testify v1.6.1
+	github.com/w84thesun/logger v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
+
+replace github.com/w84thesun/logger => ../
@@ -0,0 +1,111 @@
+// Package logradapter provides logr.LogSink on top of logger.Logger,
+// e.g. for controller-runtime and other Kubernetes libraries.
+package logradapter
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+
+	"github.com/w84thesun/logger"
+)
+
+// Field holding names passed to WithName, joined with dots
+const NameKey = "logger"
+
+type sink struct {
+	base logger.Logger
+	name string
+}
+
+var _ logr.CallDepthLogSink = &sink{}
+
+// Translates V(0) to info and V(1) and higher to debug level.
+// Caller field points to the code calling logr.Logger, helpers can skip their own frames with WithCallDepth.
+func NewLogrSink(l logger.Logger) logr.LogSink {
+	// Skips the method of the sink, Init adds frames of logr.Logger
+	return &sink{base: l.WithCallerSkip(1)}
+}
+
+// Shortcut for logr.New(NewLogrSink(l))
+func NewLogr(l logger.Logger) logr.Logger {
+	return logr.New(NewLogrSink(l))
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.base = s.base.WithCallerSkip(info.CallDepth)
+}
+
+func (s *sink) Enabled(level int) bool {
+	return s.base.Enabled(vLevel(level))
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...interface{}) {
+	l := s.base.With(toFields(keysAndValues))
+
+	if level > 0 {
+		l.Debug(msg)
+		return
+	}
+	l.Info(msg)
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...interface{}) {
+	fields := toFields(keysAndValues)
+	if err != nil {
+		// Error values are encoded by zap as "error" and, for errors with stack, "errorVerbose"
+		fields["error"] = err
+	}
+
+	s.base.With(fields).Error(msg)
+}
+
+func (s *sink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	c := *s
+	c.base = c.base.With(toFields(keysAndValues))
+	return &c
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	if s.name != "" {
+		name = s.name + "." + name
+	}
+
+	c := *s
+	c.name = name
+	c.base = c.base.With(logger.Fields{NameKey: name})
+	return &c
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	c := *s
+	c.base = c.base.WithCallerSkip(depth)
+	return &c
+}
+
+func vLevel(level int) string {
+	if level > 0 {
+		return "debug"
+	}
+	return "info"
+}
+
+// toFields converts logr key-value pairs, dangling key gets nil value
+func toFields(keysAndValues []interface{}) logger.Fields {
+	fields := make(logger.Fields, len(keysAndValues)/2+1)
+
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		var value interface{}
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fields[key] = value
+	}
+
+	return fields
+}
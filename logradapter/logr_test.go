@@ -0,0 +1,100 @@
+package logradapter
+
+import (
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T, level string) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: level})
+}
+
+func TestSink_Levels(t *testing.T) {
+	l, entries := newTestLogger(t, "info")
+	log := NewLogr(l)
+
+	assert.True(t, log.Enabled())
+	assert.False(t, log.V(1).Enabled())
+
+	log.Info("info", "key", "value")
+	log.V(1).Info("debug chatter")
+	log.V(2).Info("more debug chatter")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "info", got[0]["message"])
+	assert.Equal(t, "value", got[0]["key"])
+}
+
+func TestSink_Debug(t *testing.T) {
+	l, entries := newTestLogger(t, "debug")
+	log := NewLogr(l)
+
+	assert.True(t, log.V(3).Enabled())
+	log.V(3).Info("debug chatter")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "debug", got[0]["level"])
+}
+
+func TestSink_WithValuesAndName(t *testing.T) {
+	l, entries := newTestLogger(t, "info")
+	log := NewLogr(l).WithName("controller").WithValues("reconciler", "pods").WithName("pod")
+
+	log.Info("reconciled", "dangling")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "controller.pod", got[0][NameKey])
+	assert.Equal(t, "pods", got[0]["reconciler"])
+	assert.Equal(t, "default", got[0]["namespace"])
+	assert.Contains(t, got[0], "dangling")
+	assert.Nil(t, got[0]["dangling"])
+}
+
+func TestSink_Error(t *testing.T) {
+	l, entries := newTestLogger(t, "error")
+	log := NewLogr(l)
+
+	log.Info("suppressed")
+	log.Error(errors.New("boom"), "reconcile failed", "attempt", 3)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "error", got[0]["level"])
+	assert.Equal(t, "reconcile failed", got[0]["message"])
+	assert.Equal(t, "boom", got[0]["error"])
+	assert.Equal(t, float64(3), got[0]["attempt"])
+}
+
+func TestSink_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "info", Caller: true})
+	log := NewLogr(l).WithName("controller").WithValues("key", "value")
+
+	log.Info("direct")
+	_, _, direct, _ := runtime.Caller(0)
+	// Helpers skip their own frames with WithCallDepth
+	helper := func(err error) {
+		log.WithCallDepth(1).Error(err, "helper")
+	}
+	helper(errors.New("boom"))
+	_, _, outer, _ := runtime.Caller(0)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "logr_test.go:"+strconv.Itoa(direct-1)), got[0]["caller"])
+	assert.True(t, strings.HasSuffix(got[1]["caller"].(string), "logr_test.go:"+strconv.Itoa(outer-1)), got[1]["caller"])
+	assert.Equal(t, "controller", got[1][NameKey])
+}
@@ -0,0 +1,93 @@
+package logger_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// Logstash output is tested against logtest.FakeLogstash, which imports the package, hence logger_test package
+
+func newLogstashLogger(t *testing.T, config logger.LoggingConfig) logger.Logger {
+	config.Service = "testing"
+	config.Level = "info"
+	config.DisableStdout = true
+	log, err := logger.New(config)
+	require.NoError(t, err)
+	return log
+}
+
+func TestSinkHealthy_DroppedConnection(t *testing.T) {
+	fake := logtest.NewFakeLogstash(t, "tcp")
+	log := newLogstashLogger(t, logger.LoggingConfig{LogstashURI: fake.Addr(), LogstashProtocol: "tcp"})
+	defer log.Close()
+
+	log.Info("delivered")
+	assert.True(t, log.Namespace("derived").SinkHealthy())
+	require.True(t, fake.WaitFor(1, 2*time.Second))
+
+	// Writes fail once the peer resets the connection
+	fake.CloseConnections()
+	deadline := time.Now().Add(2 * time.Second)
+	for log.SinkHealthy() && time.Now().Before(deadline) {
+		log.Info("lost")
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, log.SinkHealthy())
+}
+
+func TestSinkHealthy_Closed(t *testing.T) {
+	fake := logtest.NewFakeLogstash(t, "udp")
+	log := newLogstashLogger(t, logger.LoggingConfig{LogstashURI: fake.Addr(), LogstashProtocol: "udp"})
+
+	log.Info("delivered")
+	assert.True(t, log.SinkHealthy())
+	assert.True(t, log.Stats().Sinks[logger.OutputLogstash].Connected)
+	assert.True(t, fake.WaitFor(1, 2*time.Second))
+
+	require.NoError(t, log.Close())
+	assert.False(t, log.SinkHealthy())
+	assert.False(t, log.Stats().Sinks[logger.OutputLogstash].Connected)
+}
+
+func TestBatch_Logstash(t *testing.T) {
+	fake := logtest.NewFakeLogstash(t, "tcp")
+	log := newLogstashLogger(t, logger.LoggingConfig{
+		LogstashURI:           fake.Addr(),
+		LogstashProtocol:      "tcp",
+		LogstashBatchInterval: time.Hour,
+	})
+	defer log.Close()
+
+	log.Info("first")
+	log.Info("second")
+	assert.False(t, fake.WaitFor(1, 50*time.Millisecond), "entry received before sync")
+
+	require.NoError(t, log.Sync())
+	require.True(t, fake.WaitFor(2, 2*time.Second))
+	for i, message := range []string{"first", "second"} {
+		assert.Equal(t, message, fake.Messages()[i]["message"])
+	}
+}
+
+func TestFanOut_Logstash(t *testing.T) {
+	fakes := []*logtest.FakeLogstash{logtest.NewFakeLogstash(t, "tcp"), logtest.NewFakeLogstash(t, "tcp")}
+	log := newLogstashLogger(t, logger.LoggingConfig{
+		LogstashURI:      strings.Join([]string{fakes[0].Addr(), fakes[1].Addr()}, ", "),
+		LogstashProtocol: "tcp",
+	})
+
+	log.Info("fanned out")
+	require.NoError(t, log.Close())
+
+	for _, fake := range fakes {
+		require.True(t, fake.WaitFor(1, 2*time.Second))
+		assert.Equal(t, "fanned out", fake.Messages()[0]["message"])
+	}
+}
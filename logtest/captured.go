@@ -0,0 +1,66 @@
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/w84thesun/logger"
+)
+
+// NewCaptured returns logger built by logger.New from the config with stdout output written as JSON into memory,
+// and a function returning entries written so far decoded, e.g. for tests of adapters checking fields.
+// The logger is closed when the test finishes.
+func NewCaptured(t testing.TB, config logger.LoggingConfig) (logger.Logger, func() []map[string]interface{}) {
+	t.Helper()
+
+	out := &capturedOutput{}
+	config.StdoutWriter = out
+	config.FormatStdout = logger.FormatJSON
+	l, err := logger.New(config)
+	if err != nil {
+		t.Fatalf("invalid logger config: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	return l, func() []map[string]interface{} {
+		t.Helper()
+		return out.entries(t)
+	}
+}
+
+// capturedOutput keeps written entries, it's synchronized on its own since entries are read concurrently
+type capturedOutput struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (o *capturedOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.buf.Write(p)
+}
+
+func (o *capturedOutput) Sync() error {
+	return nil
+}
+
+func (o *capturedOutput) entries(t testing.TB) []map[string]interface{} {
+	o.mu.Lock()
+	data := append([]byte(nil), o.buf.Bytes()...)
+	o.mu.Unlock()
+
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("invalid entry %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
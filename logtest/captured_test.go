@@ -0,0 +1,23 @@
+package logtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+func TestNewCaptured(t *testing.T) {
+	log, entries := NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "info", FormatStdout: logger.FormatPretty})
+
+	log.Debug("filtered by level")
+	log.With(logger.Fields{"order_id": 42}).Info("captured")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "captured", got[0]["message"])
+	assert.Equal(t, "testing", got[0]["service"])
+	assert.Equal(t, float64(42), got[0]["order_id"])
+}
@@ -0,0 +1,11 @@
+//go:build go1.20
+// +build go1.20
+
+package logtest
+
+import "context"
+
+// contextCause returns the cause of the done context like WithContextError of the logger does
+func contextCause(ctx context.Context) error {
+	return context.Cause(ctx)
+}
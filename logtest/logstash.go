@@ -0,0 +1,284 @@
+package logtest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeLogstash is logstash server for tests of the logstash output: it listens on an ephemeral local port,
+// decodes newline-delimited JSON entries and injects failures to exercise reconnects and timeouts.
+// It's closed when the test finishes.
+type FakeLogstash struct {
+	t       testing.TB
+	network string
+
+	// Either of them depending on the network
+	listener net.Listener
+	packet   net.PacketConn
+
+	mu       sync.Mutex
+	messages []map[string]interface{}
+	conns    map[net.Conn]struct{}
+	refuse   bool
+	// Closed and replaced on every message, so WaitFor wakes up
+	received chan struct{}
+	// Not nil while reads are stalled, closed to resume them
+	stalled chan struct{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewFakeLogstash starts fake logstash listening on 127.0.0.1 with the network, "tcp" or "udp".
+// Pass Addr as LogstashURI and the network as LogstashProtocol of the logger config.
+func NewFakeLogstash(t testing.TB, network string) *FakeLogstash {
+	t.Helper()
+
+	f := &FakeLogstash{
+		t:        t,
+		network:  network,
+		conns:    map[net.Conn]struct{}{},
+		received: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	switch network {
+	case "tcp":
+		listener, err := net.Listen(network, "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("fake logstash: %v", err)
+		}
+		f.listener = listener
+		f.wg.Add(1)
+		go f.accept()
+	case "udp":
+		packet, err := net.ListenPacket(network, "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("fake logstash: %v", err)
+		}
+		f.packet = packet
+		f.wg.Add(1)
+		go f.readPackets()
+	default:
+		t.Fatalf("fake logstash: unsupported network %q, must be tcp or udp", network)
+	}
+
+	t.Cleanup(f.Close)
+	return f
+}
+
+// Addr returns host:port the fake listens on
+func (f *FakeLogstash) Addr() string {
+	if f.listener != nil {
+		return f.listener.Addr().String()
+	}
+	return f.packet.LocalAddr().String()
+}
+
+// Messages returns entries received so far in order
+func (f *FakeLogstash) Messages() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]interface{}(nil), f.messages...)
+}
+
+// WaitFor waits until at least n entries are received, reports whether they are before the timeout
+func (f *FakeLogstash) WaitFor(n int, timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		f.mu.Lock()
+		count, received := len(f.messages), f.received
+		f.mu.Unlock()
+		if count >= n {
+			return true
+		}
+
+		select {
+		case <-received:
+		case <-timer.C:
+			return false
+		}
+	}
+}
+
+// RefuseConnections makes the fake close new tcp connections right after accepting them
+// and drop udp datagrams while refuse is set
+func (f *FakeLogstash) RefuseConnections(refuse bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refuse = refuse
+}
+
+// StallReads stops reading while stall is set: tcp writes block once socket buffers fill up
+// and udp datagrams wait in the socket buffer. Data isn't lost, it's read once reads resume.
+func (f *FakeLogstash) StallReads(stall bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case stall && f.stalled == nil:
+		f.stalled = make(chan struct{})
+	case !stall && f.stalled != nil:
+		close(f.stalled)
+		f.stalled = nil
+	}
+}
+
+// CloseConnections closes accepted tcp connections mid-stream, new connections are still accepted
+func (f *FakeLogstash) CloseConnections() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for conn := range f.conns {
+		_ = conn.Close()
+		delete(f.conns, conn)
+	}
+}
+
+// Close stops the fake and waits for its goroutines, called by t.Cleanup. Calling it more than once is no-op.
+func (f *FakeLogstash) Close() {
+	f.closeOnce.Do(func() {
+		close(f.done)
+		if f.listener != nil {
+			_ = f.listener.Close()
+		} else {
+			_ = f.packet.Close()
+		}
+		f.CloseConnections()
+		f.wg.Wait()
+	})
+}
+
+func (f *FakeLogstash) accept() {
+	defer f.wg.Done()
+
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		// Connections accepted once Close started aren't closed by it, so they aren't read
+		f.mu.Lock()
+		select {
+		case <-f.done:
+			f.mu.Unlock()
+			_ = conn.Close()
+			return
+		default:
+		}
+		if f.refuse {
+			f.mu.Unlock()
+			_ = conn.Close()
+			continue
+		}
+		f.conns[conn] = struct{}{}
+		f.mu.Unlock()
+
+		f.wg.Add(1)
+		go f.read(conn)
+	}
+}
+
+func (f *FakeLogstash) read(conn net.Conn) {
+	defer f.wg.Done()
+	defer func() {
+		f.mu.Lock()
+		delete(f.conns, conn)
+		f.mu.Unlock()
+		_ = conn.Close()
+	}()
+
+	reader := bufio.NewReader(stallingReader{f: f, r: conn})
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// Partial line of the connection closed mid-stream
+			return
+		}
+		f.decode(line)
+	}
+}
+
+func (f *FakeLogstash) readPackets() {
+	defer f.wg.Done()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, _, err := f.packet.ReadFrom(buf)
+		if err != nil || !f.waitUnstalled() {
+			return
+		}
+
+		f.mu.Lock()
+		refuse := f.refuse
+		f.mu.Unlock()
+		if refuse {
+			continue
+		}
+		for _, line := range bytes.Split(buf[:n], []byte("\n")) {
+			f.decode(line)
+		}
+	}
+}
+
+// decode records the JSON entry, empty lines are skipped
+func (f *FakeLogstash) decode(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+
+	var message map[string]interface{}
+	if err := json.Unmarshal(line, &message); err != nil {
+		f.t.Errorf("fake logstash: invalid entry %q: %v", line, err)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.messages = append(f.messages, message)
+	close(f.received)
+	f.received = make(chan struct{})
+}
+
+// waitUnstalled waits while reads are stalled, returns false if the fake is closed meanwhile
+func (f *FakeLogstash) waitUnstalled() bool {
+	f.mu.Lock()
+	stalled := f.stalled
+	f.mu.Unlock()
+	if stalled == nil {
+		return true
+	}
+
+	select {
+	case <-stalled:
+		return true
+	case <-f.done:
+		return false
+	}
+}
+
+// stallingReader holds data read from the connection while reads are stalled, including a read in progress
+// when they were stalled
+type stallingReader struct {
+	f *FakeLogstash
+	r io.Reader
+}
+
+func (r stallingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if !r.f.waitUnstalled() {
+		return 0, io.EOF
+	}
+	return n, err
+}
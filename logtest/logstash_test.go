@@ -0,0 +1,121 @@
+package logtest
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+func newLogstashLogger(t *testing.T, fake *FakeLogstash, network string) logger.Logger {
+	log, err := logger.New(logger.LoggingConfig{
+		Service:          "testing",
+		Level:            "info",
+		DisableStdout:    true,
+		LogstashURI:      fake.Addr(),
+		LogstashProtocol: network,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = log.Close() })
+	return log
+}
+
+func TestFakeLogstash(t *testing.T) {
+	for _, network := range []string{"tcp", "udp"} {
+		t.Run(network, func(t *testing.T) {
+			fake := NewFakeLogstash(t, network)
+			log := newLogstashLogger(t, fake, network)
+
+			log.Info("first")
+			log.With(logger.Fields{"attempt": 2}).Warn("second")
+			require.NoError(t, log.Sync())
+
+			require.True(t, fake.WaitFor(2, 2*time.Second))
+			messages := fake.Messages()
+			require.Len(t, messages, 2)
+			assert.Equal(t, "first", messages[0]["message"])
+			assert.Equal(t, "testing", messages[0]["service"])
+			assert.Equal(t, float64(2), messages[1]["attempt"])
+		})
+	}
+}
+
+func TestFakeLogstash_StallReads(t *testing.T) {
+	fake := NewFakeLogstash(t, "tcp")
+	log := newLogstashLogger(t, fake, "tcp")
+
+	fake.StallReads(true)
+	log.Info("stalled")
+	require.NoError(t, log.Sync())
+	assert.False(t, fake.WaitFor(1, 50*time.Millisecond))
+
+	fake.StallReads(false)
+	assert.True(t, fake.WaitFor(1, 2*time.Second))
+}
+
+func TestFakeLogstash_RefuseConnections(t *testing.T) {
+	fake := NewFakeLogstash(t, "tcp")
+	fake.RefuseConnections(true)
+	log := newLogstashLogger(t, fake, "tcp")
+
+	// Connection is closed by the peer, so writes fail
+	deadline := time.Now().Add(2 * time.Second)
+	for log.SinkHealthy() && time.Now().Before(deadline) {
+		log.Info("refused")
+		_ = log.Sync()
+		time.Sleep(time.Millisecond)
+	}
+	assert.False(t, log.SinkHealthy())
+	assert.Empty(t, fake.Messages())
+}
+
+func TestFakeLogstash_CloseIdempotent(t *testing.T) {
+	fake := NewFakeLogstash(t, "udp")
+	fake.StallReads(true)
+	fake.Close()
+	fake.Close()
+}
+
+// Connections accepted while Close runs don't keep it waiting for their reads
+func TestFakeLogstash_CloseWhileConnecting(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		fake := NewFakeLogstash(t, "tcp")
+
+		stop := make(chan struct{})
+		dialed := make(chan struct{})
+		go func() {
+			defer close(dialed)
+			var conns []net.Conn
+			for {
+				select {
+				case <-stop:
+					for _, conn := range conns {
+						_ = conn.Close()
+					}
+					return
+				default:
+				}
+				if conn, err := net.Dial("tcp", fake.Addr()); err == nil {
+					conns = append(conns, conn)
+				}
+			}
+		}()
+
+		closed := make(chan struct{})
+		go func() {
+			fake.Close()
+			close(closed)
+		}()
+		select {
+		case <-closed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Close is blocked by accepted connection")
+		}
+		close(stop)
+		<-dialed
+	}
+}
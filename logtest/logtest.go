@@ -0,0 +1,268 @@
+// Package logtest asserts entries captured by Record of logger.Logger, so tests check log behavior with matchers
+// instead of grepping the output:
+//
+//	stop := log.Record()
+//	svc.Charge(ctx, order)
+//	logtest.AssertLogged(t, stop(), logtest.Level("error"), logtest.MessageContains("timeout"),
+//		logtest.Field("namespace", "billing"))
+package logtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/w84thesun/logger"
+)
+
+// Entries printed by failed assertions as the closest ones
+const closestEntries = 3
+
+// Matcher matches recorded entries, matchers are combined with All, Any and Not
+type Matcher struct {
+	desc  string
+	match func(entry logger.Entry) bool
+}
+
+// Match reports whether the entry matches
+func (m Matcher) Match(entry logger.Entry) bool {
+	return m.match(entry)
+}
+
+func (m Matcher) String() string {
+	return m.desc
+}
+
+// Matches entries of the level, e.g. "error"
+func Level(level string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("level %s", level),
+		match: func(entry logger.Entry) bool { return entry.Level == level },
+	}
+}
+
+// Matches entries with the message
+func Message(message string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("message %q", message),
+		match: func(entry logger.Entry) bool { return entry.Message == message },
+	}
+}
+
+// Matches entries whose message contains the substring
+func MessageContains(substr string) Matcher {
+	return Matcher{
+		desc:  fmt.Sprintf("message containing %q", substr),
+		match: func(entry logger.Entry) bool { return strings.Contains(entry.Message, substr) },
+	}
+}
+
+// Matches entries with the field equal to the value. Numbers of any type are equal if their values are,
+// since recorded integers are int64 and floats are float64.
+func Field(key string, value interface{}) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("field %s=%v", key, value),
+		match: func(entry logger.Entry) bool {
+			got, ok := entry.Fields[key]
+			return ok && equal(value, got)
+		},
+	}
+}
+
+// Matches entries with the field of any value
+func HasField(key string) Matcher {
+	return Matcher{
+		desc: fmt.Sprintf("field %s", key),
+		match: func(entry logger.Entry) bool {
+			_, ok := entry.Fields[key]
+			return ok
+		},
+	}
+}
+
+// Matches entries matching all of the matchers
+func All(matchers ...Matcher) Matcher {
+	return Matcher{
+		desc: join(matchers, " and "),
+		match: func(entry logger.Entry) bool {
+			for _, m := range matchers {
+				if !m.Match(entry) {
+					return false
+				}
+			}
+			return true
+		},
+	}
+}
+
+// Matches entries matching any of the matchers
+func Any(matchers ...Matcher) Matcher {
+	return Matcher{
+		desc: "(" + join(matchers, " or ") + ")",
+		match: func(entry logger.Entry) bool {
+			for _, m := range matchers {
+				if m.Match(entry) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// Matches entries not matching the matcher
+func Not(m Matcher) Matcher {
+	return Matcher{
+		desc:  "not " + m.desc,
+		match: func(entry logger.Entry) bool { return !m.Match(entry) },
+	}
+}
+
+// AssertLogged fails the test unless an entry matches all of the matchers, printing the closest entries then.
+// Returns whether the assertion succeeded.
+func AssertLogged(t testing.TB, entries []logger.Entry, matchers ...Matcher) bool {
+	t.Helper()
+
+	if index(entries, matchers, 0) >= 0 {
+		return true
+	}
+	t.Errorf("no entry with %s logged\n%s", join(matchers, ", "), closest(entries, matchers))
+	return false
+}
+
+// AssertNotLogged fails the test if an entry matches all of the matchers, printing matching entries then.
+// Returns whether the assertion succeeded.
+func AssertNotLogged(t testing.TB, entries []logger.Entry, matchers ...Matcher) bool {
+	t.Helper()
+
+	var matched []string
+	for i := index(entries, matchers, 0); i >= 0; i = index(entries, matchers, i+1) {
+		matched = append(matched, fmt.Sprintf("  #%d %s", i, format(entries[i])))
+	}
+	if len(matched) == 0 {
+		return true
+	}
+	t.Errorf("unexpected entries with %s logged:\n%s", join(matchers, ", "), strings.Join(matched, "\n"))
+	return false
+}
+
+// AssertOrdered fails the test unless an entry matching first is logged before an entry matching then.
+// Returns whether the assertion succeeded.
+func AssertOrdered(t testing.TB, entries []logger.Entry, first, then Matcher) bool {
+	t.Helper()
+
+	i := index(entries, []Matcher{first}, 0)
+	if i < 0 {
+		t.Errorf("no entry with %s logged\n%s", first, closest(entries, []Matcher{first}))
+		return false
+	}
+	if index(entries, []Matcher{then}, i+1) >= 0 {
+		return true
+	}
+
+	if j := index(entries, []Matcher{then}, 0); j >= 0 {
+		t.Errorf("entry with %s is logged before the first one with %s:\n  #%d %s\n  #%d %s",
+			then, first, j, format(entries[j]), i, format(entries[i]))
+	} else {
+		t.Errorf("no entry with %s logged\n%s", then, closest(entries, []Matcher{then}))
+	}
+	return false
+}
+
+// index returns index of the first entry from the start matching all of the matchers, -1 if there is none
+func index(entries []logger.Entry, matchers []Matcher, start int) int {
+	for i := start; i < len(entries); i++ {
+		if All(matchers...).Match(entries[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// closest formats entries matching most of the matchers with the ones they don't match
+func closest(entries []logger.Entry, matchers []Matcher) string {
+	if len(entries) == 0 {
+		return "no entries are recorded"
+	}
+
+	type candidate struct {
+		index  int
+		failed []Matcher
+	}
+	candidates := make([]candidate, 0, len(entries))
+	for i, entry := range entries {
+		c := candidate{index: i}
+		for _, m := range matchers {
+			if !m.Match(entry) {
+				c.failed = append(c.failed, m)
+			}
+		}
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i].failed) < len(candidates[j].failed)
+	})
+	if len(candidates) > closestEntries {
+		candidates = candidates[:closestEntries]
+	}
+
+	lines := []string{"closest entries:"}
+	for _, c := range candidates {
+		lines = append(lines, fmt.Sprintf("  #%d %s\n    doesn't match %s",
+			c.index, format(entries[c.index]), join(c.failed, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// format prints the entry as level, message and fields sorted by key
+func format(entry logger.Entry) string {
+	keys := make([]string, 0, len(entry.Fields))
+	for key := range entry.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q", entry.Level, entry.Message)
+	for _, key := range keys {
+		fmt.Fprintf(&b, " %s=%v", key, entry.Fields[key])
+	}
+	return b.String()
+}
+
+func join(matchers []Matcher, sep string) string {
+	descs := make([]string, len(matchers))
+	for i, m := range matchers {
+		descs[i] = m.desc
+	}
+	return strings.Join(descs, sep)
+}
+
+// equal compares values like reflect.DeepEqual, except numbers which are compared by value
+func equal(want, got interface{}) bool {
+	if reflect.DeepEqual(want, got) {
+		return true
+	}
+
+	wantNumber, ok := number(want)
+	if !ok {
+		return false
+	}
+	gotNumber, ok := number(got)
+	return ok && wantNumber == gotNumber
+}
+
+func number(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
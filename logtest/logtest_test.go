@@ -0,0 +1,83 @@
+package logtest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+// failureT records failures of assertions instead of failing the test
+type failureT struct {
+	*testing.T
+	failures []string
+}
+
+func (t *failureT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func record(t *testing.T) []logger.Entry {
+	log := NewTB(t, "info").Namespace("billing")
+	stop := log.Record()
+
+	log.Debug("retrying charge")
+	log.With(logger.Fields{"attempt": 3}).Error("charge timeout exceeded")
+	log.Info("charge cancelled")
+	return stop()
+}
+
+func TestAssertLogged(t *testing.T) {
+	entries := record(t)
+
+	assert.True(t, AssertLogged(t, entries, Level("error"), MessageContains("timeout"), Field("namespace", "billing")))
+	assert.True(t, AssertLogged(t, entries, Field("attempt", 3), HasField("namespace")))
+	assert.True(t, AssertLogged(t, entries, Any(Level("warn"), Level("debug")), Not(HasField("attempt"))))
+	assert.True(t, AssertLogged(t, entries, All(Message("charge cancelled"), Level("info"))))
+
+	ft := &failureT{T: t}
+	assert.False(t, AssertLogged(ft, entries, Level("error"), Field("attempt", 4)))
+	require.Len(t, ft.failures, 1)
+	assert.Contains(t, ft.failures[0], "no entry with level error, field attempt=4 logged")
+	// The closest entry goes first with the matchers it fails
+	assert.Contains(t, ft.failures[0], "closest entries:\n"+
+		`  #1 error "charge timeout exceeded" attempt=3 namespace=billing`+"\n"+
+		"    doesn't match field attempt=4")
+}
+
+func TestAssertNotLogged(t *testing.T) {
+	entries := record(t)
+
+	assert.True(t, AssertNotLogged(t, entries, Level("error"), MessageContains("cancelled")))
+
+	ft := &failureT{T: t}
+	assert.False(t, AssertNotLogged(ft, entries, MessageContains("charge")))
+	require.Len(t, ft.failures, 1)
+	assert.Contains(t, ft.failures[0], `#0 debug "retrying charge"`)
+	assert.Contains(t, ft.failures[0], `#2 info "charge cancelled"`)
+}
+
+func TestAssertOrdered(t *testing.T) {
+	entries := record(t)
+
+	assert.True(t, AssertOrdered(t, entries, MessageContains("retrying"), Level("error")))
+
+	ft := &failureT{T: t}
+	assert.False(t, AssertOrdered(ft, entries, Level("info"), Level("error")))
+	assert.False(t, AssertOrdered(ft, entries, Level("warn"), Level("error")))
+	assert.False(t, AssertOrdered(ft, entries, Level("error"), Level("warn")))
+	require.Len(t, ft.failures, 3)
+	assert.Contains(t, ft.failures[0], "entry with level error is logged before the first one with level info")
+	assert.Contains(t, ft.failures[1], "no entry with level warn logged")
+	assert.Contains(t, ft.failures[2], "no entry with level warn logged")
+}
+
+func TestAssertLogged_NoEntries(t *testing.T) {
+	ft := &failureT{T: t}
+	assert.False(t, AssertLogged(ft, nil, Level("error")))
+	require.Len(t, ft.failures, 1)
+	assert.Contains(t, ft.failures[0], "no entries are recorded")
+}
@@ -0,0 +1,551 @@
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/w84thesun/logger"
+	"go.uber.org/zap/zapcore"
+)
+
+// Call is an entry logged through MockLogger
+type Call struct {
+	// Method called, e.g. "Infof" or "InfoZ", methods of TypedLogger are prefixed with "Typed.", e.g. "Typed.Info"
+	Method string
+	// Level of the entry, e.g. "info"
+	Level string
+	Time  time.Time
+	// Message as the logger renders it, e.g. formatted by Infof
+	Message string
+	// Fields of the logger and of the call at the time of the call, lazy ones are computed. Values are kept
+	// as passed, except typed fields which are encoded like zap does, e.g. int64 for zap.Int.
+	Fields logger.Fields
+	// Namespace of the logger, empty if not set
+	Namespace string
+}
+
+// Configures NewMockLogger
+type MockOption func(*mockState)
+
+// ForwardTo makes the mock also log calls with l, e.g. NewTB(t, "debug") to see them with -v.
+// Panic, Fatal and Recover entries are logged at error level, so l neither panics nor exits.
+func ForwardTo(l logger.Logger) MockOption {
+	return func(s *mockState) {
+		s.forward = l
+	}
+}
+
+// MockLogger is logger.Logger recording calls instead of writing entries, for tests of code taking the logger.
+// Loggers derived by With, Namespace and similar methods record calls to the same mock, so Calls of any of them
+// returns calls of all. Panic and Fatal are recorded without panicking and exiting, Recover recovers the panic
+// and records it. All levels are enabled. Safe for concurrent use.
+type MockLogger struct {
+	state *mockState
+
+	fields    logger.Fields
+	namespace string
+	lazy      []func() logger.Fields
+}
+
+var _ logger.Logger = &MockLogger{}
+
+// mockState is shared by loggers derived from the same NewMockLogger call
+type mockState struct {
+	mu    sync.Mutex
+	calls []Call
+	// Calls by level since ResetStats, reported by Stats
+	entries map[string]uint64
+
+	forward logger.Logger
+}
+
+func NewMockLogger(opts ...MockOption) *MockLogger {
+	state := &mockState{entries: map[string]uint64{}}
+	for _, opt := range opts {
+		opt(state)
+	}
+	return &MockLogger{state: state}
+}
+
+// Calls returns calls of the level in order, calls of all levels for empty level
+func (m *MockLogger) Calls(level string) []Call {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	var calls []Call
+	for _, call := range m.state.calls {
+		if level == "" || call.Level == level {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// LastCall returns the last call, false if there are none
+func (m *MockLogger) LastCall() (Call, bool) {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	if len(m.state.calls) == 0 {
+		return Call{}, false
+	}
+	return m.state.calls[len(m.state.calls)-1], true
+}
+
+// Reset forgets recorded calls
+func (m *MockLogger) Reset() {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+	m.state.calls = nil
+}
+
+// log records the call with fields of the logger, fields of the call take precedence
+func (m *MockLogger) log(method, level, message string, fields logger.Fields) {
+	all := m.fields.Copy()
+	for _, fn := range m.lazy {
+		all = all.Merge(fn())
+	}
+	all = all.Merge(fields)
+
+	call := Call{
+		Method:    method,
+		Level:     level,
+		Time:      time.Now(),
+		Message:   message,
+		Fields:    all,
+		Namespace: m.namespace,
+	}
+
+	m.state.mu.Lock()
+	m.state.calls = append(m.state.calls, call)
+	if level != "" {
+		m.state.entries[level]++
+	}
+	forward := m.state.forward
+	m.state.mu.Unlock()
+
+	if forward != nil {
+		forwardCall(forward, call)
+	}
+}
+
+func forwardCall(l logger.Logger, call Call) {
+	if call.Namespace != "" {
+		l = l.Namespace(call.Namespace)
+	}
+	l = l.With(call.Fields)
+
+	switch call.Level {
+	case "debug":
+		l.Debug(call.Message)
+	case "info":
+		l.Info(call.Message)
+	case "warn":
+		l.Warn(call.Message)
+	default:
+		l.Error(call.Message)
+	}
+}
+
+func (m *MockLogger) Debug(message ...interface{}) {
+	m.log("Debug", "debug", fmt.Sprint(message...), nil)
+}
+
+func (m *MockLogger) Debugf(format string, args ...interface{}) {
+	m.log("Debugf", "debug", fmt.Sprintf(format, args...), nil)
+}
+
+func (m *MockLogger) Info(message ...interface{}) {
+	m.log("Info", "info", fmt.Sprint(message...), nil)
+}
+
+func (m *MockLogger) Infof(format string, args ...interface{}) {
+	m.log("Infof", "info", fmt.Sprintf(format, args...), nil)
+}
+
+func (m *MockLogger) Warn(message ...interface{}) {
+	m.log("Warn", "warn", fmt.Sprint(message...), nil)
+}
+
+func (m *MockLogger) Warnf(format string, args ...interface{}) {
+	m.log("Warnf", "warn", fmt.Sprintf(format, args...), nil)
+}
+
+func (m *MockLogger) Error(message ...interface{}) {
+	m.log("Error", "error", fmt.Sprint(message...), nil)
+}
+
+func (m *MockLogger) Errorf(format string, args ...interface{}) {
+	m.log("Errorf", "error", fmt.Sprintf(format, args...), nil)
+}
+
+func (m *MockLogger) DebugZ(msg string, fields ...logger.Field) {
+	m.log("DebugZ", "debug", msg, typedValues(fields))
+}
+
+func (m *MockLogger) InfoZ(msg string, fields ...logger.Field) {
+	m.log("InfoZ", "info", msg, typedValues(fields))
+}
+
+func (m *MockLogger) WarnZ(msg string, fields ...logger.Field) {
+	m.log("WarnZ", "warn", msg, typedValues(fields))
+}
+
+func (m *MockLogger) ErrorZ(msg string, fields ...logger.Field) {
+	m.log("ErrorZ", "error", msg, typedValues(fields))
+}
+
+// Panic is recorded without panicking
+func (m *MockLogger) Panic(message ...interface{}) {
+	m.log("Panic", "panic", fmt.Sprint(message...), nil)
+}
+
+func (m *MockLogger) Panicf(format string, args ...interface{}) {
+	m.log("Panicf", "panic", fmt.Sprintf(format, args...), nil)
+}
+
+// Fatal is recorded without exiting
+func (m *MockLogger) Fatal(message ...interface{}) {
+	m.log("Fatal", "fatal", fmt.Sprint(message...), nil)
+}
+
+func (m *MockLogger) Fatalf(format string, args ...interface{}) {
+	m.log("Fatalf", "fatal", fmt.Sprintf(format, args...), nil)
+}
+
+// derive returns copy of the mock recording to the same state
+func (m *MockLogger) derive() *MockLogger {
+	derived := *m
+	return &derived
+}
+
+func (m *MockLogger) With(fields logger.Fields) logger.Logger {
+	if len(fields) == 0 {
+		return m
+	}
+
+	derived := m.derive()
+	derived.fields = m.fields.Merge(fields)
+	return derived
+}
+
+// WithReuse is the same as With, there is nothing to build
+func (m *MockLogger) WithReuse(fields logger.Fields) logger.Logger {
+	return m.With(fields)
+}
+
+// WithLazy fields are computed on every call, since all levels are enabled
+func (m *MockLogger) WithLazy(fn func() logger.Fields) logger.Logger {
+	if fn == nil {
+		return m
+	}
+
+	derived := m.derive()
+	derived.lazy = append(m.lazy[:len(m.lazy):len(m.lazy)], fn)
+	return derived
+}
+
+func (m *MockLogger) Namespace(namespace string) logger.Logger {
+	derived := m.derive()
+	derived.namespace = namespace
+	return derived
+}
+
+func (m *MockLogger) AppendNamespace(sub string) logger.Logger {
+	if m.namespace == "" {
+		return m.Namespace(sub)
+	}
+	if sub == "" {
+		return m
+	}
+	return m.Namespace(m.namespace + logger.NamespaceSeparator + sub)
+}
+
+func (m *MockLogger) WithComponent(name string) logger.Logger {
+	if name == "" {
+		return m
+	}
+	return m.With(logger.Fields{logger.ComponentKey: name})
+}
+
+func (m *MockLogger) WithErrorClass(class string) logger.Logger {
+	if class == "" {
+		return m
+	}
+	return m.With(logger.Fields{logger.ErrorClassKey: class})
+}
+
+func (m *MockLogger) WithTenant(ctx context.Context) logger.Logger {
+	id, ok := logger.TenantFromContext(ctx)
+	if !ok {
+		return m
+	}
+	return m.With(logger.Fields{logger.TenantKey: id})
+}
+
+func (m *MockLogger) WithUser(ctx context.Context) logger.Logger {
+	id, ok := logger.UserFromContext(ctx)
+	if !ok {
+		return m
+	}
+	return m.With(logger.Fields{logger.UserKey: id})
+}
+
+func (m *MockLogger) WithMDC(ctx context.Context) logger.Logger {
+	return m.With(logger.MDCFromContext(ctx))
+}
+
+func (m *MockLogger) WithContextError(ctx context.Context) logger.Logger {
+	err := ctx.Err()
+	if err == nil {
+		return m
+	}
+	return m.With(logger.Fields{logger.ContextErrorKey: err.Error(), logger.ContextCauseKey: contextCause(ctx).Error()})
+}
+
+func (m *MockLogger) WithTTL(d time.Duration) logger.Logger {
+	if d < 0 {
+		return m
+	}
+	days := (d + 24*time.Hour - 1) / (24 * time.Hour)
+	return m.With(logger.Fields{logger.TTLKey: int64(days)})
+}
+
+// Once returns the mock unchanged, all calls are recorded, so tests see what code logs regardless of throttling
+func (m *MockLogger) Once(key string) logger.Logger {
+	return m
+}
+
+// Every returns the mock unchanged like Once
+func (m *MockLogger) Every(key string, n int) logger.Logger {
+	return m
+}
+
+// EveryDuration returns the mock unchanged like Once
+func (m *MockLogger) EveryDuration(key string, d time.Duration) logger.Logger {
+	return m
+}
+
+// WithCallerSkip returns the mock unchanged, calls are recorded without callers
+func (m *MockLogger) WithCallerSkip(n int) logger.Logger {
+	return m
+}
+
+func (m *MockLogger) Event(fields logger.Fields) {
+	m.log("Event", "info", "", fields)
+}
+
+// Trace records the message and fields of logger.DefaultStackFormatter
+func (m *MockLogger) Trace(err error) {
+	if err == nil {
+		return
+	}
+	message, fields := logger.DefaultStackFormatter.FormatStack(err)
+	m.log("Trace", "error", message, fields)
+}
+
+func (m *MockLogger) LogErr(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	m.log("LogErr", "error", msg, logger.Fields{logger.ErrorKey: err.Error()})
+	return err
+}
+
+// Recover records the panic at panic level with message of logger.DefaultRecoverFormat and PanicKey field,
+// the panic isn't propagated
+func (m *MockLogger) Recover(msg string) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	m.log("Recover", "panic", fmt.Sprintf(logger.DefaultRecoverFormat, msg, recovered),
+		logger.Fields{logger.PanicKey: fmt.Sprint(recovered)})
+}
+
+func (m *MockLogger) GetField(field string) (interface{}, bool) {
+	if field == "namespace" {
+		return m.namespace, m.namespace != ""
+	}
+	return m.fields.Get(field)
+}
+
+func (m *MockLogger) GetFields() logger.Fields {
+	fields := m.fields.Copy()
+	if m.namespace != "" {
+		fields["namespace"] = m.namespace
+	}
+	return fields
+}
+
+func (m *MockLogger) MergeFrom(other logger.Logger) logger.Logger {
+	if other == nil {
+		return m
+	}
+
+	fields := other.GetFields()
+	derived := m.derive()
+	if namespace, ok := fields["namespace"]; ok {
+		delete(fields, "namespace")
+		if m.namespace == "" {
+			derived.namespace = fmt.Sprint(namespace)
+		}
+	}
+	derived.fields = fields.Merge(m.fields)
+	return derived
+}
+
+// Enabled reports true for all valid levels
+func (m *MockLogger) Enabled(level string) bool {
+	var zapLevel zapcore.Level
+	return zapLevel.UnmarshalText([]byte(level)) == nil
+}
+
+func (m *MockLogger) Sync() error {
+	return nil
+}
+
+func (m *MockLogger) Close() error {
+	return nil
+}
+
+func (m *MockLogger) SinkHealthy() bool {
+	return true
+}
+
+// Stats reports recorded calls by level, the mock has no outputs
+func (m *MockLogger) Stats() logger.LoggerStats {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	stats := logger.LoggerStats{Entries: map[string]uint64{}, Sinks: map[string]logger.SinkStats{}}
+	for level, count := range m.state.entries {
+		stats.Entries[level] = count
+	}
+	return stats
+}
+
+func (m *MockLogger) Healthy() error {
+	return nil
+}
+
+// ResetStats zeroes counters of Stats, recorded calls are kept
+func (m *MockLogger) ResetStats() {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+	m.state.entries = map[string]uint64{}
+}
+
+func (m *MockLogger) Writer(level string) *logger.LineWriter {
+	return logger.NewLineWriter(m, level)
+}
+
+func (m *MockLogger) StdLogger(level string) *log.Logger {
+	return log.New(m.Writer(level), "", 0)
+}
+
+// EMF records metrics and dimensions as fields at info level with the namespace as message
+func (m *MockLogger) EMF(namespace string, metrics logger.Fields, dimensions logger.Fields) error {
+	m.log("EMF", "info", namespace, dimensions.Merge(metrics))
+	return nil
+}
+
+// Audit records the call at info level with AuditNamespace, invalid calls aren't recorded and return error
+// of ValidateAudit
+func (m *MockLogger) Audit(event string, fields logger.Fields) error {
+	all := m.fields.Copy()
+	for _, fn := range m.lazy {
+		all = all.Merge(fn())
+	}
+	if err := logger.ValidateAudit(event, all.Merge(fields)); err != nil {
+		return err
+	}
+
+	derived := m.derive()
+	derived.namespace = logger.AuditNamespace
+	derived.log("Audit", "info", event, fields)
+	return nil
+}
+
+func (m *MockLogger) Typed() logger.TypedLogger {
+	return mockTyped{m: m}
+}
+
+// Record returns calls logged since it was called as entries, e.g. for AssertLogged.
+// Namespace is added as "namespace" field. Calls forgotten by Reset aren't returned.
+func (m *MockLogger) Record() (stop func() []logger.Entry) {
+	m.state.mu.Lock()
+	start := len(m.state.calls)
+	m.state.mu.Unlock()
+
+	return func() []logger.Entry {
+		m.state.mu.Lock()
+		defer m.state.mu.Unlock()
+
+		if start > len(m.state.calls) {
+			start = len(m.state.calls)
+		}
+		entries := make([]logger.Entry, 0, len(m.state.calls)-start)
+		for _, call := range m.state.calls[start:] {
+			fields := call.Fields.Copy()
+			if call.Namespace != "" {
+				fields["namespace"] = call.Namespace
+			}
+			entries = append(entries, logger.Entry{Level: call.Level, Time: call.Time, Message: call.Message, Fields: fields})
+		}
+		return entries
+	}
+}
+
+// mockTyped is TypedLogger of MockLogger
+type mockTyped struct {
+	m *MockLogger
+}
+
+func (t mockTyped) Debug(msg string, fields ...logger.Field) {
+	t.m.log("Typed.Debug", "debug", msg, typedValues(fields))
+}
+
+func (t mockTyped) Info(msg string, fields ...logger.Field) {
+	t.m.log("Typed.Info", "info", msg, typedValues(fields))
+}
+
+func (t mockTyped) Warn(msg string, fields ...logger.Field) {
+	t.m.log("Typed.Warn", "warn", msg, typedValues(fields))
+}
+
+func (t mockTyped) Error(msg string, fields ...logger.Field) {
+	t.m.log("Typed.Error", "error", msg, typedValues(fields))
+}
+
+func (t mockTyped) Panic(msg string, fields ...logger.Field) {
+	t.m.log("Typed.Panic", "panic", msg, typedValues(fields))
+}
+
+func (t mockTyped) Fatal(msg string, fields ...logger.Field) {
+	t.m.log("Typed.Fatal", "fatal", msg, typedValues(fields))
+}
+
+func (t mockTyped) With(fields ...logger.Field) logger.TypedLogger {
+	return t.m.With(typedValues(fields)).Typed()
+}
+
+func (t mockTyped) Sugar() logger.Logger {
+	return t.m
+}
+
+// typedValues encodes typed fields to values like zap does
+func typedValues(fields []logger.Field) logger.Fields {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return enc.Fields
+}
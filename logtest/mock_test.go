@@ -0,0 +1,169 @@
+package logtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/w84thesun/logger"
+)
+
+func TestMockLogger(t *testing.T) {
+	mock := NewMockLogger()
+	var log logger.Logger = mock
+
+	log.Infof("charged %d", 42)
+	derived := log.With(logger.Fields{"order_id": 7}).Namespace("billing").AppendNamespace("cards")
+	derived.WithComponent("stripe").Warn("retrying", 3)
+	derived.Typed().With(zap.Int("attempt", 3)).Error("timeout", zap.String("gateway", "eu"))
+
+	// Calls of derived loggers are recorded to the root
+	calls := mock.Calls("")
+	require.Len(t, calls, 3)
+	assert.Equal(t, Call{Method: "Infof", Level: "info", Time: calls[0].Time, Message: "charged 42", Fields: logger.Fields{}},
+		calls[0])
+
+	assert.Equal(t, "Warn", calls[1].Method)
+	assert.Equal(t, "retrying3", calls[1].Message)
+	assert.Equal(t, logger.Fields{"order_id": 7, logger.ComponentKey: "stripe"}, calls[1].Fields)
+	assert.Equal(t, "billing/cards", calls[1].Namespace)
+
+	assert.Equal(t, "Typed.Error", calls[2].Method)
+	assert.Equal(t, logger.Fields{"order_id": 7, "attempt": int64(3), "gateway": "eu"}, calls[2].Fields)
+
+	assert.Equal(t, calls[1:2], mock.Calls("warn"))
+	last, ok := mock.LastCall()
+	assert.True(t, ok)
+	assert.Equal(t, "timeout", last.Message)
+
+	mock.Reset()
+	assert.Empty(t, mock.Calls(""))
+	_, ok = derived.(*MockLogger).LastCall()
+	assert.False(t, ok)
+}
+
+func TestMockLogger_FieldsAtCall(t *testing.T) {
+	mock := NewMockLogger()
+
+	calls := 0
+	log := mock.WithReuse(logger.Fields{"a": 1}).WithLazy(func() logger.Fields {
+		calls++
+		return logger.Fields{"lazy": calls}
+	})
+	log.Info("first")
+	log.With(logger.Fields{"a": 2}).InfoZ("second", zap.Bool("typed", true))
+
+	recorded := mock.Calls("info")
+	require.Len(t, recorded, 2)
+	assert.Equal(t, logger.Fields{"a": 1, "lazy": 1}, recorded[0].Fields)
+	assert.Equal(t, logger.Fields{"a": 2, "lazy": 2, "typed": true}, recorded[1].Fields)
+
+	value, ok := log.GetField("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+}
+
+func TestMockLogger_PanicFatal(t *testing.T) {
+	mock := NewMockLogger()
+
+	assert.NotPanics(t, func() {
+		mock.Panic("broken")
+		mock.Fatalf("exiting %d", 1)
+	})
+	func() {
+		defer mock.Recover("handler")
+		panic("nil map")
+	}()
+
+	calls := mock.Calls("")
+	require.Len(t, calls, 3)
+	assert.Equal(t, "panic", calls[0].Level)
+	assert.Equal(t, "fatal", calls[1].Level)
+	assert.Equal(t, "exiting 1", calls[1].Message)
+	assert.Equal(t, "Recover", calls[2].Method)
+	assert.Equal(t, "recovered handler from nil map", calls[2].Message)
+	assert.Equal(t, "nil map", calls[2].Fields[logger.PanicKey])
+}
+
+func TestMockLogger_Context(t *testing.T) {
+	mock := NewMockLogger()
+
+	ctx := logger.ContextWithTenant(context.Background(), "acme")
+	ctx = logger.PushField(ctx, "request_id", "r1")
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	log := mock.WithTenant(ctx).WithUser(ctx).WithMDC(ctx).WithContextError(ctx)
+	assert.NoError(t, log.LogErr(nil, "ignored"))
+	assert.Error(t, log.LogErr(errors.New("declined"), "charge failed"))
+
+	last, ok := mock.LastCall()
+	require.True(t, ok)
+	assert.Equal(t, logger.Fields{
+		logger.TenantKey:       "acme",
+		"request_id":           "r1",
+		logger.ContextErrorKey: "context canceled",
+		logger.ContextCauseKey: "context canceled",
+		logger.ErrorKey:        "declined",
+	}, last.Fields)
+	assert.Len(t, mock.Calls(""), 1)
+}
+
+func TestMockLogger_Record(t *testing.T) {
+	mock := NewMockLogger()
+	mock.Info("before")
+
+	stop := mock.Record()
+	mock.Namespace("billing").Error("charge timeout exceeded")
+	mock.StdLogger("warn").Print("from std logger")
+
+	entries := stop()
+	require.Len(t, entries, 2)
+	AssertLogged(t, entries, Level("error"), Field("namespace", "billing"))
+	AssertLogged(t, entries, Level("warn"), Message("from std logger"))
+	AssertNotLogged(t, entries, Message("before"))
+}
+
+func TestMockLogger_ForwardTo(t *testing.T) {
+	forward := NewTB(t, "debug")
+	stop := forward.Record()
+
+	mock := NewMockLogger(ForwardTo(forward))
+	mock.Namespace("billing").With(logger.Fields{"order_id": 7}).Info("charged")
+	mock.Fatal("not exiting")
+
+	entries := stop()
+	require.Len(t, entries, 2)
+	AssertLogged(t, entries, Level("info"), Message("charged"), Field("order_id", 7), Field("namespace", "billing"))
+	AssertLogged(t, entries, Level("error"), Message("not exiting"))
+	assert.Len(t, mock.Calls(""), 2)
+}
+
+func TestMockLogger_Enabled(t *testing.T) {
+	mock := NewMockLogger()
+	assert.True(t, mock.Enabled("debug"))
+	assert.True(t, mock.Enabled("fatal"))
+	assert.False(t, mock.Enabled("loud"))
+}
+
+func TestMockLogger_Audit(t *testing.T) {
+	mock := NewMockLogger()
+	admin := mock.With(logger.Fields{logger.AuditActorKey: "admin"})
+
+	require.NoError(t, admin.Audit("payout approved", logger.Fields{
+		logger.AuditActionKey: "approve",
+		logger.AuditTargetKey: "payout/42",
+	}))
+	assert.Error(t, admin.Audit("payout approved", logger.Fields{logger.AuditActionKey: "approve"}))
+
+	calls := mock.Calls("")
+	require.Len(t, calls, 1)
+	assert.Equal(t, "Audit", calls[0].Method)
+	assert.Equal(t, "payout approved", calls[0].Message)
+	assert.Equal(t, logger.AuditNamespace, calls[0].Namespace)
+	assert.Equal(t, "admin", calls[0].Fields[logger.AuditActorKey])
+}
@@ -0,0 +1,178 @@
+package logtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/w84thesun/logger"
+)
+
+// Configures NewTB
+type TBOption func(*tbOptions)
+
+type tbOptions struct {
+	panics bool
+}
+
+// Makes Panic actually panic after logging instead of failing the test
+func TBPanics() TBOption {
+	return func(o *tbOptions) {
+		o.panics = true
+	}
+}
+
+// NewTB returns logger printing entries with t.Logf, so they are attributed to the test
+// and shown only if it fails or -v is set. Entries are formatted by the pretty encoder
+// with fields appended as sorted key=value pairs.
+// Fatal fails the test with t.Fatalf instead of exiting, Panic fails it the same way unless TBPanics is set.
+// Entries logged after the test has finished are dropped.
+func NewTB(t testing.TB, level string, opts ...TBOption) logger.Logger {
+	t.Helper()
+
+	var o tbOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	zapLevel, err := logger.ParseLevel(level)
+	if err != nil {
+		t.Fatalf("invalid logger level: %v", err)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.MessageKey = "message"
+	// t.Logf prints its own location and go test output has no use of timestamps
+	encoderConfig.TimeKey = ""
+	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+
+	core := &tbCore{
+		LevelEnabler: zapLevel,
+		encoder:      zapcore.NewConsoleEncoder(encoderConfig),
+		out:          newTBOutput(t),
+		panics:       o.panics,
+	}
+
+	// In case the test has finished and fatal entry wasn't turned into t.Fatalf
+	return logger.NewWithCore(core, zap.OnFatal(zapcore.WriteThenGoexit))
+}
+
+// tbOutput guards t from being used after the test has finished, which panics
+type tbOutput struct {
+	t testing.TB
+
+	mu   sync.Mutex
+	done bool
+}
+
+func newTBOutput(t testing.TB) *tbOutput {
+	out := &tbOutput{t: t}
+	t.Cleanup(func() {
+		out.mu.Lock()
+		out.done = true
+		out.mu.Unlock()
+	})
+	return out
+}
+
+// print logs the line, fail makes it fail the test and stop its goroutine
+func (o *tbOutput) print(line string, fail bool) {
+	o.mu.Lock()
+	if o.done {
+		o.mu.Unlock()
+		return
+	}
+
+	if !fail {
+		o.t.Log(line)
+		o.mu.Unlock()
+		return
+	}
+
+	o.t.Error(line)
+	o.mu.Unlock()
+	o.t.FailNow()
+}
+
+// tbCore writes pretty entries with key=value fields into the test output.
+// Context fields are kept unencoded to be printed sorted together with the entry ones.
+type tbCore struct {
+	zapcore.LevelEnabler
+
+	encoder zapcore.Encoder
+	out     *tbOutput
+	context []zapcore.Field
+	panics  bool
+}
+
+func (c *tbCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+
+	clone := *c
+	clone.context = context
+	return &clone
+}
+
+func (c *tbCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *tbCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.encoder.EncodeEntry(ent, nil)
+	if err != nil {
+		return err
+	}
+	line := strings.TrimSuffix(buf.String(), "\n")
+	buf.Free()
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range c.context {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(line)
+	for _, key := range keys {
+		b.WriteString(" ")
+		b.WriteString(key)
+		b.WriteString("=")
+		b.WriteString(formatTBValue(enc.Fields[key]))
+	}
+
+	fail := ent.Level == zapcore.FatalLevel || (ent.Level >= zapcore.DPanicLevel && !c.panics)
+	c.out.print(b.String(), fail)
+
+	return nil
+}
+
+func (c *tbCore) Sync() error {
+	return nil
+}
+
+// formatTBValue quotes strings with spaces, so pairs remain distinguishable
+func formatTBValue(value interface{}) string {
+	s := fmt.Sprint(value)
+	if _, ok := value.(string); ok && strings.ContainsAny(s, " =\t\n") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
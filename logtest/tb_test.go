@@ -0,0 +1,133 @@
+package logtest
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+// fakeTB records output of the code under test instead of failing the real test
+type fakeTB struct {
+	testing.TB
+
+	mu       sync.Mutex
+	lines    []string
+	failed   bool
+	cleanups []func()
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Log(args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lines = append(t.lines, args[0].(string))
+}
+
+func (t *fakeTB) Error(args ...interface{}) {
+	t.Log(args...)
+	t.mu.Lock()
+	t.failed = true
+	t.mu.Unlock()
+}
+
+func (t *fakeTB) FailNow() {
+	t.mu.Lock()
+	t.failed = true
+	t.mu.Unlock()
+	runtime.Goexit()
+}
+
+func (t *fakeTB) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+// finish runs cleanups like the testing package does after the test
+func (t *fakeTB) finish() {
+	for _, fn := range t.cleanups {
+		fn()
+	}
+}
+
+// run calls fn in its own goroutine, so FailNow stops only it like it stops the test goroutine
+func (t *fakeTB) run(fn func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	<-done
+}
+
+func TestNewTB(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := NewTB(tb, "info")
+
+	log.Debug("filtered by level")
+	log.Namespace("orders").With(logger.Fields{"id": 42, "status": "in progress"}).Info("updated")
+	log.With(logger.Bool("cached", true)).Warnf("took %dms", 10)
+
+	assert.Equal(t, []string{
+		`INFO	updated id=42 namespace=orders status="in progress"`,
+		`WARN	took 10ms cached=true`,
+	}, tb.lines)
+	assert.False(t, tb.failed)
+}
+
+func TestNewTB_Fatal(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := NewTB(tb, "debug")
+
+	reached := false
+	tb.run(func() {
+		log.Fatal("no config")
+		reached = true
+	})
+
+	assert.False(t, reached)
+	assert.True(t, tb.failed)
+	assert.Equal(t, []string{"FATAL	no config"}, tb.lines)
+}
+
+func TestNewTB_Panic(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := NewTB(tb, "debug")
+
+	tb.run(func() {
+		log.Panic("bad state")
+	})
+	assert.True(t, tb.failed)
+	assert.Equal(t, []string{"PANIC	bad state"}, tb.lines)
+
+	tb = &fakeTB{TB: t}
+	log = NewTB(tb, "debug", TBPanics())
+	assert.PanicsWithValue(t, "bad state", func() {
+		log.Panic("bad state")
+	})
+	assert.False(t, tb.failed)
+}
+
+func TestNewTB_AfterFinish(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	log := NewTB(tb, "debug")
+
+	log.Info("during test")
+	tb.finish()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		log.Trace(errors.New("late"))
+	}()
+	wg.Wait()
+
+	require.Len(t, tb.lines, 1)
+	assert.Equal(t, "INFO	during test", tb.lines[0])
+}
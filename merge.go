@@ -0,0 +1,38 @@
+package logger
+
+func (l loggerImpl) GetFields() Fields {
+	general := l.general().fields
+	merged := l.fields.fields()
+	fields := make(Fields, len(general)+len(merged)+1)
+	for k, v := range general {
+		fields[k] = v
+	}
+	for k, v := range merged {
+		if l.encodesField(k) {
+			fields[k] = fieldValue(v)
+		}
+	}
+	if l.namespace != nil {
+		fields["namespace"] = l.namespace.value
+	}
+	return fields
+}
+
+func (l loggerImpl) MergeFrom(other Logger) Logger {
+	if other == nil {
+		return l
+	}
+
+	fields := other.GetFields()
+	for k := range l.general().fields {
+		delete(fields, k)
+	}
+	if namespace, ok := fields["namespace"]; ok {
+		delete(fields, "namespace")
+		if l.namespace == nil {
+			l = l.withNamespace(newNamespaceField(namespaceString(namespace)))
+		}
+	}
+
+	return l.withFields(newFieldLayers(fields).with(l.fields.fields()))
+}
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_GetFields(t *testing.T) {
+	at := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "orders"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger = logger.With(Fields{"order_id": 42}).With(Time("created_at", at))
+	fields := logger.GetFields()
+	assert.Equal(t, Fields{"service": "testing", "namespace": "orders", "order_id": 42, "created_at": at}, fields)
+
+	fields["order_id"] = 43
+	value, _ := logger.GetField("order_id")
+	assert.Equal(t, 42, value)
+}
+
+func TestLoggerImpl_GetFieldBase(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing", Region: "eu-west-1"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	value, ok := logger.GetField("service")
+	assert.True(t, ok)
+	assert.Equal(t, "testing", value)
+
+	value, ok = logger.Namespace("derived").GetField(RegionKey)
+	assert.True(t, ok)
+	assert.Equal(t, "eu-west-1", value)
+
+	// Fields of the logger shadow base ones
+	value, ok = logger.With(Fields{RegionKey: "us-east-1"}).GetField(RegionKey)
+	assert.True(t, ok)
+	assert.Equal(t, "us-east-1", value)
+
+	_, ok = logger.GetField(ClusterKey)
+	assert.False(t, ok)
+
+}
+
+func TestLoggerImpl_GetFieldsBase(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing", Region: "eu-west-1"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	fields := logger.Namespace("derived").GetFields()
+	assert.Equal(t, "testing", fields["service"])
+	assert.Equal(t, "eu-west-1", fields[RegionKey])
+
+	// Fields of the logger shadow base ones, except colliding service
+	fields = logger.With(Fields{RegionKey: "us-east-1", "service": "fake"}).GetFields()
+	assert.Equal(t, "us-east-1", fields[RegionKey])
+	assert.Equal(t, "testing", fields["service"])
+
+	logger, err = newLogger(LoggingConfig{
+		Service:        "testing",
+		FieldCollision: FieldCollisionOverride,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+	assert.Equal(t, "fake", logger.With(Fields{"service": "fake"}).GetFields()["service"])
+}
+
+// Base fields of the receiver aren't copied into its fields, so Reconfigure still replaces them
+func TestLoggerImpl_MergeFromBase(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Region: "eu-west-1"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	merged := logger.MergeFrom(logger.With(Fields{"job_id": 7}))
+	require.NoError(t, logger.(Reconfigurer).Reconfigure(LoggingConfig{Service: "renamed", Region: "us-east-1"}))
+	merged.Info("merged")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "renamed", entries[0]["service"])
+	assert.Equal(t, "us-east-1", entries[0][RegionKey])
+	assert.Equal(t, float64(7), entries[0]["job_id"])
+}
+
+// GetField returns the value which is encoded
+func TestLoggerImpl_GetFieldCollision(t *testing.T) {
+	for policy, want := range map[FieldCollisionPolicy]string{
+		FieldCollisionIgnore:   "testing",
+		FieldCollisionOverride: "fake",
+		FieldCollisionRename:   "testing",
+	} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{Service: "testing", FieldCollision: policy}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		shadowing := logger.With(Fields{"service": "fake", "message": "fake"})
+		value, ok := shadowing.GetField("service")
+		assert.True(t, ok, policy)
+		assert.Equal(t, want, value, policy)
+		_, ok = shadowing.GetField("message")
+		assert.False(t, ok, policy)
+
+		shadowing.Info("collision")
+		entries := buf.entries(t)
+		require.NotEmpty(t, entries)
+		assert.Equal(t, want, entries[len(entries)-1]["service"], policy)
+	}
+}
+
+func TestLoggerImpl_MergeFrom(t *testing.T) {
+	buf := &lockedBuffer{}
+	base, err := newLogger(LoggingConfig{Service: "testing", Namespace: "http", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	request := base.With(Fields{"request_id": "abc", "user": "alice"})
+	job := base.Namespace("jobs").With(Fields{"job_id": 7, "user": "bob"})
+
+	merged := request.MergeFrom(job)
+	merged.Info("merged")
+	request.Info("receiver unchanged")
+	request.MergeFrom(nil).Info("nil other")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "abc", entries[0]["request_id"])
+	assert.Equal(t, float64(7), entries[0]["job_id"])
+	assert.Equal(t, "alice", entries[0]["user"], "receiver wins on conflicts")
+	assert.Equal(t, "http", entries[0]["namespace"], "receiver wins on conflicts")
+
+	assert.NotContains(t, entries[1], "job_id")
+	assert.Equal(t, "abc", entries[2]["request_id"])
+}
@@ -0,0 +1,77 @@
+// Package natsadapter logs NATS connection lifecycle events through logger.Logger and connects the NATS output
+// of the logger to LoggingConfig.NATSURL once imported.
+package natsadapter
+
+import (
+	"strings"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/w84thesun/logger"
+)
+
+// Fields attached to connection entries
+const (
+	ServerKey = "nats.server"
+	// Known servers, logged instead of ServerKey while disconnected
+	ServersKey      = "nats.servers"
+	ReconnectsKey   = "nats.reconnects"
+	SubjectKey      = "nats.subject"
+	ConnectionIDKey = "nats.client_id"
+)
+
+// Component of all entries
+const Component = "nats"
+
+// Options returns nats.Connect options logging disconnects and errors at error level, reconnects at warn
+// and close at info. Pass them before own handlers to override some of them.
+func Options(l logger.Logger) []nats.Option {
+	l = l.WithComponent(Component)
+
+	return []nats.Option{
+		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
+			log := withConn(l, nc)
+			if err == nil {
+				log.Warn("nats disconnected")
+				return
+			}
+			log.With(logger.Fields{"error": err.Error()}).Error("nats disconnected")
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			withConn(l, nc).Warn("nats reconnected")
+		}),
+		nats.ReconnectErrHandler(func(nc *nats.Conn, err error) {
+			withConn(l, nc).With(logger.Fields{"error": err.Error()}).Error("nats reconnect failed")
+		}),
+		nats.ClosedHandler(func(nc *nats.Conn) {
+			withConn(l, nc).Info("nats connection closed")
+		}),
+		nats.ErrorHandler(func(nc *nats.Conn, sub *nats.Subscription, err error) {
+			log := withConn(l, nc).With(logger.Fields{"error": err.Error()})
+			if sub != nil {
+				log = log.With(logger.Fields{SubjectKey: sub.Subject})
+			}
+			log.Error("nats async error")
+		}),
+	}
+}
+
+// withConn adds fields identifying the server and connection state
+func withConn(l logger.Logger, nc *nats.Conn) logger.Logger {
+	if nc == nil {
+		return l
+	}
+
+	fields := logger.Fields{
+		ReconnectsKey: nc.Stats().Reconnects,
+	}
+	if server := nc.ConnectedUrlRedacted(); server != "" {
+		fields[ServerKey] = server
+	} else {
+		fields[ServersKey] = strings.Join(nc.Servers(), ",")
+	}
+	if id, err := nc.GetClientID(); err == nil {
+		fields[ConnectionIDKey] = id
+	}
+	return l.With(fields)
+}
@@ -0,0 +1,98 @@
+package natsadapter
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+// eventually waits for an entry with the message
+func eventually(t *testing.T, entries func() []map[string]interface{}, message string) map[string]interface{} {
+	var found map[string]interface{}
+	require.Eventually(t, func() bool {
+		for _, entry := range entries() {
+			if entry["message"] == message {
+				found = entry
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond, message)
+	return found
+}
+
+func runServer(t *testing.T, port int) *server.Server {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = port
+	return natsserver.RunServer(&opts)
+}
+
+func TestOptions(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	s := runServer(t, server.RANDOM_PORT)
+	url := s.ClientURL()
+	port := s.Addr().(*net.TCPAddr).Port
+
+	nc, err := nats.Connect(url, append(Options(l), nats.ReconnectWait(10*time.Millisecond), nats.MaxReconnects(-1))...)
+	require.NoError(t, err)
+
+	s.Shutdown()
+	disconnected := eventually(t, entries, "nats disconnected")
+	assert.Equal(t, "nats", disconnected[logger.ComponentKey])
+	assert.Equal(t, url, disconnected[ServersKey])
+	assert.Equal(t, float64(0), disconnected[ReconnectsKey])
+
+	s = runServer(t, port)
+	defer s.Shutdown()
+	reconnected := eventually(t, entries, "nats reconnected")
+	assert.Equal(t, "warn", reconnected["level"])
+	assert.Equal(t, url, reconnected[ServerKey])
+	assert.Equal(t, float64(1), reconnected[ReconnectsKey])
+
+	nc.Close()
+	closed := eventually(t, entries, "nats connection closed")
+	assert.Equal(t, "info", closed["level"])
+}
+
+func TestOptions_AsyncError(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	s := runServer(t, server.RANDOM_PORT)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(s.ClientURL(), Options(l)...)
+	require.NoError(t, err)
+	defer nc.Close()
+
+	block := make(chan struct{})
+	defer close(block)
+	sub, err := nc.Subscribe("orders.created", func(*nats.Msg) { <-block })
+	require.NoError(t, err)
+	require.NoError(t, sub.SetPendingLimits(1, -1))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, nc.Publish("orders.created", []byte("order")))
+	}
+	require.NoError(t, nc.Flush())
+
+	entry := eventually(t, entries, "nats async error")
+	assert.Equal(t, "error", entry["level"])
+	assert.Equal(t, "orders.created", entry[SubjectKey])
+	assert.Contains(t, entry["error"], "slow consumer")
+	assert.Equal(t, s.ClientURL(), entry[ServerKey])
+}
@@ -0,0 +1,61 @@
+package natsadapter
+
+import (
+	"io"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/w84thesun/logger"
+)
+
+// Makes LoggingConfig.NATSURL of the logger usable once the package is imported
+func init() {
+	logger.RegisterNATSDialer(Dial)
+}
+
+// Dial connects to NATSURL of the config for the NATS output of the logger, the connection is named after
+// the service. With NATSJetStream entries are published to JetStream, see JetStream.
+func Dial(config logger.LoggingConfig) (logger.Publisher, io.Closer, error) {
+	nc, err := nats.Connect(config.NATSURL, nats.Name(config.Service))
+	if err != nil {
+		return nil, nil, err
+	}
+	closer := connCloser{nc: nc}
+
+	if !config.NATSJetStream {
+		return nc, closer, nil
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, nil, err
+	}
+	return JetStream(js), closer, nil
+}
+
+// JetStream returns publisher of the NATS output waiting for every entry to be stored by the stream bound
+// to the subject, e.g. for LoggingConfig.NATSPublisher. Entries are published in background, so logging
+// doesn't wait for acknowledgements.
+func JetStream(js nats.JetStreamContext) logger.Publisher {
+	return jetStreamPublisher{js: js}
+}
+
+type jetStreamPublisher struct {
+	js nats.JetStreamContext
+}
+
+func (p jetStreamPublisher) Publish(subject string, data []byte) error {
+	_, err := p.js.Publish(subject, data)
+	return err
+}
+
+// connCloser closes the connection made by Dial
+type connCloser struct {
+	nc *nats.Conn
+}
+
+func (c connCloser) Close() error {
+	c.nc.Close()
+	return nil
+}
@@ -0,0 +1,115 @@
+package natsadapter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+func TestDial(t *testing.T) {
+	s := runServer(t, server.RANDOM_PORT)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+	sub, err := nc.SubscribeSync("logs.testing")
+	require.NoError(t, err)
+	require.NoError(t, nc.Flush())
+
+	l, err := logger.New(logger.LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSURL:       s.ClientURL(),
+		NATSSubject:   "logs.testing",
+	})
+	require.NoError(t, err)
+
+	l.With(logger.Fields{"order_id": 7}).Info("charged")
+	require.NoError(t, l.Sync())
+
+	msg, err := sub.NextMsg(5 * time.Second)
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(msg.Data, &entry))
+	assert.Equal(t, "charged", entry["message"])
+	assert.Equal(t, float64(7), entry["order_id"])
+
+	require.NoError(t, l.Close())
+	require.Eventually(t, func() bool { return s.NumClients() == 1 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDial_JetStream(t *testing.T) {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = server.RANDOM_PORT
+	opts.JetStream = true
+	opts.StoreDir = t.TempDir()
+	s := natsserver.RunServer(&opts)
+	defer s.Shutdown()
+
+	nc, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+	_, err = js.AddStream(&nats.StreamConfig{Name: "LOGS", Subjects: []string{"logs.>"}})
+	require.NoError(t, err)
+
+	l, err := logger.New(logger.LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSURL:       s.ClientURL(),
+		NATSSubject:   "logs.testing",
+		NATSJetStream: true,
+	})
+	require.NoError(t, err)
+	defer l.Close()
+
+	l.Info("stored")
+	l.Warn("stored too")
+	// Entries are acknowledged by the stream once Sync returns
+	require.NoError(t, l.Sync())
+
+	info, err := js.StreamInfo("LOGS")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), info.State.Msgs)
+
+	stored, err := js.GetMsg("LOGS", 1)
+	require.NoError(t, err)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(stored.Data, &entry))
+	assert.Equal(t, "stored", entry["message"])
+
+	// Subjects without stream aren't acknowledged
+	unbound, err := logger.New(logger.LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSURL:       s.ClientURL(),
+		NATSSubject:   "unbound",
+		NATSJetStream: true,
+	})
+	require.NoError(t, err)
+	defer unbound.Close()
+	unbound.Info("lost")
+	assert.Error(t, unbound.Sync())
+}
+
+func TestDial_Unreachable(t *testing.T) {
+	_, err := logger.New(logger.LoggingConfig{
+		Service:     "testing",
+		NATSURL:     "nats://127.0.0.1:1",
+		NATSSubject: "logs",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,219 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Entries waiting to be published by the NATS output if LoggingConfig.NATSQueueSize is zero
+const DefaultNATSQueueSize = 1024
+
+// Returned by writes of the NATS output for entries dropped since its queue is full, e.g. while NATS is slow
+var ErrNATSQueueFull = errors.New("nats output queue is full, entry dropped")
+
+// Returned by natsWriter methods called after Close
+var errNATSClosed = errors.New("nats writer is closed")
+
+// Publisher publishes entries of the NATS output, implemented by *nats.Conn.
+// Publishers having Flush() error, like *nats.Conn, are flushed by Sync.
+type Publisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSDialer connects the NATS output to LoggingConfig.NATSURL, the closer is closed with the logger
+type NATSDialer func(config LoggingConfig) (Publisher, io.Closer, error)
+
+var (
+	natsDialerMu sync.RWMutex
+	natsDialer   NATSDialer
+)
+
+// Makes NATSURL usable by connecting with the dialer, replacing previous registration if any.
+// The logger doesn't depend on a NATS client, importing natsadapter registers one.
+func RegisterNATSDialer(dialer NATSDialer) {
+	natsDialerMu.Lock()
+	natsDialer = dialer
+	natsDialerMu.Unlock()
+}
+
+func getNATSDialer() NATSDialer {
+	natsDialerMu.RLock()
+	defer natsDialerMu.RUnlock()
+	return natsDialer
+}
+
+// natsWriter publishes every written entry as a message to the subject in background, so writes don't block
+// on NATS. Entries not fitting the queue are dropped, errors of publishing are returned by the next Write or Sync.
+type natsWriter struct {
+	// Entries dropped since the queue is full, reported to Stats. First, so it's 64-bit aligned.
+	dropped uint64
+
+	publisher Publisher
+	subject   string
+	// Closed with the writer if not nil
+	closer io.Closer
+
+	// Guards non-blocking queue sends and closed
+	mu     sync.Mutex
+	closed bool
+	// Sync calls sending to the queue without the lock, waited by Close before closing the queue
+	senders sync.WaitGroup
+
+	errMu sync.Mutex
+	err   error
+
+	queue chan natsMessage
+	done  chan struct{}
+}
+
+// natsMessage is either an entry to publish or a marker closed once all previous entries are published
+type natsMessage struct {
+	data      []byte
+	published chan struct{}
+}
+
+func newNATSWriter(publisher Publisher, subject string, queueSize int, closer io.Closer) *natsWriter {
+	if queueSize <= 0 {
+		queueSize = DefaultNATSQueueSize
+	}
+
+	w := &natsWriter{
+		publisher: publisher,
+		subject:   subject,
+		closer:    closer,
+		queue:     make(chan natsMessage, queueSize),
+		done:      make(chan struct{}),
+	}
+	go w.publish()
+	return w
+}
+
+func (w *natsWriter) publish() {
+	defer close(w.done)
+
+	for message := range w.queue {
+		if message.published != nil {
+			close(message.published)
+			continue
+		}
+		if err := w.publisher.Publish(w.subject, message.data); err != nil {
+			w.errMu.Lock()
+			w.err = multierr.Append(w.err, err)
+			w.errMu.Unlock()
+		}
+	}
+}
+
+// takeErr returns errors of publishing since the previous call
+func (w *natsWriter) takeErr() error {
+	w.errMu.Lock()
+	defer w.errMu.Unlock()
+
+	err := w.err
+	w.err = nil
+	return err
+}
+
+func (w *natsWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errNATSClosed
+	}
+
+	// Zap reuses p once Write returns, so it's copied. A message is a single entry, the newline isn't needed.
+	data := append([]byte(nil), bytes.TrimSuffix(p, []byte("\n"))...)
+	select {
+	case w.queue <- natsMessage{data: data}:
+	default:
+		atomic.AddUint64(&w.dropped, 1)
+		return 0, multierr.Append(w.takeErr(), ErrNATSQueueFull)
+	}
+	return len(p), w.takeErr()
+}
+
+// Sync waits until queued entries are published and flushes the publisher, returns their errors
+func (w *natsWriter) Sync() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.senders.Add(1)
+	w.mu.Unlock()
+
+	// Writes aren't blocked while the queue is full
+	published := make(chan struct{})
+	w.queue <- natsMessage{published: published}
+	w.senders.Done()
+
+	<-published
+	return multierr.Append(w.takeErr(), w.flush())
+}
+
+func (w *natsWriter) queueStats() (int, int, uint64) {
+	return len(w.queue), cap(w.queue), atomic.LoadUint64(&w.dropped)
+}
+
+func (w *natsWriter) flush() error {
+	if flusher, ok := w.publisher.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// Close publishes queued entries, stops background publishing and closes the connection made by the dialer.
+// Calling it more than once is no-op.
+func (w *natsWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	w.senders.Wait()
+	close(w.queue)
+
+	<-w.done
+	err := multierr.Append(w.takeErr(), w.flush())
+	if w.closer != nil {
+		err = multierr.Append(err, w.closer.Close())
+	}
+	return err
+}
+
+// newNATSCore builds JSON core of the NATS output, with the same keys as logstash one
+func newNATSCore(zapLevel zapcore.Level, config LoggingConfig) (zapcore.Core, io.Closer, error) {
+	if config.NATSSubject == "" {
+		return nil, nil, errors.New("NATSSubject must be set to publish entries to NATS")
+	}
+
+	publisher := config.NATSPublisher
+	var closer io.Closer
+	if publisher == nil {
+		dialer := getNATSDialer()
+		if dialer == nil {
+			return nil, nil, fmt.Errorf("no NATS client to connect to NATSURL %v, import natsadapter or set NATSPublisher",
+				config.NATSURL)
+		}
+
+		var err error
+		publisher, closer, err = dialer(config)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	w := newNATSWriter(publisher, config.NATSSubject, config.NATSQueueSize, closer)
+	return newCompatJSONCore(w, zapLevel, config), w, nil
+}
@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPublisher records published messages, blocking while block is set and failing with err
+type mockPublisher struct {
+	mu       sync.Mutex
+	subjects []string
+	messages []string
+	flushes  int
+	closed   bool
+	err      error
+
+	block chan struct{}
+}
+
+func (p *mockPublisher) Publish(subject string, data []byte) error {
+	if p.block != nil {
+		<-p.block
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.subjects = append(p.subjects, subject)
+	p.messages = append(p.messages, string(data))
+	return nil
+}
+
+func (p *mockPublisher) Flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.flushes++
+	return nil
+}
+
+func (p *mockPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *mockPublisher) published() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.messages...)
+}
+
+func TestNATS(t *testing.T) {
+	publisher := &mockPublisher{}
+	logger, err := New(LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSSubject:   "logs.testing",
+		NATSPublisher: publisher,
+	})
+	require.NoError(t, err)
+
+	logger.With(Fields{"order_id": 7}).Info("charged")
+	logger.Warn("retrying")
+	require.NoError(t, logger.Sync())
+
+	messages := publisher.published()
+	require.Len(t, messages, 2)
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(messages[0]), &entry))
+	assert.Equal(t, "charged", entry["message"])
+	assert.Equal(t, "testing", entry["service"])
+	assert.Equal(t, float64(7), entry["order_id"])
+	assert.NotContains(t, messages[0], "\n")
+	assert.Equal(t, []string{"logs.testing", "logs.testing"}, publisher.subjects)
+	assert.Equal(t, 1, publisher.flushes)
+
+	// Injected publisher isn't closed, it's owned by the caller
+	require.NoError(t, logger.Close())
+	assert.False(t, publisher.closed)
+}
+
+func TestNATS_Dialer(t *testing.T) {
+	publisher := &mockPublisher{}
+	var dialed LoggingConfig
+	RegisterNATSDialer(func(config LoggingConfig) (Publisher, io.Closer, error) {
+		dialed = config
+		return publisher, publisher, nil
+	})
+	defer RegisterNATSDialer(nil)
+
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSURL:       "nats://127.0.0.1:4222",
+		NATSSubject:   "logs",
+		NATSJetStream: true,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "nats://127.0.0.1:4222", dialed.NATSURL)
+	assert.True(t, dialed.NATSJetStream)
+
+	logger.Info("dialed")
+	require.NoError(t, logger.Close())
+	assert.Len(t, publisher.published(), 1)
+	assert.True(t, publisher.closed)
+}
+
+func TestNATS_Invalid(t *testing.T) {
+	_, err := New(LoggingConfig{Service: "testing", NATSPublisher: &mockPublisher{}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NATSSubject")
+
+	_, err = New(LoggingConfig{Service: "testing", NATSURL: "nats://127.0.0.1:4222", NATSSubject: "logs"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "natsadapter")
+
+	RegisterNATSDialer(func(LoggingConfig) (Publisher, io.Closer, error) {
+		return nil, nil, assert.AnError
+	})
+	defer RegisterNATSDialer(nil)
+	_, err = New(LoggingConfig{Service: "testing", NATSURL: "nats://127.0.0.1:4222", NATSSubject: "logs"})
+	assert.Equal(t, assert.AnError, err)
+}
+
+func TestNATSWriter_QueueFull(t *testing.T) {
+	publisher := &mockPublisher{block: make(chan struct{})}
+	w := newNATSWriter(publisher, "logs", 2, nil)
+
+	// The first entry is taken by the blocked publisher, two more fill the queue
+	_, err := w.Write([]byte("{\"n\":1}\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(w.queue) == 0 }, time.Second, time.Millisecond)
+	for i := 0; i < 2; i++ {
+		_, err = w.Write([]byte("{}\n"))
+		require.NoError(t, err)
+	}
+
+	_, err = w.Write([]byte("{\"dropped\":true}\n"))
+	assert.True(t, errors.Is(err, ErrNATSQueueFull))
+
+	close(publisher.block)
+	require.NoError(t, w.Close())
+	assert.Equal(t, []string{`{"n":1}`, "{}", "{}"}, publisher.published())
+
+	_, err = w.Write([]byte("{}\n"))
+	assert.Equal(t, errNATSClosed, err)
+	assert.NoError(t, w.Close())
+}
+
+// Sync waiting for the full queue doesn't block writes
+func TestNATSWriter_SyncQueueFull(t *testing.T) {
+	publisher := &mockPublisher{block: make(chan struct{})}
+	w := newNATSWriter(publisher, "logs", 1, nil)
+
+	_, err := w.Write([]byte("{}\n"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(w.queue) == 0 }, time.Second, time.Millisecond)
+	_, err = w.Write([]byte("{}\n"))
+	require.NoError(t, err)
+
+	synced := make(chan error)
+	go func() { synced <- w.Sync() }()
+	time.Sleep(10 * time.Millisecond)
+
+	written := make(chan error)
+	go func() {
+		_, err := w.Write([]byte("{}\n"))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		assert.True(t, errors.Is(err, ErrNATSQueueFull))
+	case <-time.After(time.Second):
+		t.Fatal("write is blocked by sync")
+	}
+
+	close(publisher.block)
+	assert.NoError(t, <-synced)
+	assert.NoError(t, w.Close())
+}
+
+func TestNATSWriter_PublishError(t *testing.T) {
+	publisher := &mockPublisher{err: assert.AnError}
+	w := newNATSWriter(publisher, "logs", 0, nil)
+	defer w.Close()
+
+	_, err := w.Write([]byte("{}\n"))
+	require.NoError(t, err)
+	assert.Equal(t, assert.AnError, w.Sync())
+
+	// Reported once
+	assert.NoError(t, w.Sync())
+}
+
+func TestNATS_OutputName(t *testing.T) {
+	wrapper := &recordingWrapper{written: map[string]int{}}
+	logger, err := newLogger(LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSSubject:   "logs",
+		NATSPublisher: &mockPublisher{},
+		CoreWrapper:   wrapper,
+	}, nil)
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("wrapped")
+	assert.Equal(t, []string{OutputNATS}, wrapper.outputs)
+	assert.Equal(t, 1, wrapper.written[OutputNATS])
+}
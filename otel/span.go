@@ -0,0 +1,282 @@
+// Package otel correlates logs with OpenTelemetry traces
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/w84thesun/logger"
+)
+
+// Keys of span context fields
+const (
+	TraceIDKey    = "trace_id"
+	SpanIDKey     = "span_id"
+	TraceFlagsKey = "trace_flags"
+)
+
+// Name and attribute keys of span events recorded for error entries
+const (
+	EventName   = "log"
+	SeverityKey = attribute.Key("log.severity")
+	MessageKey  = attribute.Key("log.message")
+)
+
+// Configures WithSpanContext
+type Option func(*options)
+
+type options struct {
+	errorEvents bool
+}
+
+// Records error, panic and fatal entries as events of the span, so they appear in the trace.
+// Errors logged by Trace and Recover are recorded with span.RecordError instead and mark the span as failed.
+func WithErrorEvents() Option {
+	return func(o *options) {
+		o.errorEvents = true
+	}
+}
+
+// Returns logger with trace_id, span_id and trace_flags fields of the span carried by ctx.
+// Logger is returned unchanged if ctx has no valid span context.
+func WithSpanContext(ctx context.Context, l logger.Logger, opts ...Option) logger.Logger {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return l
+	}
+
+	l = l.With(SpanFields(span.SpanContext()))
+	if o.errorEvents && span.IsRecording() {
+		return spanLogger{Logger: l, span: span}
+	}
+	return l
+}
+
+// Returns fields identifying the span, nil for invalid span context
+func SpanFields(sc trace.SpanContext) logger.Fields {
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return logger.Fields{
+		TraceIDKey:    sc.TraceID().String(),
+		SpanIDKey:     sc.SpanID().String(),
+		TraceFlagsKey: sc.TraceFlags().String(),
+	}
+}
+
+// spanLogger mirrors error level entries to the span, methods logging through the wrapped logger skip their own frame
+type spanLogger struct {
+	logger.Logger
+
+	span trace.Span
+}
+
+func (l spanLogger) wrap(next logger.Logger) logger.Logger {
+	return spanLogger{Logger: next, span: l.span}
+}
+
+func (l spanLogger) event(severity string, message string) {
+	l.span.AddEvent(EventName, trace.WithAttributes(
+		SeverityKey.String(severity),
+		MessageKey.String(message),
+	))
+}
+
+func (l spanLogger) Error(message ...interface{}) {
+	if l.Enabled("error") {
+		l.event("error", fmt.Sprint(message...))
+	}
+	l.Logger.WithCallerSkip(1).Error(message...)
+}
+
+func (l spanLogger) Errorf(format string, args ...interface{}) {
+	if l.Enabled("error") {
+		l.event("error", fmt.Sprintf(format, args...))
+	}
+	l.Logger.WithCallerSkip(1).Errorf(format, args...)
+}
+
+func (l spanLogger) ErrorZ(msg string, fields ...logger.Field) {
+	if l.Enabled("error") {
+		l.event("error", msg)
+	}
+	l.Logger.WithCallerSkip(1).ErrorZ(msg, fields...)
+}
+
+func (l spanLogger) Panic(message ...interface{}) {
+	l.event("panic", fmt.Sprint(message...))
+	l.Logger.WithCallerSkip(1).Panic(message...)
+}
+
+func (l spanLogger) Panicf(format string, args ...interface{}) {
+	l.event("panic", fmt.Sprintf(format, args...))
+	l.Logger.WithCallerSkip(1).Panicf(format, args...)
+}
+
+func (l spanLogger) Fatal(message ...interface{}) {
+	l.event("fatal", fmt.Sprint(message...))
+	l.Logger.WithCallerSkip(1).Fatal(message...)
+}
+
+func (l spanLogger) Fatalf(format string, args ...interface{}) {
+	l.event("fatal", fmt.Sprintf(format, args...))
+	l.Logger.WithCallerSkip(1).Fatalf(format, args...)
+}
+
+func (l spanLogger) Trace(err error) {
+	if err == nil {
+		return
+	}
+
+	if l.Enabled("error") {
+		l.span.RecordError(err)
+		l.span.SetStatus(codes.Error, err.Error())
+	}
+	l.Logger.WithCallerSkip(1).Trace(err)
+}
+
+func (l spanLogger) LogErr(err error, msg string) error {
+	if err != nil && l.Enabled("error") {
+		l.event("error", msg)
+	}
+	return l.Logger.WithCallerSkip(1).LogErr(err, msg)
+}
+
+// Recover records the panic to the span and panics again with the value through Recover of the wrapped logger,
+// since recover works only when called by the deferred function itself. The wrapped logger logs it like it would
+// without the span, e.g. with RecoverFormat, and panics.
+func (l spanLogger) Recover(msg string) {
+	i := recover()
+	if i == nil {
+		return
+	}
+
+	err, ok := i.(error)
+	if !ok {
+		err = fmt.Errorf("%v", i)
+	}
+	l.span.RecordError(err)
+	l.span.SetStatus(codes.Error, err.Error())
+	l.event("panic", msg)
+
+	func() {
+		defer l.Logger.Recover(msg)
+		panic(i)
+	}()
+}
+
+func (l spanLogger) With(fields logger.Fields) logger.Logger {
+	return l.wrap(l.Logger.With(fields))
+}
+
+func (l spanLogger) WithReuse(fields logger.Fields) logger.Logger {
+	return l.wrap(l.Logger.WithReuse(fields))
+}
+
+func (l spanLogger) WithLazy(fn func() logger.Fields) logger.Logger {
+	return l.wrap(l.Logger.WithLazy(fn))
+}
+
+func (l spanLogger) Namespace(namespace string) logger.Logger {
+	return l.wrap(l.Logger.Namespace(namespace))
+}
+
+func (l spanLogger) AppendNamespace(sub string) logger.Logger {
+	return l.wrap(l.Logger.AppendNamespace(sub))
+}
+
+func (l spanLogger) WithComponent(name string) logger.Logger {
+	return l.wrap(l.Logger.WithComponent(name))
+}
+
+func (l spanLogger) WithErrorClass(class string) logger.Logger {
+	return l.wrap(l.Logger.WithErrorClass(class))
+}
+
+func (l spanLogger) WithTenant(ctx context.Context) logger.Logger {
+	return l.wrap(l.Logger.WithTenant(ctx))
+}
+
+func (l spanLogger) WithUser(ctx context.Context) logger.Logger {
+	return l.wrap(l.Logger.WithUser(ctx))
+}
+
+func (l spanLogger) WithMDC(ctx context.Context) logger.Logger {
+	return l.wrap(l.Logger.WithMDC(ctx))
+}
+
+func (l spanLogger) WithContextError(ctx context.Context) logger.Logger {
+	return l.wrap(l.Logger.WithContextError(ctx))
+}
+
+func (l spanLogger) WithTTL(d time.Duration) logger.Logger {
+	return l.wrap(l.Logger.WithTTL(d))
+}
+
+func (l spanLogger) Once(key string) logger.Logger {
+	return l.wrap(l.Logger.Once(key))
+}
+
+func (l spanLogger) Every(key string, n int) logger.Logger {
+	return l.wrap(l.Logger.Every(key, n))
+}
+
+func (l spanLogger) EveryDuration(key string, d time.Duration) logger.Logger {
+	return l.wrap(l.Logger.EveryDuration(key, d))
+}
+
+func (l spanLogger) WithCallerSkip(n int) logger.Logger {
+	return l.wrap(l.Logger.WithCallerSkip(n))
+}
+
+func (l spanLogger) MergeFrom(other logger.Logger) logger.Logger {
+	return l.wrap(l.Logger.MergeFrom(other))
+}
+
+func (l spanLogger) Typed() logger.TypedLogger {
+	return spanTypedLogger{TypedLogger: l.Logger.Typed(), span: l.span}
+}
+
+// spanTypedLogger mirrors error level entries of the typed logger to the span
+type spanTypedLogger struct {
+	logger.TypedLogger
+
+	span trace.Span
+}
+
+func (l spanTypedLogger) Error(msg string, fields ...zap.Field) {
+	if l.Sugar().Enabled("error") {
+		spanLogger{span: l.span}.event("error", msg)
+	}
+	l.TypedLogger.Sugar().WithCallerSkip(1).Typed().Error(msg, fields...)
+}
+
+func (l spanTypedLogger) Panic(msg string, fields ...zap.Field) {
+	spanLogger{span: l.span}.event("panic", msg)
+	l.TypedLogger.Sugar().WithCallerSkip(1).Typed().Panic(msg, fields...)
+}
+
+func (l spanTypedLogger) Fatal(msg string, fields ...zap.Field) {
+	spanLogger{span: l.span}.event("fatal", msg)
+	l.TypedLogger.Sugar().WithCallerSkip(1).Typed().Fatal(msg, fields...)
+}
+
+func (l spanTypedLogger) With(fields ...zap.Field) logger.TypedLogger {
+	return spanTypedLogger{TypedLogger: l.TypedLogger.With(fields...), span: l.span}
+}
+
+func (l spanTypedLogger) Sugar() logger.Logger {
+	return spanLogger{Logger: l.TypedLogger.Sugar(), span: l.span}
+}
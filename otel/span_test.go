@@ -0,0 +1,198 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "info"})
+}
+
+func newSpan(t *testing.T) (context.Context, trace.Span, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	ctx, span := provider.Tracer("test").Start(context.Background(), "operation")
+	return ctx, span, recorder
+}
+
+func TestWithSpanContext(t *testing.T) {
+	l, entries := newTestLogger(t)
+	ctx, span, _ := newSpan(t)
+	defer span.End()
+
+	WithSpanContext(ctx, l).Info("hello")
+
+	got := entries()
+	require.Len(t, got, 1)
+	sc := span.SpanContext()
+	assert.Equal(t, sc.TraceID().String(), got[0][TraceIDKey])
+	assert.Equal(t, sc.SpanID().String(), got[0][SpanIDKey])
+	assert.Equal(t, "01", got[0][TraceFlagsKey])
+}
+
+func TestWithSpanContext_Invalid(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	WithSpanContext(context.Background(), l, WithErrorEvents()).Error("no span")
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.NotContains(t, got[0], TraceIDKey)
+	assert.NotContains(t, got[0], SpanIDKey)
+	assert.NotContains(t, got[0], TraceFlagsKey)
+	assert.Nil(t, SpanFields(trace.SpanContext{}))
+}
+
+func TestWithErrorEvents(t *testing.T) {
+	l, entries := newTestLogger(t)
+	ctx, span, recorder := newSpan(t)
+
+	log := WithSpanContext(ctx, l, WithErrorEvents()).With(logger.Fields{"key": "value"})
+	log.Info("not recorded")
+	log.Namespace("orders").Errorf("failed %d", 1)
+	log.Trace(errors.New("boom"))
+	span.End()
+
+	got := entries()
+	require.Len(t, got, 3)
+	for _, entry := range got {
+		assert.Equal(t, span.SpanContext().TraceID().String(), entry[TraceIDKey])
+	}
+	assert.Equal(t, "orders", got[1]["namespace"])
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	events := spans[0].Events()
+	require.Len(t, events, 2)
+
+	assert.Equal(t, EventName, events[0].Name)
+	attrs := map[string]string{}
+	for _, attr := range events[0].Attributes {
+		attrs[string(attr.Key)] = attr.Value.AsString()
+	}
+	assert.Equal(t, map[string]string{"log.severity": "error", "log.message": "failed 1"}, attrs)
+
+	// Trace records exception only, not a duplicate log event
+	assert.Equal(t, "exception", events[1].Name)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestWithErrorEvents_Recover(t *testing.T) {
+	l, entries := newTestLogger(t)
+	ctx, span, recorder := newSpan(t)
+
+	assert.PanicsWithValue(t, "recovered handler from 42", func() {
+		defer WithSpanContext(ctx, l, WithErrorEvents()).Recover("handler")
+		panic(42)
+	})
+	span.End()
+
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "exception", events[0].Name)
+	assert.Equal(t, EventName, events[1].Name)
+	assert.Equal(t, codes.Error, recorder.Ended()[0].Status().Code)
+
+	// Logged by the wrapped logger, values of any type included
+	got := entries()
+	require.NotEmpty(t, got)
+	assert.Equal(t, "recovered handler from 42", got[len(got)-1]["message"])
+	assert.Equal(t, span.SpanContext().TraceID().String(), got[len(got)-1][TraceIDKey])
+}
+
+func TestWithErrorEvents_Typed(t *testing.T) {
+	l, entries := newTestLogger(t)
+	ctx, span, recorder := newSpan(t)
+
+	log := WithSpanContext(ctx, l, WithErrorEvents()).Typed().With(zap.Int("attempt", 2))
+	log.Info("not recorded")
+	log.Error("typed failure", zap.String("key", "value"))
+	log.Sugar().Error("sugared failure")
+	span.End()
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, span.SpanContext().TraceID().String(), got[1][TraceIDKey])
+	assert.Equal(t, float64(2), got[1]["attempt"])
+	assert.Equal(t, "value", got[1]["key"])
+
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 2)
+	assert.Equal(t, "typed failure", events[0].Attributes[1].Value.AsString())
+	assert.Equal(t, "sugared failure", events[1].Attributes[1].Value.AsString())
+}
+
+func TestWithErrorEvents_ErrorZ(t *testing.T) {
+	l, entries := newTestLogger(t)
+	ctx, span, recorder := newSpan(t)
+
+	log := WithSpanContext(ctx, l, WithErrorEvents())
+	log.InfoZ("not recorded")
+	log.ErrorZ("typed failure", zap.String("key", "value"))
+	span.End()
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "value", got[1]["key"])
+
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "typed failure", events[0].Attributes[1].Value.AsString())
+}
+
+func TestWithErrorEvents_LogErr(t *testing.T) {
+	l, entries := newTestLogger(t)
+	ctx, span, recorder := newSpan(t)
+
+	log := WithSpanContext(ctx, l, WithErrorEvents())
+	assert.NoError(t, log.LogErr(nil, "not logged"))
+	assert.Error(t, log.LogErr(errors.New("boom"), "failed to save"))
+	span.End()
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "boom", got[0][logger.ErrorKey])
+
+	events := recorder.Ended()[0].Events()
+	require.Len(t, events, 1)
+	assert.Equal(t, "failed to save", events[0].Attributes[1].Value.AsString())
+}
+
+func TestWithErrorEvents_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "info", Caller: true})
+	ctx, span, _ := newSpan(t)
+	defer span.End()
+
+	log := WithSpanContext(ctx, l, WithErrorEvents())
+	_, _, line, _ := runtime.Caller(0)
+	log.Info("info")
+	log.Error("error")
+	_ = log.LogErr(errors.New("boom"), "failed")
+	log.Typed().Error("typed")
+
+	// Reported at the calls, not in methods of the span logger
+	got := entries()
+	require.Len(t, got, 4)
+	for i, entry := range got {
+		assert.True(t, strings.HasSuffix(entry["caller"].(string), "span_test.go:"+strconv.Itoa(line+1+i)), entry["caller"])
+	}
+}
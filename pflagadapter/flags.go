@@ -0,0 +1,89 @@
+// Package pflagadapter binds logger config to command line flags of pflag/cobra and standard library flag sets
+package pflagadapter
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/w84thesun/logger"
+)
+
+// Names of flags registered by BindFlags, BindStdFlags registers QuietFlag and VerbosityShorthand
+const (
+	LevelFlag     = "log-level"
+	FormatFlag    = "log-format"
+	ServiceFlag   = "log-service"
+	QuietFlag     = "quiet"
+	VerbosityFlag = "verbosity"
+
+	// Shorthand of VerbosityFlag for pflag, name of the counting flag for flag package
+	VerbosityShorthand = "v"
+)
+
+// Levels set by verbosity count, higher counts use the last one.
+// Trace entries are logged at debug level.
+var verbosityLevels = []string{"warn", "info", "debug"}
+
+// Environment variables used for flags not passed explicitly
+var envFallback = map[string]string{
+	LevelFlag:   "LOGGER_LEVEL",
+	FormatFlag:  "LOGGER_FORMAT_STDOUT",
+	ServiceFlag: "LOGGER_SERVICE",
+}
+
+// Registers --log-level, --log-format, --log-service, --quiet (errors only) and -v/--verbosity flags
+// on the flag set, e.g. cmd.PersistentFlags() of cobra command. Current config values are used as defaults.
+// Call NewFromFlags after parsing to build the logger.
+func BindFlags(fs *pflag.FlagSet, cfg *logger.LoggingConfig) {
+	fs.StringVar(&cfg.Level, LevelFlag, cfg.Level, "minimum log level: debug, info, warn, error, panic or fatal")
+	fs.StringVar(&cfg.FormatStdout, FormatFlag, cfg.FormatStdout, "log format: json, pretty, gcp or a registered encoder")
+	fs.StringVar(&cfg.Service, ServiceFlag, cfg.Service, "service name in logs")
+	fs.Bool(QuietFlag, false, "log errors only, overrides verbosity")
+	fs.CountP(VerbosityFlag, VerbosityShorthand, "increase log verbosity: warn, -v info, -vv debug")
+}
+
+// Builds logger from the config bound by BindFlags once flags are parsed.
+// Flags not passed explicitly fall back to LOGGER_LEVEL, LOGGER_FORMAT_STDOUT and LOGGER_SERVICE
+// environment variables and then to config values. Verbosity and quiet override the level.
+func NewFromFlags(fs *pflag.FlagSet, cfg *logger.LoggingConfig) (logger.Logger, error) {
+	verbosity, err := fs.GetCount(VerbosityFlag)
+	if err != nil {
+		return nil, err
+	}
+	quiet, err := fs.GetBool(QuietFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	resolve(cfg, fs.Changed, fs.Changed(VerbosityFlag), verbosity, quiet)
+
+	return logger.New(*cfg)
+}
+
+// resolve applies environment fallback, verbosity and quiet to the parsed config
+func resolve(cfg *logger.LoggingConfig, changed func(name string) bool, verbosityChanged bool, verbosity int, quiet bool) {
+	targets := map[string]*string{
+		LevelFlag:   &cfg.Level,
+		FormatFlag:  &cfg.FormatStdout,
+		ServiceFlag: &cfg.Service,
+	}
+	for name, target := range targets {
+		if changed(name) {
+			continue
+		}
+		if value, ok := os.LookupEnv(envFallback[name]); ok {
+			*target = value
+		}
+	}
+
+	switch {
+	case quiet:
+		cfg.Level = "error"
+	case verbosityChanged:
+		if verbosity >= len(verbosityLevels) {
+			verbosity = len(verbosityLevels) - 1
+		}
+		cfg.Level = verbosityLevels[verbosity]
+	}
+}
@@ -0,0 +1,99 @@
+package pflagadapter
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+// runCommand executes cobra command with the args and returns config and logger built by its Run
+func runCommand(t *testing.T, args ...string) (logger.LoggingConfig, logger.Logger) {
+	cfg := logger.DefaultConfig
+	var l logger.Logger
+
+	cmd := &cobra.Command{
+		Use: "tool",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var err error
+			l, err = NewFromFlags(cmd.Flags(), &cfg)
+			return err
+		},
+	}
+	BindFlags(cmd.Flags(), &cfg)
+	cmd.SetArgs(args)
+
+	require.NoError(t, cmd.Execute())
+	return cfg, l
+}
+
+func TestBindFlags(t *testing.T) {
+	cfg, l := runCommand(t, "--log-level", "warn", "--log-format", "pretty", "--log-service", "tool")
+
+	assert.Equal(t, "warn", cfg.Level)
+	assert.Equal(t, logger.FormatPretty, cfg.FormatStdout)
+	assert.Equal(t, "tool", cfg.Service)
+	assert.False(t, l.Enabled("info"))
+	assert.True(t, l.Enabled("warn"))
+}
+
+func TestBindFlags_Verbosity(t *testing.T) {
+	tests := []struct {
+		args  []string
+		level string
+	}{
+		{args: nil, level: logger.DefaultConfig.Level},
+		{args: []string{"--verbosity=0"}, level: "warn"},
+		{args: []string{"-v"}, level: "info"},
+		{args: []string{"-vv"}, level: "debug"},
+		{args: []string{"-vvvv"}, level: "debug"},
+		{args: []string{"-vv", "--quiet"}, level: "error"},
+		{args: []string{"--log-level=info", "-vv"}, level: "debug"},
+	}
+	for _, tt := range tests {
+		cfg, _ := runCommand(t, tt.args...)
+		assert.Equal(t, tt.level, cfg.Level, tt.args)
+	}
+}
+
+func TestBindFlags_EnvFallback(t *testing.T) {
+	t.Setenv("LOGGER_LEVEL", "error")
+	t.Setenv("LOGGER_SERVICE", "from-env")
+
+	cfg, _ := runCommand(t, "--log-service", "from-flag")
+	assert.Equal(t, "error", cfg.Level)
+	assert.Equal(t, "from-flag", cfg.Service)
+	assert.Equal(t, logger.DefaultConfig.FormatStdout, cfg.FormatStdout)
+}
+
+func TestBindStdFlags(t *testing.T) {
+	t.Setenv("LOGGER_FORMAT_STDOUT", "pretty")
+
+	cfg := logger.DefaultConfig
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	BindStdFlags(fs, &cfg)
+	require.NoError(t, fs.Parse([]string{"-log.service", "tool", "-log.sort-keys", "-v", "-v", "arg"}))
+
+	l, err := NewFromStdFlags(fs, &cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tool", cfg.Service)
+	assert.Equal(t, "debug", cfg.Level)
+	assert.Equal(t, logger.FormatPretty, cfg.FormatStdout)
+	// All flags of logger.RegisterFlagsVar are registered
+	assert.True(t, cfg.SortKeys)
+	assert.True(t, l.Enabled("debug"))
+	assert.Equal(t, []string{"arg"}, fs.Args())
+
+	cfg = logger.DefaultConfig
+	fs = flag.NewFlagSet("tool", flag.ContinueOnError)
+	BindStdFlags(fs, &cfg)
+	require.NoError(t, fs.Parse([]string{"-v=1", "-quiet"}))
+	_, err = NewFromStdFlags(fs, &cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "error", cfg.Level)
+}
@@ -0,0 +1,77 @@
+package pflagadapter
+
+import (
+	"flag"
+	"strconv"
+
+	"github.com/w84thesun/logger"
+)
+
+// countValue counts occurrences of a boolean-like flag, e.g. -v -v, or takes explicit -v=2
+type countValue int
+
+func (c *countValue) String() string {
+	return strconv.Itoa(int(*c))
+}
+
+func (c *countValue) Set(s string) error {
+	// flag package passes "true" for boolean flags without value
+	if s == "true" {
+		*c++
+		return nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*c = countValue(n)
+	return nil
+}
+
+func (c *countValue) IsBoolFlag() bool {
+	return true
+}
+
+// Names of flags registered by logger.RegisterFlagsVar for the flags of BindFlags, used by NewFromStdFlags
+var stdFlags = map[string]string{
+	LevelFlag:   logger.FlagPrefix + "level",
+	FormatFlag:  logger.FlagPrefix + "format",
+	ServiceFlag: logger.FlagPrefix + "service",
+}
+
+// Registers flags of logger.RegisterFlagsVar, e.g. -log.level and -log.format, on standard library flag set
+// with -quiet and counting -v flags on top, e.g. -v -v. Call NewFromStdFlags after parsing to build the logger.
+func BindStdFlags(fs *flag.FlagSet, cfg *logger.LoggingConfig) {
+	logger.RegisterFlagsVar(fs, cfg)
+	fs.Bool(QuietFlag, false, "log errors only, overrides verbosity")
+	fs.Var(new(countValue), VerbosityShorthand, "increase log verbosity: warn, -v info, -v -v debug")
+}
+
+// Same as NewFromFlags for flag set bound by BindStdFlags, -log.level, -log.format and -log.service
+// fall back to the same environment variables
+func NewFromStdFlags(fs *flag.FlagSet, cfg *logger.LoggingConfig) (logger.Logger, error) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	verbosity := 0
+	if f := fs.Lookup(VerbosityShorthand); f != nil {
+		if c, ok := f.Value.(*countValue); ok {
+			verbosity = int(*c)
+		}
+	}
+
+	quiet := false
+	if f := fs.Lookup(QuietFlag); f != nil {
+		quiet, _ = strconv.ParseBool(f.Value.String())
+	}
+
+	changed := func(name string) bool {
+		return set[stdFlags[name]]
+	}
+	resolve(cfg, changed, set[VerbosityShorthand], verbosity, quiet)
+
+	return logger.New(*cfg)
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// WithPprofLabels adds string fields of the logger (e.g. request_id, namespace and service) as pprof labels
+// to the context, so CPU profiles can be matched with entries. Fields of other types are skipped.
+// Labels already in the context are added to the returned logger as fields, the logger's fields win on conflicts.
+//
+// Labels of the context apply to profiles only within pprof.Do or after pprof.SetGoroutineLabels:
+//
+//	ctx, log = logger.WithPprofLabels(ctx, log)
+//	pprof.SetGoroutineLabels(ctx)
+func WithPprofLabels(ctx context.Context, l Logger) (context.Context, Logger) {
+	fields := l.GetFields()
+	contextFields := FieldsFromPprofLabels(ctx)
+	for k := range fields {
+		delete(contextFields, k)
+	}
+	l = l.With(contextFields)
+
+	if s, ok := l.(interface{ serviceName() string }); ok && s.serviceName() != "" {
+		fields["service"] = s.serviceName()
+	}
+
+	var labels []string
+	for k, v := range fields {
+		if s, ok := v.(string); ok {
+			labels = append(labels, k, s)
+		}
+	}
+	if len(labels) == 0 {
+		return ctx, l
+	}
+
+	return pprof.WithLabels(ctx, pprof.Labels(labels...)), l
+}
+
+// FieldsFromPprofLabels returns pprof labels of the context as fields, e.g. set by pprof.Do in a library.
+// The service label is skipped since it is added to entries by the logger itself.
+func FieldsFromPprofLabels(ctx context.Context) Fields {
+	fields := Fields{}
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		if _, ok := ignore[key]; !ok {
+			fields[key] = value
+		}
+		return true
+	})
+	return fields
+}
+
+func (l loggerImpl) serviceName() string {
+	return l.general().service
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"runtime/pprof"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func ExampleWithPprofLabels() {
+	logger, err := newLogger(LoggingConfig{Service: "example", Namespace: "orders", Level: "info"}, zapcore.AddSync(ioutil.Discard))
+	if err != nil {
+		panic(err)
+	}
+	logger = logger.With(Fields{RequestIDKey: "abc", "attempt": 2})
+
+	ctx, _ := WithPprofLabels(context.Background(), logger)
+
+	var labels []string
+	pprof.ForLabels(ctx, func(key, value string) bool {
+		labels = append(labels, key+"="+value)
+		return true
+	})
+	sort.Strings(labels)
+	fmt.Println(labels)
+	// Output:
+	// [namespace=orders request_id=abc service=example]
+}
+
+func TestWithPprofLabels_FromContext(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "orders", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("worker", "3", "namespace", "jobs", "service", "other"))
+	assert.Equal(t, Fields{"worker": "3", "namespace": "jobs"}, FieldsFromPprofLabels(ctx))
+
+	pprof.Do(ctx, pprof.Labels(RequestIDKey, "abc"), func(ctx context.Context) {
+		ctx, l := WithPprofLabels(ctx, logger)
+		l.Info("labeled")
+
+		value, ok := pprof.Label(ctx, "namespace")
+		assert.True(t, ok)
+		assert.Equal(t, "orders", value, "logger fields win")
+	})
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "3", entries[0]["worker"])
+	assert.Equal(t, "abc", entries[0][RequestIDKey])
+	assert.Equal(t, "orders", entries[0]["namespace"])
+	assert.Equal(t, "testing", entries[0]["service"])
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestPresets(t *testing.T) {
+	assert.Equal(t, FormatPretty, DevelopmentConfig.FormatStdout)
+	assert.Equal(t, "debug", DevelopmentConfig.Level)
+	assert.True(t, DevelopmentConfig.Caller)
+	assert.NotEmpty(t, DevelopmentConfig.StacktraceLevel)
+	assert.False(t, DevelopmentConfig.Sampling)
+
+	assert.Equal(t, FormatJSON, ProductionConfig.FormatStdout)
+	assert.Equal(t, "info", ProductionConfig.Level)
+	assert.True(t, ProductionConfig.Sampling)
+
+	dev, err := NewDevelopment()
+	require.NoError(t, err)
+	assert.True(t, dev.Enabled("debug"))
+
+	prod, err := NewProduction()
+	require.NoError(t, err)
+	assert.False(t, prod.Enabled("debug"))
+	assert.True(t, prod.Enabled("info"))
+}
+
+func TestCaller(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("plain")
+	logger.With(Fields{"a": "b"}).Warnf("formatted %d", 1)
+	logger.WithLazy(func() Fields { return Fields{"lazy": true} }).Info("lazy")
+	logger.Trace(errors.New("traced"))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 4)
+	for _, entry := range entries {
+		assert.Contains(t, entry["caller"], "/preset_test.go:")
+	}
+}
+
+func TestStacktraceLevel(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", StacktraceLevel: "error"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Warn("no stack")
+	logger.Error("with stack")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entries[0], "stacktrace")
+	assert.Contains(t, entries[1]["stacktrace"], "TestStacktraceLevel")
+
+	_, err = newLogger(LoggingConfig{Service: "testing", StacktraceLevel: "loud"}, zapcore.AddSync(buf))
+	assert.Error(t, err)
+}
+
+func TestSampling(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Sampling: true, Sequence: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	for i := 0; i < 300; i++ {
+		logger.Info("repeated")
+	}
+	logger.Info("unique")
+
+	// First 100 entries, then every 100th, assuming the loop takes less than a second
+	entries := buf.entries(t)
+	require.Len(t, entries, 103)
+	assert.Equal(t, "unique", entries[102]["message"])
+	// Dropped entries don't take sequence numbers
+	assert.Equal(t, float64(103), entries[102]["seq"])
+}
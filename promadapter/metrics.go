@@ -0,0 +1,177 @@
+// Package promadapter exposes Prometheus metrics of entries written by the logger
+package promadapter
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/w84thesun/logger"
+)
+
+// Reasons of dropped entries
+const (
+	// Entry wasn't written by at least one output
+	ReasonWriteError = "write_error"
+)
+
+// Metrics counts written entries and output errors, pass it as LoggingConfig.CoreWrapper:
+//
+//	metrics, err := promadapter.NewMetrics(prometheus.DefaultRegisterer)
+//	log, err := logger.New(logger.LoggingConfig{Service: "orders", CoreWrapper: metrics})
+//
+// The same Metrics can be shared by several loggers.
+type Metrics struct {
+	entries    *prometheus.CounterVec
+	dropped    *prometheus.CounterVec
+	sinkErrors *prometheus.CounterVec
+
+	namespaces *namespaceLabels
+}
+
+var _ logger.CoreWrapper = (*Metrics)(nil)
+
+// Creates counters and registers them on the registerer:
+// logger_entries_total{level,namespace}, logger_dropped_entries_total{reason} and logger_sink_errors_total{sink}
+func NewMetrics(reg prometheus.Registerer, opts ...Option) (*Metrics, error) {
+	o := options{limit: DefaultNamespaceLimit}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	m := &Metrics{
+		entries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "logger_entries_total",
+			Help: "Number of log entries written, by level and namespace.",
+		}, []string{"level", "namespace"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "logger_dropped_entries_total",
+			Help: "Number of log entries not written to all outputs, by reason.",
+		}, []string{"reason"}),
+		sinkErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "logger_sink_errors_total",
+			Help: "Number of failed writes, by output.",
+		}, []string{"sink"}),
+		namespaces: newNamespaceLabels(o),
+	}
+
+	for _, c := range []prometheus.Collector{m.entries, m.dropped, m.sinkErrors} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (m *Metrics) WrapOutput(name string, core zapcore.Core) zapcore.Core {
+	return &sinkCore{Core: core, errors: m.sinkErrors.WithLabelValues(name)}
+}
+
+func (m *Metrics) WrapCore(core zapcore.Core) zapcore.Core {
+	return &entriesCore{Core: core, metrics: m, namespace: m.namespaces.label("")}
+}
+
+// entriesCore counts entries passed the level check by level and namespace
+type entriesCore struct {
+	zapcore.Core
+
+	metrics *Metrics
+
+	// Label value of the namespace set by With
+	namespace string
+}
+
+func (c *entriesCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &entriesCore{Core: c.Core.With(fields), metrics: c.metrics, namespace: c.namespace}
+	for _, field := range fields {
+		if field.Key == "namespace" && field.Type == zapcore.StringType {
+			clone.namespace = c.metrics.namespaces.label(field.String)
+		}
+	}
+	return clone
+}
+
+func (c *entriesCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *entriesCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+
+	c.metrics.entries.WithLabelValues(ent.Level.String(), c.namespace).Inc()
+	if err != nil {
+		c.metrics.dropped.WithLabelValues(ReasonWriteError).Inc()
+	}
+
+	return err
+}
+
+// sinkCore counts failed writes of a single output
+type sinkCore struct {
+	zapcore.Core
+
+	errors prometheus.Counter
+}
+
+func (c *sinkCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sinkCore{Core: c.Core.With(fields), errors: c.errors}
+}
+
+func (c *sinkCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if err != nil {
+		c.errors.Inc()
+	}
+	return err
+}
+
+// namespaceLabels bounds cardinality of the namespace label
+type namespaceLabels struct {
+	allowed map[string]struct{}
+	limit   int
+
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func newNamespaceLabels(o options) *namespaceLabels {
+	return &namespaceLabels{allowed: o.allowed, limit: o.limit, seen: map[string]struct{}{}}
+}
+
+func (n *namespaceLabels) label(namespace string) string {
+	if n.allowed != nil {
+		if _, ok := n.allowed[namespace]; ok {
+			return namespace
+		}
+		return OtherNamespace
+	}
+
+	if n.limit <= 0 {
+		return ""
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if _, ok := n.seen[namespace]; ok {
+		return namespace
+	}
+	if len(n.seen) >= n.limit {
+		return OtherNamespace
+	}
+
+	n.seen[namespace] = struct{}{}
+	return namespace
+}
@@ -0,0 +1,156 @@
+package promadapter
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+)
+
+// newTestLogger builds a logger with metrics writing into the file instead of stdout
+func newTestLogger(t *testing.T, stdout *os.File, opts ...Option) (logger.Logger, *prometheus.Registry) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewMetrics(reg, opts...)
+	require.NoError(t, err)
+
+	l, err := logger.New(logger.LoggingConfig{
+		Service:      "testing",
+		Namespace:    "default",
+		Level:        "info",
+		StdoutWriter: stdout,
+		CoreWrapper:  metrics,
+	})
+	require.NoError(t, err)
+
+	return l, reg
+}
+
+func tempFile(t *testing.T) *os.File {
+	f, err := os.CreateTemp(t.TempDir(), "stdout")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+// scrape gathers counters from the registry keyed by name and sorted labels, e.g. `name{a="b"}`
+func scrape(t *testing.T, reg *prometheus.Registry) map[string]float64 {
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	samples := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			var labels []string
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, label.GetName()+"="+`"`+label.GetValue()+`"`)
+			}
+			sort.Strings(labels)
+			samples[family.GetName()+"{"+strings.Join(labels, ",")+"}"] = metric.GetCounter().GetValue()
+		}
+	}
+	return samples
+}
+
+func TestMetrics(t *testing.T) {
+	l, reg := newTestLogger(t, tempFile(t))
+
+	orders := l.Namespace("orders")
+	for i := 0; i < 10; i++ {
+		l.Info("burst")
+		orders.Warnf("order %d", i)
+		orders.Debug("filtered by level")
+	}
+	orders.With(logger.Fields{"key": "value"}).Error("failed")
+
+	assert.Equal(t, map[string]float64{
+		`logger_entries_total{level="info",namespace="default"}`: 10,
+		`logger_entries_total{level="warn",namespace="orders"}`:  10,
+		`logger_entries_total{level="error",namespace="orders"}`: 1,
+		`logger_sink_errors_total{sink="stdout"}`:                0,
+	}, scrape(t, reg))
+}
+
+func TestMetrics_SinkErrors(t *testing.T) {
+	stdout := tempFile(t)
+	l, reg := newTestLogger(t, stdout)
+
+	l.Info("written")
+	require.NoError(t, stdout.Close())
+	l.Info("lost")
+	l.Warn("lost")
+
+	assert.Equal(t, map[string]float64{
+		`logger_entries_total{level="info",namespace="default"}`: 2,
+		`logger_entries_total{level="warn",namespace="default"}`: 1,
+		`logger_dropped_entries_total{reason="write_error"}`:     2,
+		`logger_sink_errors_total{sink="stdout"}`:                2,
+	}, scrape(t, reg))
+}
+
+func TestMetrics_NamespaceLimit(t *testing.T) {
+	l, reg := newTestLogger(t, tempFile(t), WithNamespaceLimit(2))
+
+	for _, namespace := range []string{"a", "b", "c", "a"} {
+		l.Namespace(namespace).Info("hello")
+	}
+
+	assert.Equal(t, map[string]float64{
+		`logger_entries_total{level="info",namespace="a"}`:     2,
+		`logger_entries_total{level="info",namespace="other"}`: 2,
+		`logger_sink_errors_total{sink="stdout"}`:              0,
+	}, scrape(t, reg))
+}
+
+func TestMetrics_Namespaces(t *testing.T) {
+	l, reg := newTestLogger(t, tempFile(t), WithNamespaces("orders"))
+
+	l.Namespace("orders").Info("hello")
+	l.Namespace("payments").Info("hello")
+	l.AppendNamespace("sub").Info("hello")
+
+	assert.Equal(t, map[string]float64{
+		`logger_entries_total{level="info",namespace="orders"}`: 1,
+		`logger_entries_total{level="info",namespace="other"}`:  2,
+		`logger_sink_errors_total{sink="stdout"}`:               0,
+	}, scrape(t, reg))
+}
+
+func TestNewMetrics_Registered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, err := NewMetrics(reg)
+	require.NoError(t, err)
+
+	_, err = NewMetrics(reg)
+	assert.Error(t, err)
+}
+
+func TestMetrics_Dedup(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewMetrics(reg)
+	require.NoError(t, err)
+	l, err := logger.New(logger.LoggingConfig{
+		Service:          "testing",
+		Namespace:        "default",
+		Level:            "info",
+		StdoutWriter:     tempFile(t),
+		CoreWrapper:      metrics,
+		DedupConsecutive: true,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		l.Info("burst")
+	}
+	l.Warn("other")
+
+	// Suppressed entries aren't counted, the entry summarizing them is
+	samples := scrape(t, reg)
+	assert.Equal(t, float64(2), samples[`logger_entries_total{level="info",namespace="default"}`])
+	assert.Equal(t, float64(1), samples[`logger_entries_total{level="warn",namespace="default"}`])
+}
@@ -0,0 +1,34 @@
+package promadapter
+
+// Default number of distinct namespaces used as label values, see WithNamespaceLimit
+const DefaultNamespaceLimit = 50
+
+// Label value of namespaces over the limit or not in the allow-list
+const OtherNamespace = "other"
+
+// Configures Metrics
+type Option func(*options)
+
+type options struct {
+	allowed map[string]struct{}
+	limit   int
+}
+
+// Limits namespace label to the listed values, all other namespaces are counted as OtherNamespace.
+// Overrides WithNamespaceLimit.
+func WithNamespaces(namespaces ...string) Option {
+	return func(o *options) {
+		o.allowed = make(map[string]struct{}, len(namespaces))
+		for _, namespace := range namespaces {
+			o.allowed[namespace] = struct{}{}
+		}
+	}
+}
+
+// Caps the number of distinct namespace label values, first seen namespaces are kept
+// and others are counted as OtherNamespace. Zero or negative limit disables the namespace label.
+func WithNamespaceLimit(limit int) Option {
+	return func(o *options) {
+		o.limit = limit
+	}
+}
@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Reconfigurer is implemented by loggers returned by New, e.g. to change level or outputs of a long-running service
+// without replacing loggers passed around:
+//
+//	if r, ok := log.(logger.Reconfigurer); ok {
+//		err = r.Reconfigure(config)
+//	}
+type Reconfigurer interface {
+	// Rebuilds outputs of all loggers derived from the same New call from the config, their fields are kept.
+	// Entries being written meanwhile go to the old outputs, which are synced and closed once they are written.
+	// On error the old outputs are kept. Caller, StacktraceLevel, StackFormatter, RecoverFormat, RecoverStructured,
+	// Namespace, FlushInterval, DeepCopyFields, FieldCollision, the audit output and the debug file of New are kept too. Must not be called from OnWriteError or OnFatal.
+	Reconfigure(config LoggingConfig) error
+}
+
+var _ Reconfigurer = loggerImpl{}
+
+// Returned by Reconfigure of loggers which aren't built by New, e.g. test ones
+var errNotReconfigurable = errors.New("logger can't be reconfigured")
+
+// reconfigState is state of loggers derived from the same New call replaced by Reconfigure
+type reconfigState struct {
+	// Serializes Reconfigure calls
+	mu sync.Mutex
+
+	swap   *swapState
+	stdout zapcore.WriteSyncer
+
+	// *generalState of the current config
+	general atomic.Value
+}
+
+// generalState is service and other fields added to all entries by the core
+type generalState struct {
+	service string
+	fields  Fields
+}
+
+func newReconfigState(swap *swapState, stdout zapcore.WriteSyncer, config LoggingConfig) *reconfigState {
+	r := &reconfigState{swap: swap, stdout: stdout}
+	r.storeGeneral(config)
+	return r
+}
+
+func (r *reconfigState) storeGeneral(config LoggingConfig) {
+	r.general.Store(&generalState{service: config.Service, fields: zapFieldValues(generalFields(config))})
+}
+
+// general returns service and fields of the current config, empty for loggers which aren't built by New
+func (l loggerImpl) general() *generalState {
+	if l.reconfig == nil {
+		return &generalState{}
+	}
+	return l.reconfig.general.Load().(*generalState)
+}
+
+func (l loggerImpl) Reconfigure(config LoggingConfig) error {
+	r := l.reconfig
+	if r == nil {
+		return errNotReconfigurable
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Fields of loggers are already resolved by the policy of New
+	config.FieldCollision = l.collisionPolicy()
+	zapLogger, closers, err := newConfiguredZapLogger(config, r.stdout, l.stats, l.debugFile)
+	if err != nil {
+		return err
+	}
+
+	var replaced zapcore.Core
+	old, err := l.closer.replace(closers, func() {
+		replaced = r.swap.replace(newRecordCore(zapLogger.Core(), l.recorder, generalFields(config)))
+	})
+	if err != nil {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+		return err
+	}
+	r.storeGeneral(config)
+	l.stats.setThreshold(config.HealthThreshold)
+
+	err = replaced.Sync()
+	for _, c := range old {
+		err = multierr.Append(err, c.Close())
+	}
+	return err
+}
+
+// swapCore passes entries to the current core of the state replaced by Reconfigure. Fields added by With
+// are added to each core once, on the first entry written to it by the swapCore.
+type swapCore struct {
+	state  *swapState
+	fields []zapcore.Field
+
+	// *swapDerived with the fields
+	derived atomic.Value
+}
+
+type swapState struct {
+	// *swapTarget
+	current atomic.Value
+
+	// Write errors of cores are printed like zap does
+	errorOutput zapcore.WriteSyncer
+}
+
+type swapTarget struct {
+	core zapcore.Core
+
+	// Writes in progress, the target is synced by Reconfigure once they finish
+	writes int64
+}
+
+type swapDerived struct {
+	target *swapTarget
+	core   zapcore.Core
+}
+
+func newSwapCore(core zapcore.Core) *swapCore {
+	state := &swapState{errorOutput: zapcore.Lock(os.Stderr)}
+	state.current.Store(&swapTarget{core: core})
+	return &swapCore{state: state}
+}
+
+// acquire returns the current target, which isn't synced by Reconfigure until release
+func (s *swapState) acquire() *swapTarget {
+	for {
+		target := s.current.Load().(*swapTarget)
+		atomic.AddInt64(&target.writes, 1)
+		if s.current.Load().(*swapTarget) == target {
+			return target
+		}
+		// Replaced meanwhile, Reconfigure may be already waiting for its writes
+		target.release()
+	}
+}
+
+func (t *swapTarget) release() {
+	atomic.AddInt64(&t.writes, -1)
+}
+
+// replace makes the core current and waits for writes to the replaced one, which is returned
+func (s *swapState) replace(core zapcore.Core) zapcore.Core {
+	old := s.current.Load().(*swapTarget)
+	s.current.Store(&swapTarget{core: core})
+
+	for atomic.LoadInt64(&old.writes) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return old.core
+}
+
+// coreOf returns core of the target with fields of c
+func (c *swapCore) coreOf(target *swapTarget) zapcore.Core {
+	if derived, ok := c.derived.Load().(*swapDerived); ok && derived.target == target {
+		return derived.core
+	}
+
+	core := target.core
+	if len(c.fields) > 0 {
+		core = core.With(c.fields)
+	}
+	c.derived.Store(&swapDerived{target: target, core: core})
+	return core
+}
+
+func (c *swapCore) Enabled(level zapcore.Level) bool {
+	return c.coreOf(c.state.current.Load().(*swapTarget)).Enabled(level)
+}
+
+func (c *swapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &swapCore{
+		state:  c.state,
+		fields: append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *swapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write checks the entry against the current core again, e.g. for sampling, and writes it to the cores added
+func (c *swapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	target := c.state.acquire()
+	defer target.release()
+
+	ce := c.coreOf(target).Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	ce.ErrorOutput = c.state.errorOutput
+	ce.Write(fields...)
+	return nil
+}
+
+func (c *swapCore) Sync() error {
+	target := c.state.acquire()
+	defer target.release()
+
+	return target.core.Sync()
+}
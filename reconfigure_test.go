@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestReconfigure(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	derived := logger.With(Fields{"request_id": 42}).Namespace("derived")
+	derived.Debug("hidden")
+	assert.Empty(t, buf.String())
+
+	require.NoError(t, logger.(Reconfigurer).Reconfigure(LoggingConfig{
+		Service:  "renamed",
+		Level:    "debug",
+		Cluster:  "blue",
+		Sequence: true,
+	}))
+
+	// Loggers derived before keep their fields
+	derived.Debug("visible")
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "visible", entries[0]["message"])
+	assert.Equal(t, "renamed", entries[0]["service"])
+	assert.Equal(t, "blue", entries[0][ClusterKey])
+	assert.Equal(t, "derived", entries[0]["namespace"])
+	assert.Equal(t, float64(42), entries[0]["request_id"])
+	assert.Equal(t, float64(1), entries[0]["seq"])
+
+	value, ok := derived.GetField("service")
+	assert.True(t, ok)
+	assert.Equal(t, "renamed", value)
+	assert.True(t, derived.Enabled("debug"))
+}
+
+func TestReconfigure_Derived(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	derived := logger.With(Fields{"request_id": 42}).Namespace("derived")
+	r, ok := derived.(Reconfigurer)
+	require.True(t, ok)
+	require.NoError(t, r.Reconfigure(LoggingConfig{Service: "testing", Level: "debug"}))
+
+	// Outputs are shared with the logger derived from
+	logger.Debug("visible")
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "visible", entries[0]["message"])
+}
+
+func TestReconfigure_Invalid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+	defer logger.Close()
+
+	assert.Error(t, logger.(Reconfigurer).Reconfigure(LoggingConfig{Service: "testing", Level: "loud"}))
+
+	// Old outputs are kept
+	logger.Info("kept")
+	assert.Len(t, jsonEntries(t, buf), 1)
+}
+
+func TestReconfigure_Closed(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(&bytes.Buffer{}))
+	require.NoError(t, err)
+	require.NoError(t, logger.Close())
+
+	assert.Equal(t, errLoggerClosed, logger.(Reconfigurer).Reconfigure(LoggingConfig{Service: "testing"}))
+}
+
+func TestReconfigure_NotReconfigurable(t *testing.T) {
+	logger := NewWithCore(zapcore.NewNopCore())
+	assert.Equal(t, errNotReconfigurable, logger.(Reconfigurer).Reconfigure(LoggingConfig{Service: "testing"}))
+}
+
+// Run with -race: entries logged while outputs are replaced are neither lost nor written to closed outputs
+func TestReconfigure_Concurrent(t *testing.T) {
+	buf := &lockedBuffer{}
+	config := LoggingConfig{Service: "testing", Level: "info"}
+	logger, err := newLogger(config, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	const goroutines, perGoroutine = 8, 200
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			derived := logger.With(Fields{"goroutine": i})
+			for j := 0; j < perGoroutine; j++ {
+				derived.Info("concurrent")
+			}
+		}(i)
+	}
+
+	// Buffered stdout is closed by every other Reconfigure, flushing entries written to it
+	for i := 0; i < 20; i++ {
+		config.AsyncStdout = i%2 == 0
+		config.Sequence = i%3 == 0
+		require.NoError(t, logger.(Reconfigurer).Reconfigure(config))
+	}
+	wg.Wait()
+	require.NoError(t, logger.Close())
+
+	assert.Len(t, buf.entries(t), goroutines*perGoroutine)
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Entry recorded by Record
+type Entry struct {
+	Level   string
+	Time    time.Time
+	Message string
+
+	// All fields of the entry including service, namespace and fields of the logger.
+	// Values are decoded like GetField does, e.g. int64 for integers.
+	Fields Fields
+}
+
+// recorder keeps entries of active Record sessions, shared by loggers derived from the same New call
+type recorder struct {
+	// Number of active sessions, checked on every entry without the lock
+	active int32
+
+	mu       sync.Mutex
+	sessions map[*recordSession]struct{}
+}
+
+type recordSession struct {
+	entries []Entry
+}
+
+func (l loggerImpl) Record() (stop func() []Entry) {
+	if l.recorder == nil {
+		// Nothing is written by the zero value of loggerImpl
+		return func() []Entry { return nil }
+	}
+	return l.recorder.start()
+}
+
+func (r *recorder) start() func() []Entry {
+	session := &recordSession{}
+
+	r.mu.Lock()
+	if r.sessions == nil {
+		r.sessions = map[*recordSession]struct{}{}
+	}
+	r.sessions[session] = struct{}{}
+	atomic.StoreInt32(&r.active, int32(len(r.sessions)))
+	r.mu.Unlock()
+
+	var once sync.Once
+	return func() []Entry {
+		once.Do(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+
+			delete(r.sessions, session)
+			atomic.StoreInt32(&r.active, int32(len(r.sessions)))
+		})
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return session.entries
+	}
+}
+
+func (r *recorder) recording() bool {
+	return atomic.LoadInt32(&r.active) > 0
+}
+
+func (r *recorder) record(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for session := range r.sessions {
+		session.entries = append(session.entries, entry)
+	}
+}
+
+// recordCore passes entries of all levels to the recorder while it is recording, outputs still get only enabled ones
+type recordCore struct {
+	zapcore.Core
+
+	recorder *recorder
+	// Fields added by With, encoded only for recorded entries
+	fields []zapcore.Field
+}
+
+// fields are already added to entries by core, e.g. service, and are only recorded
+func newRecordCore(core zapcore.Core, r *recorder, fields []zapcore.Field) zapcore.Core {
+	return &recordCore{Core: core, recorder: r, fields: fields}
+}
+
+func (c *recordCore) Enabled(level zapcore.Level) bool {
+	return c.recorder.recording() || c.Core.Enabled(level)
+}
+
+func (c *recordCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recordCore{
+		Core:     c.Core.With(fields),
+		recorder: c.recorder,
+		fields:   append(c.fields[:len(c.fields):len(c.fields)], fields...),
+	}
+}
+
+func (c *recordCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if c.recorder.recording() {
+		ce = ce.AddCore(ent, recordWriter{c})
+	}
+	return ce
+}
+
+// recordWriter is added to checked entries being recorded, so outputs aren't written twice
+type recordWriter struct {
+	*recordCore
+}
+
+func (w recordWriter) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range w.fields {
+		field.AddTo(enc)
+	}
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+
+	w.recorder.record(Entry{
+		Level:   ent.Level.String(),
+		Time:    ent.Time,
+		Message: ent.Message,
+		Fields:  enc.Fields,
+	})
+	return nil
+}
+
+func (w recordWriter) Sync() error {
+	return nil
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRecord(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Namespace: "default", Level: "warn"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("before")
+
+	stop := logger.Record()
+	derived := logger.With(Fields{"order_id": 42})
+	derived.Debug("debug")
+	derived.Infof("info %d", 1)
+	logger.Namespace("payments").Warn("warn")
+	derived.WithLazy(func() Fields { return Fields{"lazy": "value"} }).Error("error")
+	derived.Typed().Debug("typed", zap.String("key", "value"))
+	assert.Panics(t, func() { logger.Panic("panic") })
+	entries := stop()
+
+	logger.Debug("after")
+	// Stopping twice returns the same entries
+	assert.Equal(t, entries, stop())
+
+	require.Len(t, entries, 6)
+	var levels, messages []string
+	for _, entry := range entries {
+		levels = append(levels, entry.Level)
+		messages = append(messages, entry.Message)
+		assert.Equal(t, "testing", entry.Fields["service"])
+		assert.False(t, entry.Time.IsZero())
+	}
+	assert.Equal(t, []string{"debug", "info", "warn", "error", "debug", "panic"}, levels)
+	assert.Equal(t, []string{"debug", "info 1", "warn", "error", "typed", "panic"}, messages)
+
+	assert.Equal(t, int64(42), entries[0].Fields["order_id"])
+	assert.Equal(t, "default", entries[1].Fields["namespace"])
+	assert.Equal(t, "payments", entries[2].Fields["namespace"])
+	assert.Equal(t, "value", entries[3].Fields["lazy"])
+	assert.Equal(t, "value", entries[4].Fields["key"])
+
+	// Outputs still get only enabled levels
+	var written []string
+	for _, entry := range buf.entries(t) {
+		written = append(written, entry["message"].(string))
+	}
+	assert.Equal(t, []string{"warn", "error", "panic"}, written)
+}
+
+func TestRecord_Sessions(t *testing.T) {
+	logger := NewWithCore(zapcore.NewCore(zapcore.NewJSONEncoder(newEncoderConfig()), &mockSink{}, zapcore.ErrorLevel))
+
+	outer := logger.Record()
+	logger.Info("first")
+
+	inner := logger.Record()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.Debug("concurrent")
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, inner(), 10)
+
+	logger.Warn("last")
+	assert.Len(t, outer(), 12)
+
+	// Disabled levels are dropped early again once recording stops
+	assert.False(t, logger.Enabled("warn"))
+}
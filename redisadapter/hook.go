@@ -0,0 +1,181 @@
+// Package redisadapter provides go-redis hook logging every command through logger.Logger
+// with its duration and error. Built for go-redis v9, where ProcessHook replaces BeforeProcess/AfterProcess of v8.
+package redisadapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/w84thesun/logger"
+)
+
+// Fields attached to command entries
+const (
+	CommandKey  = "redis.command"
+	KeyKey      = "redis.key"
+	DurationKey = "redis.duration_ms"
+	// Number of commands sent in the same pipeline or transaction
+	PipelineKey = "redis.pipeline_size"
+	AddrKey     = "redis.addr"
+)
+
+type options struct {
+	slowThreshold time.Duration
+	hashKeys      bool
+}
+
+// Configures NewHook
+type Option func(*options)
+
+// Logs commands slower than the threshold at warn level instead of debug.
+// Pipelined commands are compared by the duration of the whole pipeline.
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = d
+	}
+}
+
+// Logs sha256 of keys instead of their text, e.g. when keys contain emails
+func WithHashedKeys() Option {
+	return func(o *options) {
+		o.hashKeys = true
+	}
+}
+
+type hook struct {
+	base logger.Logger
+	options
+}
+
+// NewHook returns hook for redis.Client.AddHook. Uses logger from the context if stored with logger.NewContext, l otherwise.
+// redis.Nil replies are logged as successful commands.
+func NewHook(l logger.Logger, opts ...Option) redis.Hook {
+	h := &hook{base: l.WithComponent("redis")}
+	for _, opt := range opts {
+		opt(&h.options)
+	}
+	return h
+}
+
+func (h *hook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			l := logger.WithCallerOutside(h.logger(ctx), internalFunctions...)
+			l.With(logger.Fields{AddrKey: addr, "error": err.Error()}).Error("redis dial failed")
+		}
+		return conn, err
+	}
+}
+
+func (h *hook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.log(ctx, cmd, err, 0, time.Since(start))
+		return err
+	}
+}
+
+func (h *hook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		elapsed := time.Since(start)
+		for _, cmd := range cmds {
+			h.log(ctx, cmd, cmd.Err(), len(cmds), elapsed)
+		}
+		return err
+	}
+}
+
+func (h *hook) logger(ctx context.Context) logger.Logger {
+	if ctxLogger, ok := logger.FromContext(ctx); ok {
+		return ctxLogger.WithComponent("redis")
+	}
+	return h.base
+}
+
+// log writes an entry for the finished command, pipelineSize is zero for commands sent alone.
+// go-redis sets errors of single commands on them after hooks return, so err is passed separately.
+func (h *hook) log(ctx context.Context, cmd redis.Cmder, err error, pipelineSize int, elapsed time.Duration) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+
+	var level string
+	switch {
+	case err != nil:
+		level = "error"
+	case h.slowThreshold > 0 && elapsed > h.slowThreshold:
+		level = "warn"
+	default:
+		level = "debug"
+	}
+
+	l := h.logger(ctx)
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := logger.Fields{
+		CommandKey:  cmd.FullName(),
+		DurationKey: float64(elapsed) / float64(time.Millisecond),
+	}
+	if key, ok := h.key(cmd); ok {
+		fields[KeyKey] = key
+	}
+	if pipelineSize > 0 {
+		fields[PipelineKey] = pipelineSize
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	l = logger.WithCallerOutside(l, internalFunctions...).With(fields)
+
+	switch level {
+	case "error":
+		l.Error("redis " + cmd.Name() + " failed")
+	case "warn":
+		l.Warn("redis " + cmd.Name())
+	default:
+		l.Debug("redis " + cmd.Name())
+	}
+}
+
+// Prefixes of functions of go-redis and the adapter, skipped when reporting the caller
+var internalFunctions = []string{"github.com/redis/go-redis/", "github.com/w84thesun/logger/redisadapter."}
+
+// Commands whose arguments aren't keys and may be credentials, e.g. AUTH sent by go-redis on new connections
+// to servers without HELLO, or CONFIG SET requirepass
+var unkeyedCommands = map[string]bool{
+	"auth":    true,
+	"hello":   true,
+	"config":  true,
+	"migrate": true,
+	"client":  true,
+	"acl":     true,
+}
+
+// key returns the first argument after the command name, which is the key for most commands
+func (h *hook) key(cmd redis.Cmder) (string, bool) {
+	args := cmd.Args()
+	if len(args) < 2 || unkeyedCommands[strings.ToLower(cmd.Name())] {
+		return "", false
+	}
+
+	key := fmt.Sprint(args[1])
+	if h.hashKeys {
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:]), true
+	}
+	return key, true
+}
@@ -0,0 +1,198 @@
+package redisadapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func newClient(t *testing.T, l logger.Logger, opts ...Option) (*redis.Client, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	client.AddHook(NewHook(l, opts...))
+	return client, server
+}
+
+// withoutHandshake drops entries of commands sent by go-redis when a connection is opened
+func withoutHandshake(entries []map[string]interface{}) []map[string]interface{} {
+	var commands []map[string]interface{}
+	for _, entry := range entries {
+		switch entry[CommandKey] {
+		case "hello", "client":
+		default:
+			commands = append(commands, entry)
+		}
+	}
+	return commands
+}
+
+func TestHook(t *testing.T) {
+	l, entries := newTestLogger(t)
+	client, _ := newClient(t, l)
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, "user:1", "alice", 0).Err())
+	assert.Equal(t, redis.Nil, client.Get(ctx, "user:2").Err())
+	assert.Error(t, client.Incr(ctx, "user:1").Err())
+
+	got := withoutHandshake(entries())
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "debug", got[0]["level"])
+	assert.Equal(t, "redis set", got[0]["message"])
+	assert.Equal(t, "set", got[0][CommandKey])
+	assert.Equal(t, "user:1", got[0][KeyKey])
+	assert.Contains(t, got[0], DurationKey)
+	assert.NotContains(t, got[0], PipelineKey)
+
+	assert.Equal(t, "debug", got[1]["level"])
+	assert.Equal(t, "user:2", got[1][KeyKey])
+	assert.NotContains(t, got[1], "error")
+
+	assert.Equal(t, "error", got[2]["level"])
+	assert.Equal(t, "redis incr failed", got[2]["message"])
+	assert.Contains(t, got[2]["error"], "not an integer")
+
+	for _, entry := range got {
+		assert.Equal(t, "redis", entry[logger.ComponentKey])
+	}
+}
+
+func TestHook_Pipeline(t *testing.T) {
+	l, entries := newTestLogger(t)
+	client, _ := newClient(t, l)
+	ctx := context.Background()
+
+	_, err := client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, "a", "1", 0)
+		p.Get(ctx, "missing")
+		return nil
+	})
+	assert.Equal(t, redis.Nil, err)
+
+	got := withoutHandshake(entries())
+	require.Len(t, got, 2)
+	assert.Equal(t, "a", got[0][KeyKey])
+	assert.Equal(t, "missing", got[1][KeyKey])
+	for _, entry := range got {
+		assert.Equal(t, "debug", entry["level"])
+		assert.Equal(t, float64(2), entry[PipelineKey])
+	}
+}
+
+func TestHook_Options(t *testing.T) {
+	l, entries := newTestLogger(t)
+	client, server := newClient(t, l, WithHashedKeys(), WithSlowThreshold(time.Nanosecond))
+	server.SetTime(time.Now())
+
+	require.NoError(t, client.Set(context.Background(), "alice@example.com", "1", 0).Err())
+
+	got := withoutHandshake(entries())
+	require.Len(t, got, 1)
+	sum := sha256.Sum256([]byte("alice@example.com"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), got[0][KeyKey])
+	assert.Equal(t, "warn", got[0]["level"])
+}
+
+func TestHook_ContextLogger(t *testing.T) {
+	l, entries := newTestLogger(t)
+	client, _ := newClient(t, l)
+
+	ctx := logger.NewContext(context.Background(), l.With(logger.Fields{"request_id": "42"}))
+	require.NoError(t, client.Ping(ctx).Err())
+
+	got := withoutHandshake(entries())
+	require.Len(t, got, 1)
+	assert.Equal(t, "42", got[0]["request_id"])
+	assert.Equal(t, "redis", got[0][logger.ComponentKey])
+	assert.NotContains(t, got[0], KeyKey)
+}
+
+func TestHook_Dial(t *testing.T) {
+	l, entries := newTestLogger(t)
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	defer client.Close()
+	client.AddHook(NewHook(l))
+
+	assert.Error(t, client.Ping(context.Background()).Err())
+
+	got := entries()
+	require.NotEmpty(t, got)
+	assert.Equal(t, "redis dial failed", got[0]["message"])
+	assert.Equal(t, addr, got[0][AddrKey])
+}
+
+func TestHook_Credentials(t *testing.T) {
+	l, entries := newTestLogger(t)
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("alice", "s3cret")
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), Username: "alice", Password: "s3cret"})
+	defer client.Close()
+	client.AddHook(NewHook(l))
+	ctx := context.Background()
+
+	require.NoError(t, client.Do(ctx, "auth", "alice", "s3cret").Err())
+	// Not supported by miniredis, still logged as failed
+	_ = client.ConfigSet(ctx, "requirepass", "hunter2").Err()
+	require.NoError(t, client.Set(ctx, "user:1", "alice", 0).Err())
+
+	var authLogged bool
+	for _, entry := range entries() {
+		if entry[CommandKey] == "auth" {
+			authLogged = true
+		}
+		switch entry[CommandKey] {
+		case "auth", "hello", "config set":
+			assert.NotContains(t, entry, KeyKey, entry)
+		}
+		data, err := json.Marshal(entry)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "s3cret")
+	}
+	assert.True(t, authLogged)
+}
+
+func TestHook_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "debug", Caller: true})
+	client, _ := newClient(t, l)
+	ctx := context.Background()
+
+	client.Set(ctx, "k", "v", 0)
+	_, _, single, _ := runtime.Caller(0)
+	_, _ = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "k")
+		return nil
+	})
+	_, _, pipelined, _ := runtime.Caller(0)
+
+	// Reported at the calls of the client, not in go-redis or the hook
+	got := withoutHandshake(entries())
+	require.Len(t, got, 2)
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "hook_test.go:"+strconv.Itoa(single-1)), got[0]["caller"])
+	assert.True(t, strings.HasSuffix(got[1]["caller"].(string), "hook_test.go:"+strconv.Itoa(pipelined-4)), got[1]["caller"])
+}
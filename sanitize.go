@@ -0,0 +1,246 @@
+package logger
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Nesting of values converted by sanitize, deeper ones are replaced, e.g. of cyclic pointers
+const maxSanitizeDepth = 32
+
+// sanitizedField converts a value like zap.Any, guarding against values which would fail or panic when encoded:
+// channels and funcs are replaced by placeholders like "<func()>", reflected values are encoded by sanitizedValue
+// and panics of MarshalLogObject and MarshalLogArray are returned as encoding errors, so zap writes the entry
+// with "<key>Error" field. Reports whether the field differs from zap.Any one.
+func sanitizedField(key string, value interface{}) (zap.Field, bool) {
+	switch marshaler := value.(type) {
+	case zapcore.ObjectMarshaler:
+		return zap.Object(key, recoveringObject{marshaler}), true
+	case zapcore.ArrayMarshaler:
+		return zap.Array(key, recoveringArray{marshaler}), true
+	}
+
+	field := zap.Any(key, value)
+	if field.Type != zapcore.ReflectType || value == nil {
+		return field, false
+	}
+
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return zap.String(key, placeholder(reflect.TypeOf(value))), true
+	}
+	return zap.Reflect(key, sanitizedValue{value}), true
+}
+
+// placeholder describes a value which can't be encoded, e.g. "<chan int>"
+func placeholder(typ reflect.Type) string {
+	return "<" + typ.String() + ">"
+}
+
+// recovered converts panic of encoding into an error
+func recovered(err *error) {
+	if p := recover(); p != nil {
+		*err = fmt.Errorf("panic: %v", p)
+	}
+}
+
+type recoveringObject struct {
+	marshaler zapcore.ObjectMarshaler
+}
+
+func (o recoveringObject) MarshalLogObject(enc zapcore.ObjectEncoder) (err error) {
+	defer recovered(&err)
+	return o.marshaler.MarshalLogObject(enc)
+}
+
+type recoveringArray struct {
+	marshaler zapcore.ArrayMarshaler
+}
+
+func (a recoveringArray) MarshalLogArray(enc zapcore.ArrayEncoder) (err error) {
+	defer recovered(&err)
+	return a.marshaler.MarshalLogArray(enc)
+}
+
+// sanitizedValue is encoded as JSON of the value. If it fails, e.g. the value has a NaN float, a func field
+// or a MarshalJSON method which panics, unencodable parts are replaced by placeholders and the rest is kept.
+type sanitizedValue struct {
+	value interface{}
+}
+
+func (v sanitizedValue) MarshalJSON() ([]byte, error) {
+	data, err := marshalJSON(v.value)
+	if err == nil {
+		return data, nil
+	}
+
+	// Placeholders are kept readable in the output
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(sanitize(reflect.ValueOf(v.value), 0)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// marshalJSON encodes the value without escaping HTML, like zap encodes reflected values
+func marshalJSON(value interface{}) (data []byte, err error) {
+	defer recovered(&err)
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func marshalText(marshaler encoding.TextMarshaler) (text []byte, err error) {
+	defer recovered(&err)
+	return marshaler.MarshalText()
+}
+
+// sanitize converts the value into one encoded by encoding/json the same way, except for unencodable parts
+func sanitize(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if !v.CanInterface() {
+		return placeholder(v.Type())
+	}
+	if depth > maxSanitizeDepth {
+		return "<max depth exceeded>"
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	switch marshaler := v.Interface().(type) {
+	case json.Marshaler:
+		data, err := marshalJSON(marshaler)
+		if err != nil {
+			return "<" + err.Error() + ">"
+		}
+		return json.RawMessage(data)
+	case encoding.TextMarshaler:
+		text, err := marshalText(marshaler)
+		if err != nil {
+			return "<" + err.Error() + ">"
+		}
+		return string(text)
+	}
+
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return placeholder(v.Type())
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	case reflect.Float32, reflect.Float64:
+		// Written the same way as floats of zap fields
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return "NaN"
+		case math.IsInf(f, 1):
+			return "+Inf"
+		case math.IsInf(f, -1):
+			return "-Inf"
+		}
+		return v.Interface()
+	case reflect.Ptr, reflect.Interface:
+		return sanitize(v.Elem(), depth+1)
+	case reflect.Struct:
+		fields := make(map[string]interface{}, v.NumField())
+		sanitizeStruct(v, fields, depth)
+		return fields
+	case reflect.Map:
+		entries := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries[mapKey(iter.Key())] = sanitize(iter.Value(), depth+1)
+		}
+		return entries
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			// Base64 like encoding/json
+			return v.Bytes()
+		}
+		elems := make([]interface{}, v.Len())
+		for i := range elems {
+			elems[i] = sanitize(v.Index(i), depth+1)
+		}
+		return elems
+	default:
+		return v.Interface()
+	}
+}
+
+// sanitizeStruct adds exported fields of the struct by their json tag names, fields of embedded structs are promoted
+func sanitizeStruct(v reflect.Value, fields map[string]interface{}, depth int) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name, opts := field.Name, ""
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if comma := strings.IndexByte(tag, ','); comma >= 0 {
+				tag, opts = tag[:comma], tag[comma:]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+
+		value := v.Field(i)
+		if field.Anonymous && name == field.Name {
+			embedded := value
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				sanitizeStruct(embedded, fields, depth+1)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if strings.Contains(opts, ",omitempty") && value.IsZero() {
+			continue
+		}
+		fields[name] = sanitize(value, depth+1)
+	}
+}
+
+// mapKey converts the key like encoding/json does
+func mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+	if marshaler, ok := key.Interface().(encoding.TextMarshaler); ok {
+		if text, err := marshalText(marshaler); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(key.Interface())
+}
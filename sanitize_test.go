@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type panickingJSON struct{}
+
+func (panickingJSON) MarshalJSON() ([]byte, error) {
+	panic("boom")
+}
+
+type panickingObject struct{}
+
+func (panickingObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("before", "panic")
+	panic("boom")
+}
+
+type withUnencodable struct {
+	Name     string        `json:"name"`
+	Callback func()        `json:"callback"`
+	Ratio    float64       `json:"ratio"`
+	Payload  panickingJSON `json:"payload"`
+	Skipped  string        `json:"-"`
+	Empty    string        `json:"empty,omitempty"`
+	hidden   chan int
+	embedded
+}
+
+type embedded struct {
+	Inner map[string]float64
+}
+
+func TestSanitize(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{
+		"chan":  make(chan int),
+		"func":  func() {},
+		"nan":   math.NaN(),
+		"inf":   math.Inf(-1),
+		"json":  panickingJSON{},
+		"valid": map[string]int{"a": 1},
+		"struct": withUnencodable{
+			Name:     "nested",
+			Callback: func() {},
+			Ratio:    math.Inf(1),
+			Skipped:  "skipped",
+			hidden:   make(chan int),
+			embedded: embedded{Inner: map[string]float64{"nan": math.NaN()}},
+		},
+	}).Info("kept")
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+
+	assert.Equal(t, "kept", entry["message"])
+	assert.Equal(t, "<chan int>", entry["chan"])
+	assert.Equal(t, "<func()>", entry["func"])
+	assert.Equal(t, "NaN", entry["nan"])
+	assert.Equal(t, "-Inf", entry["inf"])
+	assert.Equal(t, "<panic: boom>", entry["json"])
+	assert.Equal(t, map[string]interface{}{"a": float64(1)}, entry["valid"])
+	assert.Equal(t, map[string]interface{}{
+		"name":     "nested",
+		"callback": "<func()>",
+		"ratio":    "+Inf",
+		"payload":  "<panic: boom>",
+		"Inner":    map[string]interface{}{"nan": "NaN"},
+	}, entry["struct"])
+}
+
+func TestSanitize_PanickingObject(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"object": panickingObject{}}).Error("kept")
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "kept", entries[0]["message"])
+	assert.Equal(t, map[string]interface{}{"before": "panic"}, entries[0]["object"])
+	assert.Equal(t, "panic: boom", entries[0]["objectError"])
+}
+
+func TestSanitize_HTMLNotEscaped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	// Reflected values are encoded like zap does, without escaping HTML
+	logger.With(Fields{"m": map[string]string{"h": "<a>&"}, "s": "<a>&"}).Info("html")
+	logger.With(Fields{"m": map[string]interface{}{"h": "<a>&", "f": func() {}}}).Info("sanitized")
+
+	assert.Contains(t, buf.String(), `"m":{"h":"<a>&"}`)
+	assert.Contains(t, buf.String(), `"s":"<a>&"`)
+	assert.Contains(t, buf.String(), `"h":"<a>&"}`)
+	assert.NotContains(t, buf.String(), `\u003c`)
+}
+
+func TestSanitize_Flatten(t *testing.T) {
+	flat := Fields{"func": func() {}, "plain": 1}.Flatten()
+	assert.Contains(t, flat, zap.String("func", "<func()>"))
+	assert.Contains(t, flat, "plain")
+
+	// Sugared loggers of zap accept the sanitized fields as well
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(buf), zap.InfoLevel)
+	zap.New(core).Sugar().With(Fields{"json": panickingJSON{}}.Flatten()...).Info("kept")
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "<panic: boom>", entry["json"])
+}
+
+func TestSanitize_Cycle(t *testing.T) {
+	type node struct {
+		Next *node
+		Fn   func()
+	}
+	cyclic := &node{}
+	cyclic.Next = cyclic
+
+	data, err := sanitizedValue{cyclic}.MarshalJSON()
+	require.NoError(t, err)
+	assert.True(t, json.Valid(data))
+	assert.Contains(t, string(data), "<max depth exceeded>")
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// seqCore attaches monotonically increasing "seq" field to every written entry.
+// Counter is shared by all loggers derived with With/Namespace from the same New call.
+type seqCore struct {
+	zapcore.Core
+
+	seq *uint64
+}
+
+func newSeqCore(core zapcore.Core) zapcore.Core {
+	return &seqCore{Core: core, seq: new(uint64)}
+}
+
+func (c *seqCore) With(fields []zapcore.Field) zapcore.Core {
+	return &seqCore{Core: c.Core.With(fields), seq: c.seq}
+}
+
+func (c *seqCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *seqCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	withSeq := make([]zapcore.Field, 0, len(fields)+1)
+	withSeq = append(withSeq, fields...)
+	withSeq = append(withSeq, zap.Uint64("seq", atomic.AddUint64(c.seq, 1)))
+
+	return c.Core.Write(ent, withSeq)
+}
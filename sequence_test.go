@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestSequence(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:  "testing",
+		Level:    "info",
+		Sequence: true,
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	derived := logger.Namespace("derived").With(Fields{"a": "b"})
+	for i := 0; i < 10; i++ {
+		logger.Info("parent")
+		derived.Warn("derived")
+		// Disabled entries must not consume numbers
+		logger.Debug("skipped")
+	}
+
+	var last float64
+	scanner := bufio.NewScanner(buf)
+	lines := 0
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+
+		seq, ok := entry["seq"].(float64)
+		require.True(t, ok, "seq is missing in %s", scanner.Text())
+		assert.Greater(t, seq, last)
+		last = seq
+		lines++
+	}
+	assert.Equal(t, 20, lines)
+	assert.Equal(t, float64(20), last)
+}
+
+func TestSequence_Disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("hello")
+
+	assert.NotContains(t, buf.String(), `"seq"`)
+}
@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys emitted after entry keys of the format (timestamp, level, logger name, caller, message and stacktrace)
+// but before any other field when SortKeys is enabled, in this exact order.
+var fixedKeys = []string{"service", "namespace"}
+
+// sortedCore is a core which emits fixed keys first and all other fields ordered lexicographically.
+// Zap encodes context fields at With time, so they are kept here unencoded and sorted on every Write.
+// JSON core also orders entry keys like timestamp, level and caller, console one keeps them positional.
+type sortedCore struct {
+	zapcore.LevelEnabler
+
+	base    zapcore.Core
+	context []zapcore.Field
+
+	timeKey, levelKey, messageKey     string
+	nameKey, callerKey, stacktraceKey string
+	levelNames                        map[zapcore.Level]string
+	encodeName                        zapcore.NameEncoder
+	encodeCaller                      zapcore.CallerEncoder
+
+	// Positions of keys going first
+	order map[string]int
+}
+
+func newSortedCore(ws zapcore.WriteSyncer, enab zapcore.LevelEnabler, encoderConfig zapcore.EncoderConfig) zapcore.Core {
+	c := &sortedCore{
+		LevelEnabler:  enab,
+		timeKey:       encoderConfig.TimeKey,
+		levelKey:      encoderConfig.LevelKey,
+		nameKey:       encoderConfig.NameKey,
+		callerKey:     encoderConfig.CallerKey,
+		messageKey:    encoderConfig.MessageKey,
+		stacktraceKey: encoderConfig.StacktraceKey,
+		levelNames:    encodeLevelNames(encoderConfig.EncodeLevel),
+		encodeName:    encoderConfig.EncodeName,
+		encodeCaller:  encoderConfig.EncodeCaller,
+		order:         map[string]int{},
+	}
+	if c.encodeName == nil {
+		c.encodeName = zapcore.FullNameEncoder
+	}
+	if c.encodeCaller == nil {
+		c.encodeCaller = zapcore.ShortCallerEncoder
+	}
+
+	entryKeys := []string{c.timeKey, c.levelKey, c.nameKey, c.callerKey, c.messageKey, c.stacktraceKey}
+	for _, key := range append(entryKeys, fixedKeys...) {
+		if _, ok := c.order[key]; !ok && key != "" {
+			c.order[key] = len(c.order)
+		}
+	}
+
+	// Entry keys are added as regular fields on Write to take part in ordering
+	encoderConfig.TimeKey = ""
+	encoderConfig.LevelKey = ""
+	encoderConfig.NameKey = ""
+	encoderConfig.CallerKey = ""
+	encoderConfig.MessageKey = ""
+	encoderConfig.StacktraceKey = ""
+	c.base = zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), ws, enab)
+
+	return c
+}
+
+func newSortedConsoleCore(ws zapcore.WriteSyncer, enab zapcore.LevelEnabler, encoderConfig zapcore.EncoderConfig) zapcore.Core {
+	c := &sortedCore{
+		LevelEnabler: enab,
+		order:        map[string]int{},
+	}
+
+	for i, key := range fixedKeys {
+		c.order[key] = i
+	}
+	c.base = zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), ws, enab)
+
+	return c
+}
+
+func (c *sortedCore) With(fields []zapcore.Field) zapcore.Core {
+	context := make([]zapcore.Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+
+	clone := *c
+	clone.context = context
+	return &clone
+}
+
+func (c *sortedCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sortedCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	all := make([]zapcore.Field, 0, 6+len(c.context)+len(fields))
+	// Console core keeps entry keys positional, so it has none of them
+	if c.timeKey != "" {
+		all = append(all, zap.Time(c.timeKey, ent.Time))
+	}
+	if c.levelKey != "" {
+		all = append(all, zap.String(c.levelKey, c.levelNames[ent.Level]))
+	}
+	if c.nameKey != "" && ent.LoggerName != "" {
+		all = append(all, zap.Any(c.nameKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			c.encodeName(ent.LoggerName, enc)
+		})))
+	}
+	if c.callerKey != "" && ent.Caller.Defined {
+		all = append(all, zap.Any(c.callerKey, encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) {
+			c.encodeCaller(ent.Caller, enc)
+		})))
+	}
+	if c.messageKey != "" {
+		all = append(all, zap.String(c.messageKey, ent.Message))
+	}
+	if c.stacktraceKey != "" && ent.Stack != "" {
+		all = append(all, zap.String(c.stacktraceKey, ent.Stack))
+	}
+	all = append(all, c.context...)
+	all = append(all, fields...)
+
+	c.sortFields(all)
+
+	return c.base.Write(ent, all)
+}
+
+func (c *sortedCore) Sync() error {
+	return c.base.Sync()
+}
+
+func (c *sortedCore) sortFields(fields []zapcore.Field) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, fi := c.order[fields[i].Key]
+		rj, fj := c.order[fields[j].Key]
+
+		switch {
+		case fi && fj:
+			return ri < rj
+		case fi || fj:
+			return fi
+		default:
+			return fields[i].Key < fields[j].Key
+		}
+	})
+}
+
+// encodeLevelNames precomputes names of all levels produced by the level encoder
+func encodeLevelNames(encodeLevel zapcore.LevelEncoder) map[zapcore.Level]string {
+	names := make(map[zapcore.Level]string)
+
+	for level := zapcore.DebugLevel; level <= zapcore.FatalLevel; level++ {
+		level := level
+		names[level] = level.String()
+		if encoded := encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) { encodeLevel(level, enc) }); encoded != nil {
+			names[level] = fmt.Sprint(encoded)
+		}
+	}
+
+	return names
+}
+
+// encodePrimitive returns the single value appended by an entry key encoder, nil if it appended none or several
+func encodePrimitive(encode func(zapcore.PrimitiveArrayEncoder)) interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	_ = enc.AddArray("value", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		encode(arr)
+		return nil
+	}))
+
+	if encoded, ok := enc.Fields["value"].([]interface{}); ok && len(encoded) == 1 {
+		return encoded[0]
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// jsonKeys returns top-level keys of a JSON object in the order they were encoded
+func jsonKeys(t *testing.T, line string) []string {
+	dec := json.NewDecoder(strings.NewReader(line))
+
+	_, err := dec.Token()
+	require.NoError(t, err)
+
+	var keys []string
+	for dec.More() {
+		key, err := dec.Token()
+		require.NoError(t, err)
+		keys = append(keys, key.(string))
+
+		var skip json.RawMessage
+		require.NoError(t, dec.Decode(&skip))
+	}
+	return keys
+}
+
+func TestSortKeys(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:   "testing",
+		Namespace: "default",
+		Level:     "info",
+		SortKeys:  true,
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"c": 3, "a": 1, "b": 2}).With(Fields{"aa": 0}).Info("hello")
+
+	want := []string{"@timestamp", "level", "message", "service", "namespace", "a", "aa", "b", "c"}
+	assert.Equal(t, want, jsonKeys(t, buf.String()))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "hello", entry["message"])
+	assert.Equal(t, "testing", entry["service"])
+	assert.Equal(t, "default", entry["namespace"])
+}
+
+func TestSortKeys_CallerAndStacktrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:         "testing",
+		Level:           "info",
+		SortKeys:        true,
+		Caller:          true,
+		StacktraceLevel: "error",
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"b": 2, "a": 1}).Error("failed")
+
+	want := []string{"@timestamp", "level", "caller", "message", "stacktrace", "service", "namespace", "a", "b"}
+	assert.Equal(t, want, jsonKeys(t, buf.String()))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Contains(t, entry["caller"], "sort_test.go:")
+	assert.Contains(t, entry["stacktrace"], "TestSortKeys_CallerAndStacktrace")
+	assert.Equal(t, 1, strings.Count(buf.String(), `"caller"`))
+	assert.Equal(t, 1, strings.Count(buf.String(), `"stacktrace"`))
+}
+
+func TestSortKeys_Stable(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:  "testing",
+		SortKeys: true,
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	fields := Fields{"z": 1, "y": 2, "x": 3, "w": 4, "v": 5}
+	for i := 0; i < 10; i++ {
+		logger.With(fields).Info("hello")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 10)
+
+	first := jsonKeys(t, lines[0])
+	for _, line := range lines[1:] {
+		assert.Equal(t, first, jsonKeys(t, line))
+	}
+}
+
+// Sorting costs an extra fields slice and a sort per entry,
+// compare with BenchmarkLoggerImpl_InfoUnsorted. Roughly 25% slower with 2 extra allocs per entry.
+func BenchmarkLoggerImpl_InfoSortKeys(b *testing.B) {
+	benchmarkSortKeys(b, true)
+}
+
+func BenchmarkLoggerImpl_InfoUnsorted(b *testing.B) {
+	benchmarkSortKeys(b, false)
+}
+
+func benchmarkSortKeys(b *testing.B, sortKeys bool) {
+	logger, _ := newLogger(LoggingConfig{
+		Service:   "testing",
+		Namespace: "default",
+		Level:     "info",
+		SortKeys:  sortKeys,
+	}, zapcore.AddSync(ioutil.Discard))
+
+	fields := Fields{"a": "b", "c": 1, "e": true}
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.With(fields).Info("hello there")
+	}
+}
+
+func TestConsoleSeparator(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		Namespace:        "default",
+		Level:            "info",
+		FormatStdout:     FormatPretty,
+		ConsoleSeparator: " | ",
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"c": 3, "a": 1}).With(Fields{"b": 2}).Info("hello")
+
+	parts := strings.Split(strings.TrimSpace(buf.String()), " | ")
+	require.Len(t, parts, 4)
+	assert.Equal(t, "info", parts[1])
+	assert.Equal(t, "hello", parts[2])
+	assert.Equal(t, []string{"service", "namespace", "a", "b", "c"}, jsonKeys(t, parts[3]))
+}
+
+func TestConsoleSeparator_Default(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", FormatStdout: FormatPretty}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"z": 1}).Info("hello")
+
+	parts := strings.Split(strings.TrimSpace(buf.String()), "\t")
+	require.Len(t, parts, 4)
+	assert.Equal(t, []string{"service", "namespace", "z"}, jsonKeys(t, parts[3]))
+}
@@ -0,0 +1,279 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+)
+
+var errNamedArgs = errors.New("sql: driver does not support the use of Named Parameters")
+
+// Returned like database/sql does for options which drivers without driver.ConnBeginTx can't apply
+var (
+	errIsolationLevel = errors.New("sql: driver does not support non-default isolation level")
+	errReadOnly       = errors.New("sql: driver does not support read-only transactions")
+)
+
+// wrappedConn implements optional context interfaces for every connection returning driver.ErrSkip
+// when the parent doesn't support them, so database/sql falls back the same way it does without the wrapper.
+// ExecContext and QueryContext pass statements to legacy driver.Execer and driver.Queryer of the parent,
+// since database/sql only calls them on connections without the context interfaces.
+type wrappedConn struct {
+	parent driver.Conn
+	log    *sqlLogger
+}
+
+var (
+	_ driver.ConnPrepareContext = &wrappedConn{}
+	_ driver.ConnBeginTx        = &wrappedConn{}
+	_ driver.ExecerContext      = &wrappedConn{}
+	_ driver.QueryerContext     = &wrappedConn{}
+	_ driver.Pinger             = &wrappedConn{}
+	_ driver.SessionResetter    = &wrappedConn{}
+	_ driver.NamedValueChecker  = &wrappedConn{}
+	_ driver.Validator          = &wrappedConn{}
+)
+
+func (c *wrappedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.parent.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.parent.Prepare(query)
+	}
+
+	c.log.log(ctx, "prepare", query, 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedStmt{parent: stmt, query: query, log: c.log}, nil
+}
+
+func (c *wrappedConn) Close() error {
+	return c.parent.Close()
+}
+
+//nolint:staticcheck // required by driver.Conn
+func (c *wrappedConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+
+	var tx driver.Tx
+	var err error
+	switch b, ok := c.parent.(driver.ConnBeginTx); {
+	case ok:
+		tx, err = b.BeginTx(ctx, opts)
+	case opts.Isolation != driver.IsolationLevel(sql.LevelDefault):
+		err = errIsolationLevel
+	case opts.ReadOnly:
+		err = errReadOnly
+	default:
+		//nolint:staticcheck // fallback for drivers without BeginTx
+		tx, err = c.parent.Begin()
+	}
+
+	c.log.log(ctx, "begin", "", 0, start, err)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedTx{parent: tx, ctx: ctx, log: c.log}, nil
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var res driver.Result
+	var err error
+	switch e := c.parent.(type) {
+	case driver.ExecerContext:
+		res, err = e.ExecContext(ctx, query, args)
+	case driver.Execer: //nolint:staticcheck // fallback for drivers without ExecContext
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			if err = ctx.Err(); err == nil {
+				res, err = e.Exec(query, values)
+			}
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	c.log.log(ctx, "exec", query, len(args), start, err)
+	return res, err
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	switch q := c.parent.(type) {
+	case driver.QueryerContext:
+		rows, err = q.QueryContext(ctx, query, args)
+	case driver.Queryer: //nolint:staticcheck // fallback for drivers without QueryContext
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			if err = ctx.Err(); err == nil {
+				rows, err = q.Query(query, values)
+			}
+		}
+	default:
+		return nil, driver.ErrSkip
+	}
+
+	c.log.log(ctx, "query", query, len(args), start, err)
+	return rows, err
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if p, ok := c.parent.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.parent.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := c.parent.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if v, ok := c.parent.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+type wrappedStmt struct {
+	parent driver.Stmt
+	query  string
+	log    *sqlLogger
+}
+
+var (
+	_ driver.StmtExecContext   = &wrappedStmt{}
+	_ driver.StmtQueryContext  = &wrappedStmt{}
+	_ driver.NamedValueChecker = &wrappedStmt{}
+)
+
+func (s *wrappedStmt) Close() error {
+	return s.parent.Close()
+}
+
+func (s *wrappedStmt) NumInput() int {
+	return s.parent.NumInput()
+}
+
+//nolint:staticcheck // required by driver.Stmt
+func (s *wrappedStmt) Exec(args []driver.Value) (driver.Result, error) {
+	start := time.Now()
+	res, err := s.parent.Exec(args)
+	s.log.log(context.Background(), "exec", s.query, len(args), start, err)
+	return res, err
+}
+
+//nolint:staticcheck // required by driver.Stmt
+func (s *wrappedStmt) Query(args []driver.Value) (driver.Rows, error) {
+	start := time.Now()
+	rows, err := s.parent.Query(args)
+	s.log.log(context.Background(), "query", s.query, len(args), start, err)
+	return rows, err
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	var res driver.Result
+	var err error
+	if e, ok := s.parent.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			//nolint:staticcheck // fallback for drivers without ExecContext
+			res, err = s.parent.Exec(values)
+		}
+	}
+
+	s.log.log(ctx, "exec", s.query, len(args), start, err)
+	return res, err
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	var rows driver.Rows
+	var err error
+	if q, ok := s.parent.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedToValues(args); err == nil {
+			//nolint:staticcheck // fallback for drivers without QueryContext
+			rows, err = s.parent.Query(values)
+		}
+	}
+
+	s.log.log(ctx, "query", s.query, len(args), start, err)
+	return rows, err
+}
+
+func (s *wrappedStmt) CheckNamedValue(v *driver.NamedValue) error {
+	if n, ok := s.parent.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(v)
+	}
+	return driver.ErrSkip
+}
+
+type wrappedTx struct {
+	parent driver.Tx
+	ctx    context.Context
+	log    *sqlLogger
+}
+
+func (t *wrappedTx) Commit() error {
+	start := time.Now()
+	err := t.parent.Commit()
+	t.log.log(t.ctx, "commit", "", 0, start, err)
+	return err
+}
+
+func (t *wrappedTx) Rollback() error {
+	start := time.Now()
+	err := t.parent.Rollback()
+	t.log.log(t.ctx, "rollback", "", 0, start, err)
+	return err
+}
+
+// namedToValues mirrors database/sql conversion for drivers without context methods
+func namedToValues(named []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(named))
+	for i, nv := range named {
+		if nv.Name != "" {
+			return nil, errNamedArgs
+		}
+		values[i] = nv.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,188 @@
+// Package sqladapter wraps database/sql drivers to log every statement through logger.Logger.
+// Only the standard library is used, so it adds no dependencies.
+package sqladapter
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/w84thesun/logger"
+)
+
+// Fields attached to statement entries
+const (
+	OperationKey = "db.operation"
+	StatementKey = "db.statement"
+	ArgsKey      = "db.args"
+	DurationKey  = "db.duration_ms"
+)
+
+type options struct {
+	slowThreshold  time.Duration
+	statementLimit int
+	hashStatements bool
+}
+
+// Configures WrapDriver and WrapConnector
+type Option func(*options)
+
+// Logs operations slower than the threshold at warn level instead of debug
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		o.slowThreshold = d
+	}
+}
+
+// Truncates logged statements to the number of bytes, less if it would cut a multibyte character
+func WithStatementLimit(n int) Option {
+	return func(o *options) {
+		o.statementLimit = n
+	}
+}
+
+// Logs sha256 of statements instead of their text
+func WithHashedStatements() Option {
+	return func(o *options) {
+		o.hashStatements = true
+	}
+}
+
+type sqlLogger struct {
+	base logger.Logger
+	options
+}
+
+func newSQLLogger(l logger.Logger, opts []Option) *sqlLogger {
+	s := &sqlLogger{base: l.WithComponent("sql")}
+	for _, opt := range opts {
+		opt(&s.options)
+	}
+	return s
+}
+
+// WrapDriver logs Exec, Query, Prepare, Begin, Commit and Rollback of connections opened by the driver.
+// Register the result with sql.Register to use it with sql.Open.
+func WrapDriver(d driver.Driver, l logger.Logger, opts ...Option) driver.Driver {
+	return &wrappedDriver{parent: d, log: newSQLLogger(l, opts)}
+}
+
+// WrapConnector is the same as WrapDriver for sql.OpenDB
+func WrapConnector(c driver.Connector, l logger.Logger, opts ...Option) driver.Connector {
+	log := newSQLLogger(l, opts)
+	return &wrappedConnector{parent: c, driver: &wrappedDriver{parent: c.Driver(), log: log}, log: log}
+}
+
+type wrappedDriver struct {
+	parent driver.Driver
+	log    *sqlLogger
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.parent.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{parent: conn, log: d.log}, nil
+}
+
+type wrappedConnector struct {
+	parent driver.Connector
+	driver driver.Driver
+	log    *sqlLogger
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.parent.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedConn{parent: conn, log: c.log}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the parent if it's an io.Closer, sql.DB.Close does it for connectors
+func (c *wrappedConnector) Close() error {
+	if closer, ok := c.parent.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// log writes an entry for the finished operation, driver.ErrSkip is not logged since it only asks
+// database/sql to take another path
+func (s *sqlLogger) log(ctx context.Context, operation, statement string, args int, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	elapsed := time.Since(start)
+
+	var level string
+	switch {
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		level = "warn"
+	case err != nil:
+		level = "error"
+	case s.slowThreshold > 0 && elapsed > s.slowThreshold:
+		level = "warn"
+	default:
+		level = "debug"
+	}
+
+	l := s.base
+	if ctxLogger, ok := logger.FromContext(ctx); ok {
+		l = ctxLogger.WithComponent("sql")
+	}
+	if !l.Enabled(level) {
+		return
+	}
+
+	fields := logger.Fields{
+		OperationKey: operation,
+		DurationKey:  float64(elapsed) / float64(time.Millisecond),
+	}
+	if statement != "" {
+		fields[StatementKey] = s.statement(statement)
+		fields[ArgsKey] = args
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	l = logger.WithCallerOutside(l, internalFunctions...).With(fields)
+
+	switch level {
+	case "error":
+		l.Error("sql " + operation + " failed")
+	case "warn":
+		l.Warn("sql " + operation)
+	default:
+		l.Debug("sql " + operation)
+	}
+}
+
+// Prefixes of functions of database/sql and the adapter, skipped when reporting the caller
+var internalFunctions = []string{"database/sql.", "github.com/w84thesun/logger/sqladapter."}
+
+func (s *sqlLogger) statement(statement string) string {
+	if s.hashStatements {
+		sum := sha256.Sum256([]byte(statement))
+		return hex.EncodeToString(sum[:])
+	}
+	if s.statementLimit > 0 && len(statement) > s.statementLimit {
+		n := s.statementLimit
+		for n > 0 && !utf8.RuneStart(statement[n]) {
+			n--
+		}
+		return statement[:n] + "..."
+	}
+	return statement
+}
@@ -0,0 +1,254 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+func (fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+func openDB(t *testing.T, l logger.Logger, opts ...Option) *sql.DB {
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, l, opts...))
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// driverConnector opens connections of the driver, so tests don't register it globally
+type driverConnector struct {
+	driver driver.Driver
+}
+
+func (c driverConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open("")
+}
+
+func (c driverConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+func TestWrapDriver(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	db := sql.OpenDB(driverConnector{driver: WrapDriver(fakeDriver{}, l)})
+	defer db.Close()
+
+	res, err := db.Exec("INSERT INTO orders VALUES (?, ?)", 1, "a")
+	require.NoError(t, err)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), affected)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "debug", got[0]["level"])
+	assert.Equal(t, "exec", got[0][OperationKey])
+	assert.Equal(t, "INSERT INTO orders VALUES (?, ?)", got[0][StatementKey])
+	assert.Equal(t, float64(2), got[0][ArgsKey])
+	assert.Equal(t, "sql", got[0]["component"])
+	assert.Contains(t, got[0], DurationKey)
+}
+
+func TestWrapConnector_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "debug", Caller: true})
+	db := openDB(t, l)
+
+	_, err := db.Exec("INSERT INTO orders VALUES (?)", 1)
+	_, _, line, _ := runtime.Caller(0)
+	require.NoError(t, err)
+
+	// Reported at the call of Exec, not in database/sql or the adapter
+	got := entries()
+	require.Len(t, got, 1)
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "driver_test.go:"+strconv.Itoa(line-1)), got[0]["caller"])
+}
+
+func TestWrapConnector_ErrSkip(t *testing.T) {
+	l, entries := newTestLogger(t)
+	db := openDB(t, l)
+
+	// Parent skips ExecContext, database/sql prepares the statement instead
+	res, err := db.Exec("SKIP INSERT", 1)
+	require.NoError(t, err)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affected)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "prepare", got[0][OperationKey])
+	assert.Equal(t, "exec", got[1][OperationKey])
+	for _, entry := range got {
+		assert.Equal(t, "debug", entry["level"])
+		assert.NotContains(t, entry, "error")
+	}
+}
+
+func TestWrapConnector_Legacy(t *testing.T) {
+	l, entries := newTestLogger(t)
+	connector := &legacyConnector{conn: &legacyConn{}}
+	db := sql.OpenDB(WrapConnector(connector, l))
+
+	res, err := db.Exec("INSERT INTO orders VALUES (?)", 1)
+	require.NoError(t, err)
+	affected, err := res.RowsAffected()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), affected)
+
+	rows, err := db.Query("SELECT n")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	require.NoError(t, db.Close())
+	assert.True(t, connector.closed)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "exec", got[0][OperationKey])
+	assert.Equal(t, "query", got[1][OperationKey])
+	for _, entry := range got {
+		assert.Equal(t, "debug", entry["level"])
+	}
+}
+
+func TestWrapConnector_Validator(t *testing.T) {
+	l, _ := newTestLogger(t)
+	parent := &legacyConn{}
+	conn, err := WrapConnector(&legacyConnector{conn: parent}, l).Connect(context.Background())
+	require.NoError(t, err)
+
+	v, ok := conn.(driver.Validator)
+	require.True(t, ok)
+	assert.True(t, v.IsValid())
+	parent.invalid = true
+	assert.False(t, v.IsValid())
+}
+
+func TestWrapConnector_Errors(t *testing.T) {
+	l, entries := newTestLogger(t)
+	db := openDB(t, l)
+
+	_, err := db.Exec("FAIL INSERT")
+	assert.Equal(t, errFake, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = db.QueryContext(ctx, "SLOW SELECT")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "error", got[0]["level"])
+	assert.Equal(t, errFake.Error(), got[0]["error"])
+	assert.Equal(t, "warn", got[1]["level"])
+	assert.Equal(t, "query", got[1][OperationKey])
+}
+
+func TestWrapConnector_Tx(t *testing.T) {
+	l, entries := newTestLogger(t)
+	db := openDB(t, l)
+
+	ctx := logger.NewContext(context.Background(), l.With(logger.Fields{"request_id": "abc"}))
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, errFake, tx.Rollback())
+
+	got := entries()
+	require.Len(t, got, 4)
+
+	var operations []interface{}
+	for _, entry := range got {
+		operations = append(operations, entry[OperationKey])
+		assert.Equal(t, "abc", entry["request_id"])
+	}
+	assert.Equal(t, []interface{}{"begin", "commit", "begin", "rollback"}, operations)
+	assert.Equal(t, "error", got[3]["level"])
+}
+
+// Options the parent without BeginTx can't apply are rejected like database/sql does
+func TestWrapConnector_TxOptions(t *testing.T) {
+	l, entries := newTestLogger(t)
+	db := openDB(t, l)
+	ctx := context.Background()
+
+	_, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	assert.Equal(t, errReadOnly, err)
+	_, err = db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	assert.Equal(t, errIsolationLevel, err)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "error", got[0]["level"])
+}
+
+func TestWrapConnector_Statements(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	_, err := openDB(t, l, WithStatementLimit(6)).Exec("INSERT INTO orders")
+	require.NoError(t, err)
+	_, err = openDB(t, l, WithHashedStatements()).Exec("INSERT INTO orders")
+	require.NoError(t, err)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.Equal(t, "INSERT...", got[0][StatementKey])
+	assert.Len(t, got[1][StatementKey], 64)
+	assert.NotContains(t, got[1][StatementKey], "INSERT")
+}
+
+// Statements are cut before a multibyte character crossing the limit
+func TestWrapConnector_StatementLimitUTF8(t *testing.T) {
+	l, entries := newTestLogger(t)
+
+	_, err := openDB(t, l, WithStatementLimit(11)).Exec("SELECT 'привет'")
+	require.NoError(t, err)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "SELECT 'п...", got[0][StatementKey])
+	assert.True(t, utf8.ValidString(got[0][StatementKey].(string)))
+}
+
+func TestWrapConnector_SlowThreshold(t *testing.T) {
+	l, entries := newTestLogger(t)
+	db := openDB(t, l, WithSlowThreshold(time.Nanosecond))
+
+	rows, err := db.Query("SELECT n")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "warn", got[0]["level"])
+}
@@ -0,0 +1,156 @@
+package sqladapter
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var errFake = errors.New("fake failure")
+
+// fakeDriver executes statements without storage:
+// "FAIL ..." fails, "SKIP ..." asks database/sql to prepare it, "SLOW ..." waits for the context.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+type fakeConn struct{}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.HasPrefix(query, "FAIL") {
+		return nil, errFake
+	}
+	return fakeStmt{}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.HasPrefix(query, "SKIP"):
+		return nil, driver.ErrSkip
+	case strings.HasPrefix(query, "FAIL"):
+		return nil, errFake
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if strings.HasPrefix(query, "SLOW") {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(2), nil
+}
+
+func (fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error {
+	return nil
+}
+
+func (fakeTx) Rollback() error {
+	return errFake
+}
+
+// fakeRows has a single row with a single column
+type fakeRows struct {
+	done bool
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"n"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
+
+// legacyConn has only the interfaces preceding context ones, its Prepare fails to show statements aren't prepared
+type legacyConn struct {
+	invalid bool
+}
+
+func (c *legacyConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errFake
+}
+
+func (c *legacyConn) Close() error {
+	return nil
+}
+
+func (c *legacyConn) Begin() (driver.Tx, error) {
+	return fakeTx{}, nil
+}
+
+func (c *legacyConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(3), nil
+}
+
+func (c *legacyConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+func (c *legacyConn) IsValid() bool {
+	return !c.invalid
+}
+
+// legacyConnector opens legacyConn and records whether database/sql closed it
+type legacyConnector struct {
+	conn   *legacyConn
+	closed bool
+}
+
+func (c *legacyConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c *legacyConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+func (c *legacyConnector) Close() error {
+	c.closed = true
+	return nil
+}
@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Formats errors logged by Trace, e.g. to support other errors packages or structured stacks
+type StackFormatter interface {
+	// Returns message of the error entry and extra fields attached to it
+	FormatStack(err error) (message string, fields Fields)
+}
+
+// Formatter function implementing StackFormatter
+type StackFormatterFunc func(err error) (message string, fields Fields)
+
+func (f StackFormatterFunc) FormatStack(err error) (string, Fields) {
+	return f(err)
+}
+
+// Prints the error followed by a single pkg/errors stack. Errors already carrying a stack, also wrapped with
+// errors.Wrap or fmt.Errorf("%w"), are printed with the stack of their origin, the deepest one in the chain.
+// Other errors get the stack of the Trace call, without frames of the logger itself.
+var DefaultStackFormatter StackFormatter = StackFormatterFunc(func(err error) (string, Fields) {
+	stack, ok := originStack(err)
+	if !ok {
+		stack = callerStack()
+	}
+	return fmt.Sprintf("%s%+v", err.Error(), stack), nil
+})
+
+// Key of the frames array added by StructuredStackFormatter
+const StackKey = "stack"
+
+// Logs the error message and the stack chosen like DefaultStackFormatter does as StackKey array of objects with
+// "func", "file" and "line" of frames, most recent call first, so backends can index individual frames.
+var StructuredStackFormatter StackFormatter = StackFormatterFunc(func(err error) (string, Fields) {
+	stack, ok := originStack(err)
+	if !ok {
+		stack = callerStack()
+	}
+	return err.Error(), Fields{StackKey: zap.Array(StackKey, stackFrames(stack))}
+})
+
+type stackFrames errors.StackTrace
+
+func (s stackFrames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, frame := range s {
+		if err := enc.AppendObject(stackFrame(frame)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stackFrame errors.Frame
+
+// MarshalLogObject resolves the frame like pkg/errors does
+func (f stackFrame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	pc := uintptr(f) - 1
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		enc.AddString("func", "unknown")
+		return nil
+	}
+
+	file, line := fn.FileLine(pc)
+	enc.AddString("func", fn.Name())
+	enc.AddString("file", file)
+	enc.AddInt("line", line)
+	return nil
+}
+
+// Implemented by pkg/errors errors with stacks
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// originStack returns the deepest stack of the error chain, errors.Wrap and errors.WithStack add their own
+// stacks on top of the origin one
+func originStack(err error) (errors.StackTrace, bool) {
+	var stack errors.StackTrace
+	for ; err != nil; err = stderrors.Unwrap(err) {
+		if tracer, ok := err.(stackTracer); ok {
+			stack = tracer.StackTrace()
+		}
+	}
+	return stack, stack != nil
+}
+
+// Directory of the package sources, frames of non-test files in it are trimmed from captured stacks
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// callerStack captures the stack starting from the first frame outside of the logger
+func callerStack() errors.StackTrace {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	// Frames are resolved like pkg/errors does, so the index matches the printed frame
+	skip := 0
+	for ; skip < len(pcs)-1; skip++ {
+		fn := runtime.FuncForPC(pcs[skip] - 1)
+		if fn == nil {
+			break
+		}
+		file, _ := fn.FileLine(pcs[skip] - 1)
+		if filepath.Dir(file) != packageDir || strings.HasSuffix(file, "_test.go") {
+			break
+		}
+	}
+
+	stack := make(errors.StackTrace, 0, len(pcs)-skip)
+	for _, pc := range pcs[skip:] {
+		stack = append(stack, errors.Frame(pc))
+	}
+	return stack
+}
+
+// Key of the error text added by LogErr
+const ErrorKey = "error"
+
+// Fields of panic entries logged by Recover with the panic value and the stack of the panic
+const (
+	PanicKey      = "panic"
+	PanicStackKey = "panic_stack"
+)
+
+// Fields of the panic entry logged by Recover with LoggingConfig.RecoverStructured: the panic value, its Go type,
+// the stack of the panic and ID of the panicking goroutine
+const (
+	PanicValueKey      = "panic_value"
+	PanicTypeKey       = "panic_type"
+	PanicStacktraceKey = "stacktrace"
+	GoroutineIDKey     = "goroutine_id"
+)
+
+// Default LoggingConfig.RecoverFormat
+const DefaultRecoverFormat = "recovered %s from %v"
+
+// panicStack formats the stack of the recovered value, the origin one for errors carrying stacks
+func panicStack(recovered interface{}) string {
+	var stack errors.StackTrace
+	if err, ok := recovered.(error); ok {
+		stack, _ = originStack(err)
+	}
+	if stack == nil {
+		stack = callerStack()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%+v", stack), "\n")
+}
+
+// goroutineID parses ID of the current goroutine from its stack header, "goroutine 42 [running]:", 0 if it fails
+func goroutineID() int64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		buf = buf[:i]
+	}
+	id, _ := strconv.ParseInt(string(buf), 10, 64)
+	return id
+}
+
+func (l loggerImpl) Trace(err error) {
+	if err == nil {
+		return
+	}
+	l = l.skipCaller(1)
+
+	stack := l.stack
+	if stack == nil {
+		stack = DefaultStackFormatter
+	}
+	message, fields := stack.FormatStack(err)
+	if len(fields) > 0 {
+		l.With(fields).Error(message)
+		return
+	}
+	l.Error(message)
+}
+
+func (l loggerImpl) LogErr(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	l.skipCaller(1).With(Fields{ErrorKey: err.Error()}).Error(msg)
+	return err
+}
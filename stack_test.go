@@ -0,0 +1,293 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_TraceDefault(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Trace(nil)
+	logger.Trace(errors.New("boom"))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0]["level"])
+
+	message := entries[0]["message"].(string)
+	assert.True(t, strings.HasPrefix(message, "boom\n"), message)
+	assert.Contains(t, message, "TestLoggerImpl_TraceDefault")
+}
+
+func TestLoggerImpl_TraceCustomFormatter(t *testing.T) {
+	var formatted []error
+	formatter := StackFormatterFunc(func(err error) (string, Fields) {
+		formatted = append(formatted, err)
+		return "custom: " + err.Error(), Fields{"stack": []string{"main.go:1", "main.go:2"}}
+	})
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", StackFormatter: formatter}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	boom := errors.New("boom")
+	logger.Namespace("derived").Trace(boom)
+
+	assert.Equal(t, []error{boom}, formatted)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "custom: boom", entries[0]["message"])
+	assert.Equal(t, []interface{}{"main.go:1", "main.go:2"}, entries[0]["stack"])
+	assert.Equal(t, "derived", entries[0]["namespace"])
+}
+
+func TestLoggerImpl_RecoverCustomFormatter(t *testing.T) {
+	formatter := StackFormatterFunc(func(err error) (string, Fields) {
+		return "custom: " + err.Error(), nil
+	})
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", StackFormatter: formatter}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		defer logger.Recover("test")
+		panic("boom")
+	})
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "custom: boom", entries[0]["message"])
+	assert.Equal(t, "recovered test from boom", entries[1]["message"])
+}
+
+func TestLoggerImpl_RecoverFields(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", RecoverFormat: "%s panicked: %v"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		defer logger.Recover("worker")
+		panic(42)
+	})
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "panic", entries[0]["level"])
+	assert.Equal(t, "worker panicked: 42", entries[0]["message"])
+	assert.Equal(t, "42", entries[0][PanicKey])
+	assert.Contains(t, entries[0][PanicStackKey], "TestLoggerImpl_RecoverFields")
+}
+
+func TestLoggerImpl_RecoverOriginStack(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	assert.Panics(t, func() {
+		defer logger.Recover("worker")
+		panic(newOriginError())
+	})
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "recovered worker from boom", entries[1]["message"])
+	assert.Equal(t, "boom", entries[1][PanicKey])
+
+	stack := entries[1][PanicStackKey].(string)
+	assert.True(t, strings.HasPrefix(stack, "github.com/w84thesun/logger.newOriginError\n"), stack)
+}
+
+func TestLoggerImpl_RecoverStructured(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:           "testing",
+		RecoverStructured: true,
+		StacktraceLevel:   "error",
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, "worker failed", func() {
+		defer logger.Recover("worker failed")
+		panic(errors.New("boom"))
+	})
+
+	// Stacktrace of zap isn't added next to the field
+	assert.Equal(t, 1, strings.Count(buf.String(), `"stacktrace"`))
+
+	entries := jsonEntries(t, buf)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "panic", entries[0]["level"])
+	assert.Equal(t, "worker failed", entries[0]["message"])
+	assert.Equal(t, "boom", entries[0][PanicValueKey])
+	assert.Equal(t, "*errors.errorString", entries[0][PanicTypeKey])
+	assert.Contains(t, entries[0][PanicStacktraceKey], "TestLoggerImpl_RecoverStructured")
+	assert.Greater(t, entries[0][GoroutineIDKey], float64(0))
+	assert.NotContains(t, entries[0], PanicKey)
+}
+
+func TestGoroutineID(t *testing.T) {
+	ids := make(chan int64)
+	go func() { ids <- goroutineID() }()
+
+	id := goroutineID()
+	assert.Greater(t, id, int64(0))
+	assert.NotEqual(t, id, <-ids)
+}
+
+// panicSyncer panics on every write, like a broken output
+type panicSyncer struct{}
+
+func (panicSyncer) Write([]byte) (int, error) {
+	panic("output is broken")
+}
+
+func (panicSyncer) Sync() error {
+	return nil
+}
+
+func TestLoggerImpl_RecoverLoggingPanics(t *testing.T) {
+	fallback := &bytes.Buffer{}
+	prev := recoverFallback
+	recoverFallback = fallback
+	defer func() { recoverFallback = prev }()
+
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, panicSyncer{})
+	require.NoError(t, err)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer logger.Recover("worker")
+		panic("boom")
+	})
+
+	assert.Contains(t, fallback.String(), "recovered worker from boom, logging it panicked: output is broken")
+	assert.Contains(t, fallback.String(), "TestLoggerImpl_RecoverLoggingPanics")
+}
+
+func TestLoggerImpl_TraceStructured(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", StructuredStack: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Trace(errors.New("caller"))
+	logger.Trace(fmt.Errorf("loading: %w", newOriginError()))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+
+	// Stack of the Trace call without frames of the logger
+	assert.Equal(t, "caller", entries[0]["message"])
+	frames := entries[0][StackKey].([]interface{})
+	require.NotEmpty(t, frames)
+	first := frames[0].(map[string]interface{})
+	assert.Equal(t, "github.com/w84thesun/logger.TestLoggerImpl_TraceStructured", first["func"])
+	assert.True(t, strings.HasSuffix(first["file"].(string), "stack_test.go"), first["file"])
+	assert.Greater(t, first["line"], float64(0))
+	for _, frame := range frames {
+		assert.Len(t, frame, 3)
+	}
+
+	// Stack of the origin error
+	assert.Equal(t, "loading: boom", entries[1]["message"])
+	first = entries[1][StackKey].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "github.com/w84thesun/logger.newOriginError", first["func"])
+}
+
+func TestLoggerImpl_TraceStructuredFormatterWins(t *testing.T) {
+	formatter := StackFormatterFunc(func(err error) (string, Fields) {
+		return "custom: " + err.Error(), nil
+	})
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", StructuredStack: true, StackFormatter: formatter},
+		zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Trace(errors.New("boom"))
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "custom: boom", entries[0]["message"])
+	assert.NotContains(t, entries[0], StackKey)
+}
+
+// newOriginError creates an error with stack pointing at this function
+func newOriginError() error {
+	return pkgerrors.New("boom")
+}
+
+func TestLoggerImpl_TraceSingleStack(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		origin  string
+	}{
+		{name: "no stack", err: errors.New("boom"), message: "boom", origin: "TestLoggerImpl_TraceSingleStack.func1"},
+		{name: "pkg/errors", err: newOriginError(), message: "boom", origin: "newOriginError"},
+		{name: "wrapped", err: pkgerrors.Wrap(newOriginError(), "loading"), message: "loading: boom", origin: "newOriginError"},
+		{
+			name:    "with stack",
+			err:     pkgerrors.WithStack(pkgerrors.WithMessage(newOriginError(), "loading")),
+			message: "loading: boom",
+			origin:  "newOriginError",
+		},
+		{name: "fmt %w", err: fmt.Errorf("loading: %w", newOriginError()), message: "loading: boom", origin: "newOriginError"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &lockedBuffer{}
+			logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+			require.NoError(t, err)
+
+			logger.Namespace("derived").Trace(tt.err)
+
+			entries := buf.entries(t)
+			require.Len(t, entries, 1)
+			lines := strings.Split(entries[0]["message"].(string), "\n")
+			require.Greater(t, len(lines), 2)
+
+			assert.Equal(t, tt.message, lines[0])
+			// First frame is the origin, not Trace or the formatter
+			assert.True(t, strings.HasSuffix(lines[1], "."+tt.origin), lines[1])
+			assert.Equal(t, 1, strings.Count(entries[0]["message"].(string), "runtime.goexit"))
+			assert.NotContains(t, entries[0]["message"], "loggerImpl.Trace")
+		})
+	}
+}
+
+func TestLoggerImpl_LogErr(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	assert.NoError(t, logger.LogErr(nil, "not logged"))
+
+	boom := errors.New("boom")
+	save := func() error {
+		return logger.With(Fields{"order_id": 1}).LogErr(boom, "failed to save")
+	}
+	assert.Equal(t, boom, save())
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, "failed to save", entries[0]["message"])
+	assert.Equal(t, "boom", entries[0][ErrorKey])
+	assert.Equal(t, float64(1), entries[0]["order_id"])
+	assert.Contains(t, entries[0]["caller"], "stack_test.go")
+}
@@ -0,0 +1,315 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// Failing time of an output after which Healthy reports it if LoggingConfig.HealthThreshold is zero
+const DefaultHealthThreshold = 30 * time.Second
+
+// LoggerStats is a snapshot of counters of loggers derived from the same New call, see Stats of Logger
+type LoggerStats struct {
+	// Entries written to outputs by level, e.g. "error", levels without entries are omitted
+	Entries map[string]uint64
+
+	// Outputs by name, e.g. OutputLogstash
+	Sinks map[string]SinkStats
+}
+
+// SinkStats is a snapshot of counters of an output
+type SinkStats struct {
+	WriteErrors   uint64
+	LastError     string
+	LastErrorTime time.Time
+
+	// Time of the first failed write since the last successful one, zero if the last write succeeded
+	FailingSince time.Time
+
+	// Entries waiting to be written by async outputs, e.g. with AsyncStdout, and capacity of their queue,
+	// zero for outputs buffering bytes. Dropped is entries dropped by the output, e.g. since the queue is full.
+	QueueDepth    int
+	QueueCapacity int
+	Dropped       uint64
+
+	// Whether network outputs (logstash) are connected and their last write succeeded, see SinkHealthy.
+	// Always true for other outputs.
+	Connected bool
+}
+
+// queueReporter is implemented by writers of async outputs
+type queueReporter interface {
+	queueStats() (depth, capacity int, dropped uint64)
+}
+
+// statsState is counters of loggers derived from the same New call, kept by Reconfigure
+type statsState struct {
+	// Entries by level offset from DebugLevel
+	levels [zapcore.FatalLevel - zapcore.DebugLevel + 1]uint64
+
+	// Nanoseconds of HealthThreshold
+	threshold int64
+
+	mu    sync.Mutex
+	sinks map[string]*sinkStats
+}
+
+type sinkStats struct {
+	errors uint64
+	// Unix nanoseconds of the first failure since the last success, 0 if the last write succeeded
+	failingSince int64
+
+	// Guards the last error and sources, only on failures and Stats
+	mu            sync.Mutex
+	lastError     string
+	lastErrorTime time.Time
+	// Writers of the output, e.g. queues and connections, replaced by Reconfigure
+	sources []io.Closer
+	// Dropped entries of the sources when counters were reset
+	droppedBase uint64
+}
+
+func newStatsState(threshold time.Duration) *statsState {
+	s := &statsState{sinks: map[string]*sinkStats{}}
+	s.setThreshold(threshold)
+	return s
+}
+
+func (s *statsState) setThreshold(threshold time.Duration) {
+	if threshold <= 0 {
+		threshold = DefaultHealthThreshold
+	}
+	atomic.StoreInt64(&s.threshold, int64(threshold))
+}
+
+// sink returns counters of the output, the same ones for outputs rebuilt by Reconfigure.
+// Counters of new outputs are reported once passed to replaceSinks.
+func (s *statsState) sink(name string) *sinkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sink, ok := s.sinks[name]; ok {
+		return sink
+	}
+	return &sinkStats{}
+}
+
+// replaceSinks reports counters of the built outputs with their writers, ones of removed outputs are dropped
+func (s *statsState) replaceSinks(sinks map[string]*sinkStats, sources map[string][]io.Closer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, sink := range sinks {
+		sink.mu.Lock()
+		sink.sources = sources[name]
+		sink.droppedBase = 0
+		sink.mu.Unlock()
+	}
+	s.sinks = sinks
+}
+
+func (s *sinkStats) written(err error) {
+	if err == nil {
+		// Load first, so successful writes don't contend on the cache line
+		if atomic.LoadInt64(&s.failingSince) != 0 {
+			atomic.StoreInt64(&s.failingSince, 0)
+		}
+		return
+	}
+
+	now := time.Now()
+	atomic.AddUint64(&s.errors, 1)
+	atomic.CompareAndSwapInt64(&s.failingSince, 0, now.UnixNano())
+
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.lastErrorTime = now
+	s.mu.Unlock()
+}
+
+func (s *sinkStats) snapshot() SinkStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SinkStats{
+		WriteErrors:   atomic.LoadUint64(&s.errors),
+		LastError:     s.lastError,
+		LastErrorTime: s.lastErrorTime,
+		Connected:     true,
+	}
+	if since := atomic.LoadInt64(&s.failingSince); since != 0 {
+		stats.FailingSince = time.Unix(0, since)
+	}
+
+	var dropped uint64
+	for _, source := range s.sources {
+		if queue, ok := source.(queueReporter); ok {
+			depth, capacity, sourceDropped := queue.queueStats()
+			stats.QueueDepth += depth
+			stats.QueueCapacity += capacity
+			dropped += sourceDropped
+		}
+		if conn, ok := source.(*healthConn); ok && !conn.healthy() {
+			stats.Connected = false
+		}
+	}
+	stats.Dropped = dropped - s.droppedBase
+	return stats
+}
+
+func (s *sinkStats) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	atomic.StoreUint64(&s.errors, 0)
+	atomic.StoreInt64(&s.failingSince, 0)
+	s.lastError = ""
+	s.lastErrorTime = time.Time{}
+
+	s.droppedBase = 0
+	for _, source := range s.sources {
+		if queue, ok := source.(queueReporter); ok {
+			_, _, dropped := queue.queueStats()
+			s.droppedBase += dropped
+		}
+	}
+}
+
+func (s *statsState) snapshot() LoggerStats {
+	stats := LoggerStats{Entries: map[string]uint64{}, Sinks: map[string]SinkStats{}}
+	for i := range s.levels {
+		if count := atomic.LoadUint64(&s.levels[i]); count > 0 {
+			stats.Entries[(zapcore.DebugLevel + zapcore.Level(i)).String()] = count
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, sink := range s.sinks {
+		stats.Sinks[name] = sink.snapshot()
+	}
+	return stats
+}
+
+func (s *statsState) healthy() error {
+	threshold := time.Duration(atomic.LoadInt64(&s.threshold))
+	stats := s.snapshot()
+
+	names := make([]string, 0, len(stats.Sinks))
+	for name := range stats.Sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var err error
+	for _, name := range names {
+		sink := stats.Sinks[name]
+		if !sink.FailingSince.IsZero() && time.Since(sink.FailingSince) > threshold {
+			err = multierr.Append(err, fmt.Errorf("output %s is failing since %s: %s",
+				name, sink.FailingSince.Format(time.RFC3339), sink.LastError))
+		}
+	}
+	return err
+}
+
+func (s *statsState) reset() {
+	for i := range s.levels {
+		atomic.StoreUint64(&s.levels[i], 0)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sink := range s.sinks {
+		sink.reset()
+	}
+}
+
+// levelStatsCore counts entries written to outputs by level
+type levelStatsCore struct {
+	zapcore.Core
+
+	stats *statsState
+}
+
+func newLevelStatsCore(core zapcore.Core, stats *statsState) zapcore.Core {
+	return &levelStatsCore{Core: core, stats: stats}
+}
+
+func (c *levelStatsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelStatsCore{Core: c.Core.With(fields), stats: c.stats}
+}
+
+func (c *levelStatsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *levelStatsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if i := ent.Level - zapcore.DebugLevel; i >= 0 && int(i) < len(c.stats.levels) {
+		atomic.AddUint64(&c.stats.levels[i], 1)
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// sinkStatsCore counts write errors of an output. It also drops entries of levels the output doesn't enable:
+// cores wrapping all outputs, e.g. sequence counter, write every entry enabled by any output to all of them,
+// so outputs at Level drop ones enabled only by the debug file.
+type sinkStatsCore struct {
+	zapcore.Core
+
+	sink *sinkStats
+}
+
+func newSinkStatsCore(core zapcore.Core, sink *sinkStats) zapcore.Core {
+	return &sinkStatsCore{Core: core, sink: sink}
+}
+
+func (c *sinkStatsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &sinkStatsCore{Core: c.Core.With(fields), sink: c.sink}
+}
+
+func (c *sinkStatsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *sinkStatsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.Enabled(ent.Level) {
+		return nil
+	}
+
+	err := c.Core.Write(ent, fields)
+	c.sink.written(err)
+	return err
+}
+
+func (l loggerImpl) Stats() LoggerStats {
+	if l.stats == nil {
+		return LoggerStats{Entries: map[string]uint64{}, Sinks: map[string]SinkStats{}}
+	}
+	return l.stats.snapshot()
+}
+
+func (l loggerImpl) Healthy() error {
+	if l.stats == nil {
+		return nil
+	}
+	return l.stats.healthy()
+}
+
+func (l loggerImpl) ResetStats() {
+	if l.stats != nil {
+		l.stats.reset()
+	}
+}
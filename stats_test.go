@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_Entries(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, &mockSink{})
+	require.NoError(t, err)
+
+	logger.Debug("disabled")
+	logger.Info("one")
+	logger.With(Fields{"a": "b"}).Namespace("derived").Info("two")
+	logger.Error("three")
+
+	stats := logger.Stats()
+	assert.Equal(t, map[string]uint64{"info": 2, "error": 1}, stats.Entries)
+	require.Contains(t, stats.Sinks, OutputStdout)
+	assert.Equal(t, SinkStats{Connected: true}, stats.Sinks[OutputStdout])
+	assert.NoError(t, logger.Healthy())
+
+	logger.ResetStats()
+	assert.Empty(t, logger.Stats().Entries)
+}
+
+func TestStats_WriteErrors(t *testing.T) {
+	sink := &mockSink{err: errors.New("disk full")}
+	logger, err := newLogger(LoggingConfig{
+		Service:         "testing",
+		Level:           "info",
+		HealthThreshold: time.Millisecond,
+	}, sink)
+	require.NoError(t, err)
+
+	started := time.Now()
+	logger.Info("lost")
+	logger.Info("lost too")
+
+	stdout := logger.Stats().Sinks[OutputStdout]
+	assert.Equal(t, uint64(2), stdout.WriteErrors)
+	assert.Contains(t, stdout.LastError, "disk full")
+	assert.False(t, stdout.LastErrorTime.Before(started))
+	assert.False(t, stdout.FailingSince.Before(started))
+	assert.False(t, stdout.FailingSince.After(stdout.LastErrorTime))
+
+	time.Sleep(2 * time.Millisecond)
+	err = logger.Healthy()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "output stdout is failing since")
+	assert.Contains(t, err.Error(), "disk full")
+
+	// A successful write ends the failure, errors are still counted
+	sink.err = nil
+	logger.Info("written")
+	stdout = logger.Stats().Sinks[OutputStdout]
+	assert.Equal(t, uint64(2), stdout.WriteErrors)
+	assert.True(t, stdout.FailingSince.IsZero())
+	assert.NoError(t, logger.Healthy())
+
+	logger.ResetStats()
+	assert.Equal(t, SinkStats{Connected: true}, logger.Stats().Sinks[OutputStdout])
+}
+
+func TestStats_HealthThreshold(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, &mockSink{err: errors.New("disk full")})
+	require.NoError(t, err)
+
+	logger.Info("lost")
+	assert.NoError(t, logger.Healthy(), "failing shorter than DefaultHealthThreshold")
+
+	require.NoError(t, logger.(Reconfigurer).Reconfigure(LoggingConfig{
+		Service:         "testing",
+		HealthThreshold: time.Nanosecond,
+	}))
+	time.Sleep(time.Millisecond)
+	assert.Error(t, logger.Healthy(), "counters are kept by Reconfigure")
+}
+
+func TestStats_NATSQueue(t *testing.T) {
+	publisher := &mockPublisher{block: make(chan struct{})}
+	logger, err := New(LoggingConfig{
+		Service:       "testing",
+		Level:         "info",
+		DisableStdout: true,
+		NATSSubject:   "logs",
+		NATSPublisher: publisher,
+		NATSQueueSize: 2,
+	})
+	require.NoError(t, err)
+
+	// The first entry is taken by the blocked publisher, two more fill the queue and the last one is dropped
+	logger.Info("published")
+	require.Eventually(t, func() bool { return logger.Stats().Sinks[OutputNATS].QueueDepth == 0 },
+		time.Second, time.Millisecond)
+	for i := 0; i < 3; i++ {
+		logger.Info("queued")
+	}
+
+	nats := logger.Stats().Sinks[OutputNATS]
+	assert.Equal(t, 2, nats.QueueDepth)
+	assert.Equal(t, 2, nats.QueueCapacity)
+	assert.Equal(t, uint64(1), nats.Dropped)
+	assert.Equal(t, uint64(1), nats.WriteErrors)
+	assert.Contains(t, nats.LastError, ErrNATSQueueFull.Error())
+
+	logger.ResetStats()
+	assert.Zero(t, logger.Stats().Sinks[OutputNATS].Dropped)
+
+	close(publisher.block)
+	require.NoError(t, logger.Close())
+	assert.Zero(t, logger.Stats().Sinks[OutputNATS].QueueDepth)
+}
+
+func TestStats_AsyncStdout(t *testing.T) {
+	logger, err := newLogger(LoggingConfig{Service: "testing", AsyncStdout: true}, &mockSink{})
+	require.NoError(t, err)
+	defer logger.Close()
+
+	logger.Info("buffered")
+	assert.Equal(t, 1, logger.Stats().Sinks[OutputStdout].QueueDepth)
+
+	require.NoError(t, logger.Sync())
+	assert.Zero(t, logger.Stats().Sinks[OutputStdout].QueueDepth)
+}
+
+func TestStats_NotBuiltByNew(t *testing.T) {
+	var logger loggerImpl
+	assert.Equal(t, LoggerStats{Entries: map[string]uint64{}, Sinks: map[string]SinkStats{}}, logger.Stats())
+	assert.NoError(t, logger.Healthy())
+	logger.ResetStats()
+}
@@ -0,0 +1,21 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// stdoutFile is os.Stdout as the stdout output. Pipes, /dev/null and terminals don't support fsync, so Sync
+// ignores EINVAL and ENOTSUP like zap suggests for standard streams, Sync and Close report real failures only.
+type stdoutFile struct {
+	*os.File
+}
+
+func (f stdoutFile) Sync() error {
+	err := f.File.Sync()
+	if errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTSUP) {
+		return nil
+	}
+	return err
+}
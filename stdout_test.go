@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_StdoutPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	defer r.Close()
+
+	// Built by New from os.Stdout, like in containers with stdout piped to the runtime
+	stdout := os.Stdout
+	os.Stdout = w
+	logger, err := New(LoggingConfig{Service: "testing", Level: "info"})
+	os.Stdout = stdout
+	require.NoError(t, err)
+
+	logger.Info("piped")
+	assert.NoError(t, logger.Sync())
+	assert.NoError(t, logger.Close())
+
+	require.NoError(t, w.Close())
+	out, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `"message":"piped"`)
+
+	// Real failures are still reported
+	assert.Error(t, stdoutFile{w}.Sync())
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Keys of infrastructure tags added to every entry, see LoggingConfig.Region
+const (
+	RegionKey           = "region"
+	AvailabilityZoneKey = "availability_zone"
+	ClusterKey          = "cluster"
+)
+
+// Well-known variables checked in order when the config value is empty
+var (
+	regionEnv           = []string{"AWS_REGION", "AWS_DEFAULT_REGION", "GOOGLE_CLOUD_REGION"}
+	availabilityZoneEnv = []string{"AWS_AVAILABILITY_ZONE", "GOOGLE_CLOUD_ZONE"}
+	clusterEnv          = []string{"ECS_CLUSTER", "CLUSTER_NAME"}
+)
+
+// tagFields returns zap fields of the infrastructure tags that are set in the config or environment
+func tagFields(config LoggingConfig) []zapcore.Field {
+	var fields []zapcore.Field
+	for _, tag := range []struct {
+		key   string
+		value string
+		env   []string
+	}{
+		{RegionKey, config.Region, regionEnv},
+		{AvailabilityZoneKey, config.AvailabilityZone, availabilityZoneEnv},
+		{ClusterKey, config.Cluster, clusterEnv},
+	} {
+		value := tag.value
+		for _, env := range tag.env {
+			if value != "" {
+				break
+			}
+			value = os.Getenv(env)
+		}
+		if value != "" {
+			fields = append(fields, zap.String(tag.key, value))
+		}
+	}
+	return fields
+}
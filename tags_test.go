@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// setenv sets variable for the test, t.Setenv needs newer Go than the module declares
+func setenv(t *testing.T, key, value string) {
+	old, ok := os.LookupEnv(key)
+	require.NoError(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, old)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+func TestInfraTags(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		Level:            "info",
+		Region:           "eu-west-1",
+		AvailabilityZone: "eu-west-1a",
+		Cluster:          "payments",
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Namespace("orders").Info("tagged")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "eu-west-1", entries[0][RegionKey])
+	assert.Equal(t, "eu-west-1a", entries[0][AvailabilityZoneKey])
+	assert.Equal(t, "payments", entries[0][ClusterKey])
+}
+
+func TestInfraTags_Env(t *testing.T) {
+	for _, env := range append(append(regionEnv, availabilityZoneEnv...), clusterEnv...) {
+		setenv(t, env, "")
+	}
+	setenv(t, "AWS_DEFAULT_REGION", "us-east-1")
+	setenv(t, "ECS_CLUSTER", "from-env")
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", Cluster: "from-config"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.Info("tagged")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "us-east-1", entries[0][RegionKey])
+	assert.Equal(t, "from-config", entries[0][ClusterKey])
+	assert.NotContains(t, entries[0], AvailabilityZoneKey)
+}
@@ -0,0 +1,20 @@
+module github.com/w84thesun/logger/temporaladapter
+
+go 1.26.0
+
+require github.com/w84thesun/logger v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/stretchr/testify v1.12.1
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+)
+
+require (
+	github.com/pkg/errors v0.9.1 // indirect
+	go.temporal.io/sdk v1.49.0
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+)
+
+replace github.com/w84thesun/logger => ../
@@ -0,0 +1,81 @@
+// Package temporaladapter implements Temporal SDK log.Logger on top of the logger
+package temporaladapter
+
+import (
+	"fmt"
+
+	"go.temporal.io/sdk/log"
+
+	"github.com/w84thesun/logger"
+)
+
+// Value of the component field attached to all entries
+const Component = "temporal"
+
+type adapter struct {
+	base logger.Logger
+}
+
+var (
+	_ log.Logger          = adapter{}
+	_ log.WithLogger      = adapter{}
+	_ log.WithSkipCallers = adapter{}
+)
+
+// Returns logger for client.Options, its With makes workflow and activity fields
+// (WorkflowID, RunID, ActivityType, etc.) stick to loggers returned by workflow.GetLogger and activity.GetLogger:
+//
+//	c, err := client.Dial(client.Options{Logger: temporaladapter.New(log)})
+//
+// Nothing is done about replays: workflow.GetLogger already skips entries during replay
+// unless worker.Options.EnableLoggingInReplay is set, so entries are never logged twice.
+//
+// Caller field points to the code calling the adapter, the SDK skips its own wrappers through WithCallerSkip.
+func New(l logger.Logger) log.Logger {
+	return adapter{base: l.WithComponent(Component).WithCallerSkip(1)}
+}
+
+func (a adapter) Debug(msg string, keyvals ...interface{}) {
+	a.base.With(toFields(keyvals)).Debug(msg)
+}
+
+func (a adapter) Info(msg string, keyvals ...interface{}) {
+	a.base.With(toFields(keyvals)).Info(msg)
+}
+
+func (a adapter) Warn(msg string, keyvals ...interface{}) {
+	a.base.With(toFields(keyvals)).Warn(msg)
+}
+
+func (a adapter) Error(msg string, keyvals ...interface{}) {
+	a.base.With(toFields(keyvals)).Error(msg)
+}
+
+func (a adapter) With(keyvals ...interface{}) log.Logger {
+	return adapter{base: a.base.With(toFields(keyvals))}
+}
+
+// WithCallerSkip implements log.WithSkipCallers, called by the SDK for loggers wrapping the adapter
+func (a adapter) WithCallerSkip(depth int) log.Logger {
+	return adapter{base: a.base.WithCallerSkip(depth)}
+}
+
+// toFields converts key-value pairs, non-string keys are formatted and a dangling key gets nil value
+func toFields(keyvals []interface{}) logger.Fields {
+	fields := make(logger.Fields, len(keyvals)/2+1)
+
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+
+		var value interface{}
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fields[key] = value
+	}
+
+	return fields
+}
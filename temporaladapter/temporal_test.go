@@ -0,0 +1,76 @@
+package temporaladapter
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/log"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func TestAdapter(t *testing.T) {
+	l, entries := newTestLogger(t)
+	base := New(l)
+
+	// Temporal derives workflow loggers with log.With, which uses WithLogger when implemented
+	workflowLogger := log.With(base, "WorkflowID", "order-42", "RunID", "run-1")
+	workflowLogger.Info("workflow started", "attempt", 1)
+	workflowLogger.Warn("dangling", "key")
+	base.Debug("debug", 42, "non-string key")
+	base.Error("failed", "error", "boom")
+
+	got := entries()
+	require.Len(t, got, 4)
+
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "workflow started", got[0]["message"])
+	assert.Equal(t, "order-42", got[0]["WorkflowID"])
+	assert.Equal(t, "run-1", got[0]["RunID"])
+	assert.Equal(t, float64(1), got[0]["attempt"])
+
+	assert.Equal(t, "warn", got[1]["level"])
+	assert.Contains(t, got[1], "key")
+	assert.Nil(t, got[1]["key"])
+	assert.Equal(t, "order-42", got[1]["WorkflowID"])
+
+	assert.Equal(t, "debug", got[2]["level"])
+	assert.Equal(t, "non-string key", got[2]["42"])
+	assert.NotContains(t, got[2], "WorkflowID")
+
+	assert.Equal(t, "error", got[3]["level"])
+	assert.Equal(t, "boom", got[3]["error"])
+
+	for _, entry := range got {
+		assert.Equal(t, Component, entry["component"])
+	}
+}
+
+func TestAdapter_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "debug", Caller: true})
+	base := New(l)
+
+	base.Info("direct")
+	_, _, direct, _ := runtime.Caller(0)
+	// Wrappers of the SDK skip their own frames with log.Skip
+	wrapped := func(msg string) {
+		log.Skip(base, 1).Info(msg)
+	}
+	wrapped("wrapped")
+	_, _, outer, _ := runtime.Caller(0)
+
+	got := entries()
+	require.Len(t, got, 2)
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "temporal_test.go:"+strconv.Itoa(direct-1)), got[0]["caller"])
+	assert.True(t, strings.HasSuffix(got[1]["caller"].(string), "temporal_test.go:"+strconv.Itoa(outer-1)), got[1]["caller"])
+}
@@ -0,0 +1,168 @@
+package logger
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Key of the field with the number of entries suppressed by Every or EveryDuration since the previous written one
+const SuppressedCountKey = "suppressed_count"
+
+// Number of keys of Once, Every and EveryDuration remembered process-wide, least recently used ones are forgotten,
+// so e.g. a key of Once evicted by many others logs once more
+const MaxThrottleKeys = 4096
+
+type throttleKind int
+
+const (
+	throttleOnce throttleKind = iota
+	throttleEvery
+	throttleEveryDuration
+)
+
+// throttleKey is a key of one of the methods, so e.g. Once and Every with the same key don't share state
+type throttleKey struct {
+	kind throttleKind
+	key  string
+}
+
+type throttleEntry struct {
+	key throttleKey
+
+	// Whether an entry was written and when the last one was
+	written bool
+	last    time.Time
+
+	// Entries suppressed since the last written one
+	suppressed int
+}
+
+// throttleCache is LRU of throttle keys shared by all loggers of the process
+type throttleCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[throttleKey]*list.Element
+	order   *list.List
+}
+
+var throttles = newThrottleCache(MaxThrottleKeys)
+
+func newThrottleCache(size int) *throttleCache {
+	return &throttleCache{size: size, entries: map[throttleKey]*list.Element{}, order: list.New()}
+}
+
+// entry returns state of the key, must be called with the lock held
+func (t *throttleCache) entry(key throttleKey) *throttleEntry {
+	if el, ok := t.entries[key]; ok {
+		t.order.MoveToFront(el)
+		return el.Value.(*throttleEntry)
+	}
+
+	if t.order.Len() >= t.size {
+		oldest := t.order.Back()
+		t.order.Remove(oldest)
+		delete(t.entries, oldest.Value.(*throttleEntry).key)
+	}
+	e := &throttleEntry{key: key}
+	t.entries[key] = t.order.PushFront(e)
+	return e
+}
+
+// reset forgets all keys, e.g. between tests
+func (t *throttleCache) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = map[throttleKey]*list.Element{}
+	t.order.Init()
+}
+
+// throttleCore writes entries of the key passing Once, Every or EveryDuration and suppresses others before
+// they are encoded. Panic and fatal entries are always written, the process may not log anything after them.
+type throttleCore struct {
+	zapcore.Core
+
+	key throttleKey
+	n   int
+	d   time.Duration
+}
+
+func (c *throttleCore) With(fields []zapcore.Field) zapcore.Core {
+	return &throttleCore{Core: c.Core.With(fields), key: c.key, n: c.n, d: c.d}
+}
+
+func (c *throttleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) {
+		return ce
+	}
+	if ent.Level >= zapcore.DPanicLevel {
+		return c.Core.Check(ent, ce)
+	}
+
+	ok, suppressed := c.allow(ent.Time)
+	switch {
+	case !ok:
+		return ce
+	case suppressed > 0:
+		// Checked by the inner core, so e.g. sampling still applies
+		return c.Core.With([]zapcore.Field{zap.Int(SuppressedCountKey, suppressed)}).Check(ent, ce)
+	default:
+		return c.Core.Check(ent, ce)
+	}
+}
+
+// allow reports whether the entry is written and the number of entries suppressed before it
+func (c *throttleCore) allow(now time.Time) (bool, int) {
+	throttles.mu.Lock()
+	defer throttles.mu.Unlock()
+
+	e := throttles.entry(c.key)
+	if e.written {
+		switch c.key.kind {
+		case throttleOnce:
+			return false, 0
+		case throttleEvery:
+			if e.suppressed < c.n-1 {
+				e.suppressed++
+				return false, 0
+			}
+		case throttleEveryDuration:
+			if now.Sub(e.last) < c.d {
+				e.suppressed++
+				return false, 0
+			}
+		}
+	}
+
+	suppressed := e.suppressed
+	e.written, e.last, e.suppressed = true, now, 0
+	return true, suppressed
+}
+
+func (l loggerImpl) withThrottle(core *throttleCore) Logger {
+	return l.withZapOptions(zap.WrapCore(func(inner zapcore.Core) zapcore.Core {
+		core.Core = inner
+		return core
+	}))
+}
+
+func (l loggerImpl) Once(key string) Logger {
+	return l.withThrottle(&throttleCore{key: throttleKey{kind: throttleOnce, key: key}})
+}
+
+func (l loggerImpl) Every(key string, n int) Logger {
+	if n <= 1 {
+		return l
+	}
+	return l.withThrottle(&throttleCore{key: throttleKey{kind: throttleEvery, key: key}, n: n})
+}
+
+func (l loggerImpl) EveryDuration(key string, d time.Duration) Logger {
+	if d <= 0 {
+		return l
+	}
+	return l.withThrottle(&throttleCore{key: throttleKey{kind: throttleEveryDuration, key: key}, d: d})
+}
@@ -0,0 +1,149 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func newThrottleTestLogger(t *testing.T) (Logger, *lockedBuffer) {
+	throttles.reset()
+	t.Cleanup(throttles.reset)
+
+	stdout := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(stdout))
+	require.NoError(t, err)
+	return logger, stdout
+}
+
+func TestLoggerImpl_Once(t *testing.T) {
+	logger, stdout := newThrottleTestLogger(t)
+
+	for i := 0; i < 3; i++ {
+		logger.Once("legacy-api").Warnf("legacy api is deprecated, call %d", i)
+	}
+	// Process-wide, so loggers derived separately share the key
+	logger.With(Fields{"request_id": 7}).Once("legacy-api").Warn("legacy api is deprecated")
+	// Disabled entries don't use up the key
+	logger.Once("other").Debug("debug")
+	logger.Once("other").Info("other")
+
+	entries := stdout.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "legacy api is deprecated, call 0", entries[0]["message"])
+	assert.NotContains(t, entries[0], SuppressedCountKey)
+	assert.Equal(t, "other", entries[1]["message"])
+}
+
+func TestLoggerImpl_Every(t *testing.T) {
+	logger, stdout := newThrottleTestLogger(t)
+
+	retries := logger.Namespace("worker").Every("retry", 3)
+	for i := 0; i < 7; i++ {
+		retries.With(Fields{"attempt": i}).Warn("retrying")
+	}
+
+	entries := stdout.entries(t)
+	require.Len(t, entries, 3)
+	assert.Equal(t, float64(0), entries[0]["attempt"])
+	assert.NotContains(t, entries[0], SuppressedCountKey)
+	assert.Equal(t, float64(3), entries[1]["attempt"])
+	assert.Equal(t, float64(2), entries[1][SuppressedCountKey])
+	assert.Equal(t, float64(6), entries[2]["attempt"])
+	assert.Equal(t, "worker", entries[2]["namespace"])
+
+	// Typed entries are throttled too, Once with the same key has its own state
+	retries.InfoZ("typed")
+	logger.Once("retry").Info("once")
+	assert.Len(t, stdout.entries(t), 4)
+	assert.Equal(t, "once", stdout.entries(t)[3]["message"])
+
+	// Not throttled
+	logger.Every("retry", 1).Info("unthrottled")
+	logger.Every("retry", 1).Info("unthrottled")
+	assert.Len(t, stdout.entries(t), 6)
+}
+
+func TestLoggerImpl_EveryDuration(t *testing.T) {
+	logger, stdout := newThrottleTestLogger(t)
+
+	polls := logger.EveryDuration("poll", 100*time.Millisecond)
+	for i := 0; i < 5; i++ {
+		polls.Info("polling")
+	}
+	time.Sleep(150 * time.Millisecond)
+	polls.Info("polling")
+
+	entries := stdout.entries(t)
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entries[0], SuppressedCountKey)
+	assert.Equal(t, float64(4), entries[1][SuppressedCountKey])
+
+	logger.EveryDuration("poll", 0).Info("unthrottled")
+	logger.EveryDuration("poll", 0).Info("unthrottled")
+	assert.Len(t, stdout.entries(t), 4)
+}
+
+func TestLoggerImpl_Every_PanicWritten(t *testing.T) {
+	logger, stdout := newThrottleTestLogger(t)
+
+	logger.Once("panic").Info("first")
+	assert.Panics(t, func() {
+		logger.Once("panic").Panic("boom")
+	})
+	require.Len(t, stdout.entries(t), 2)
+	assert.Equal(t, "boom", stdout.entries(t)[1]["message"])
+}
+
+func TestLoggerImpl_Every_Concurrent(t *testing.T) {
+	logger, stdout := newThrottleTestLogger(t)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				logger.Every("concurrent", 10).Info("entry")
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries := stdout.entries(t)
+	require.Len(t, entries, 80)
+	var first int
+	for _, entry := range entries {
+		if suppressed, ok := entry[SuppressedCountKey]; ok {
+			assert.Equal(t, float64(9), suppressed)
+		} else {
+			first++
+		}
+	}
+	assert.Equal(t, 1, first)
+}
+
+func TestThrottleCache_Evicts(t *testing.T) {
+	cache := newThrottleCache(2)
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	keys := make([]throttleKey, 3)
+	for i := range keys {
+		keys[i] = throttleKey{kind: throttleOnce, key: fmt.Sprint(i)}
+	}
+	cache.entry(keys[0]).written = true
+	cache.entry(keys[1]).written = true
+	// Used recently, so the second key is evicted
+	cache.entry(keys[0])
+	cache.entry(keys[2])
+
+	assert.Len(t, cache.entries, 2)
+	assert.True(t, cache.entry(keys[0]).written)
+	assert.False(t, cache.entry(keys[1]).written)
+}
@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// logstashTLSConfig builds TLS config of logstash connections from PEM files of the config, nil if TLS is disabled
+func logstashTLSConfig(config LoggingConfig) (*tls.Config, error) {
+	if !config.LogstashTLS {
+		return nil, nil
+	}
+	if strings.HasPrefix(config.LogstashProtocol, "udp") || config.LogstashProtocol == "unixgram" {
+		return nil, fmt.Errorf("LogstashTLS can't be used with %v protocol", config.LogstashProtocol)
+	}
+	if (config.LogstashTLSCert == "") != (config.LogstashTLSKey == "") {
+		return nil, errors.New("LogstashTLSCert and LogstashTLSKey must be set together")
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.LogstashTLSInsecureSkipVerify,
+	}
+
+	if config.LogstashTLSCA != "" {
+		pem, err := ioutil.ReadFile(config.LogstashTLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("read LogstashTLSCA: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in LogstashTLSCA %v", config.LogstashTLSCA)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if config.LogstashTLSCert != "" {
+		cert, err := tls.LoadX509KeyPair(config.LogstashTLSCert, config.LogstashTLSKey)
+		if err != nil {
+			return nil, fmt.Errorf("load LogstashTLSCert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
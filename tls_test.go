@@ -0,0 +1,205 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// testPKI is a CA with server and client certificates signed by it, written as PEM files into dir
+type testPKI struct {
+	dir        string
+	caFile     string
+	certFile   string
+	keyFile    string
+	roots      *x509.CertPool
+	serverCert tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "testing CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		}, ca, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		return der, key
+	}
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+		return path
+	}
+
+	serverDER, serverKey := issue(2, x509.ExtKeyUsageServerAuth)
+	clientDER, clientKey := issue(3, x509.ExtKeyUsageClientAuth)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	return &testPKI{
+		dir:        dir,
+		caFile:     writePEM("ca.pem", "CERTIFICATE", caDER),
+		certFile:   writePEM("client.pem", "CERTIFICATE", clientDER),
+		keyFile:    writePEM("client-key.pem", "EC PRIVATE KEY", clientKeyDER),
+		roots:      roots,
+		serverCert: tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey},
+	}
+}
+
+// listenTLS accepts one TLS connection and sends its lines, client certificate is required if clientCAs is set
+func listenTLS(t *testing.T, pki *testPKI, clientCAs *x509.CertPool) (net.Listener, <-chan string) {
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{pki.serverCert}}
+	if clientCAs != nil {
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	received := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			received <- scanner.Text()
+		}
+	}()
+	return listener, received
+}
+
+func receiveLine(t *testing.T, received <-chan string) string {
+	select {
+	case line := <-received:
+		return line
+	case <-time.After(2 * time.Second):
+		t.Fatal("entry not received")
+		return ""
+	}
+}
+
+func TestLogstashTLS(t *testing.T) {
+	pki := newTestPKI(t)
+	listener, received := listenTLS(t, pki, pki.roots)
+
+	logger, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		DisableStdout:    true,
+		LogstashURI:      listener.Addr().String(),
+		LogstashProtocol: "tcp",
+		LogstashTLS:      true,
+		LogstashTLSCA:    pki.caFile,
+		LogstashTLSCert:  pki.certFile,
+		LogstashTLSKey:   pki.keyFile,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger.Info("encrypted")
+	require.NoError(t, logger.Close())
+
+	assert.Contains(t, receiveLine(t, received), `"message":"encrypted"`)
+}
+
+func TestLogstashTLS_UnknownAuthority(t *testing.T) {
+	pki := newTestPKI(t)
+	listener, _ := listenTLS(t, pki, nil)
+
+	// Server certificate isn't signed by system roots
+	_, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		LogstashURI:      listener.Addr().String(),
+		LogstashProtocol: "tcp",
+		LogstashTLS:      true,
+	}, zapcore.AddSync(ioutil.Discard))
+	assert.Error(t, err)
+}
+
+func TestLogstashTLS_InsecureSkipVerify(t *testing.T) {
+	pki := newTestPKI(t)
+	listener, received := listenTLS(t, pki, nil)
+
+	logger, err := newLogger(LoggingConfig{
+		Service:                       "testing",
+		DisableStdout:                 true,
+		LogstashURI:                   listener.Addr().String(),
+		LogstashProtocol:              "tcp",
+		LogstashTLS:                   true,
+		LogstashTLSInsecureSkipVerify: true,
+	}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger.Info("unverified")
+	require.NoError(t, logger.Close())
+
+	assert.Contains(t, receiveLine(t, received), `"message":"unverified"`)
+}
+
+func TestLogstashTLSConfig_Invalid(t *testing.T) {
+	pki := newTestPKI(t)
+
+	for name, config := range map[string]LoggingConfig{
+		"udp":          {LogstashProtocol: "udp", LogstashTLS: true},
+		"cert only":    {LogstashProtocol: "tcp", LogstashTLS: true, LogstashTLSCert: pki.certFile},
+		"absent CA":    {LogstashProtocol: "tcp", LogstashTLS: true, LogstashTLSCA: filepath.Join(pki.dir, "absent.pem")},
+		"CA not PEM":   {LogstashProtocol: "tcp", LogstashTLS: true, LogstashTLSCA: pki.keyFile},
+		"key mismatch": {LogstashProtocol: "tcp", LogstashTLS: true, LogstashTLSCert: pki.caFile, LogstashTLSKey: pki.keyFile},
+	} {
+		_, err := logstashTLSConfig(config)
+		assert.Error(t, err, name)
+	}
+
+	tlsConfig, err := logstashTLSConfig(LoggingConfig{LogstashProtocol: "tcp"})
+	assert.NoError(t, err)
+	assert.Nil(t, tlsConfig)
+}
@@ -0,0 +1,22 @@
+package logger
+
+import "time"
+
+// Key of the retention field added by WithTTL, in whole days
+const TTLKey = "ttl_days"
+
+const day = 24 * time.Hour
+
+func (l loggerImpl) WithTTL(d time.Duration) Logger {
+	if d < 0 {
+		return l
+	}
+
+	// Partial days are rounded up, so entries are never kept shorter than requested
+	days := d / day
+	if d%day != 0 {
+		days++
+	}
+
+	return l.With(Fields{TTLKey: int64(days)})
+}
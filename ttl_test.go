@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLoggerImpl_WithTTL(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "debug"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.WithTTL(7 * 24 * time.Hour).Debug("week")
+	logger.WithTTL(time.Hour).Namespace("debug").Debug("rounded up")
+	logger.WithTTL(0).Debug("zero")
+	logger.WithTTL(time.Hour).WithTTL(-time.Hour).Debug("negative ignored")
+	logger.WithTTL(-time.Hour).Debug("no ttl")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 5)
+	assert.Equal(t, float64(7), entries[0][TTLKey])
+	assert.Equal(t, float64(1), entries[1][TTLKey])
+	assert.Equal(t, "debug", entries[1]["namespace"])
+	assert.Equal(t, float64(0), entries[2][TTLKey])
+	assert.Equal(t, float64(1), entries[3][TTLKey])
+	assert.NotContains(t, entries[4], TTLKey)
+}
+
+// Rounding up doesn't overflow for the longest durations
+func TestLoggerImpl_WithTTLMax(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "debug"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.WithTTL(math.MaxInt64).Debug("forever")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, float64(math.MaxInt64/int64(day)+1), entries[0][TTLKey])
+}
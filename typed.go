@@ -0,0 +1,161 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Returns fields with a boolean stored as native zap field, so it isn't boxed and converted when logged
+func Bool(key string, value bool) Fields {
+	return Fields{key: zap.Bool(key, value)}
+}
+
+// Returns fields with a timestamp stored as native zap field.
+// Encoded with the time format of the output, e.g. RFC3339 with nanoseconds for JSON.
+func Time(key string, value time.Time) Fields {
+	return Fields{key: zap.Time(key, value)}
+}
+
+// Limit of bytes kept by Binary, longer values are cut to avoid dumping huge payloads into logs.
+// Zero or negative disables the limit.
+var MaxBinaryBytes = 4 << 10
+
+// Suffix of the field Binary adds with the original length when the value is truncated
+const BinarySizeSuffix = "_size"
+
+// Returns fields with bytes stored as native zap field, encoded as base64 string in JSON instead of number array.
+// Values longer than MaxBinaryBytes are truncated, the original length is added as key + BinarySizeSuffix.
+func Binary(key string, value []byte) Fields {
+	if MaxBinaryBytes <= 0 || len(value) <= MaxBinaryBytes {
+		return Fields{key: zap.Binary(key, value)}
+	}
+
+	return Fields{
+		key:                    zap.Binary(key, value[:MaxBinaryBytes]),
+		key + BinarySizeSuffix: zap.Int(key+BinarySizeSuffix, len(value)),
+	}
+}
+
+// Returns fields with errors stored as array of objects with "error" message and "causes" messages of errors they
+// wrap, outermost first. Causes repeating the message of the previous error, e.g. stacks added by errors.Wrap,
+// are skipped. Nil errors, including nil pointers, are encoded as null.
+func Errors(key string, errs []error) Fields {
+	return Fields{key: zap.Array(key, errorsArray(errs))}
+}
+
+// Returns fields with values stored as array of their strings. Nil values, including nil pointers, are encoded as null.
+func Stringers(key string, values []fmt.Stringer) Fields {
+	return Fields{key: zap.Array(key, stringersArray(values))}
+}
+
+type errorsArray []error
+
+func (errs errorsArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range errs {
+		if isNil(err) {
+			if appendErr := enc.AppendReflected(nil); appendErr != nil {
+				return appendErr
+			}
+			continue
+		}
+
+		if appendErr := enc.AppendObject(errorObject{err}); appendErr != nil {
+			return appendErr
+		}
+	}
+	return nil
+}
+
+type errorObject struct {
+	err error
+}
+
+func (e errorObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	message := e.err.Error()
+	enc.AddString("error", message)
+
+	var causes []string
+	for cause := errors.Unwrap(e.err); cause != nil; cause = errors.Unwrap(cause) {
+		if causeMessage := cause.Error(); causeMessage != message {
+			causes = append(causes, causeMessage)
+			message = causeMessage
+		}
+	}
+	if len(causes) == 0 {
+		return nil
+	}
+
+	return enc.AddArray("causes", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, cause := range causes {
+			arr.AppendString(cause)
+		}
+		return nil
+	}))
+}
+
+type stringersArray []fmt.Stringer
+
+func (values stringersArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, value := range values {
+		if isNil(value) {
+			if err := enc.AppendReflected(nil); err != nil {
+				return err
+			}
+			continue
+		}
+		enc.AppendString(value.String())
+	}
+	return nil
+}
+
+// isNil reports whether value is nil or a nil pointer, whose String method would likely panic
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// typedField returns the zap field stored as a value of fields, renamed to the map key
+func typedField(key string, value interface{}) (zapcore.Field, bool) {
+	field, ok := value.(zapcore.Field)
+	if !ok {
+		return zapcore.Field{}, false
+	}
+
+	field.Key = key
+	return field, true
+}
+
+// zapFieldValues converts zap fields into plain values like fieldValue
+func zapFieldValues(fields []zapcore.Field) Fields {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return enc.Fields
+}
+
+// fieldValue unwraps zap fields into plain values, e.g. bool for Bool or []byte for Binary
+func fieldValue(value interface{}) interface{} {
+	field, ok := value.(zapcore.Field)
+	if !ok {
+		return value
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	return enc.Fields[field.Key]
+}
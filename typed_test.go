@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestTypedFields(t *testing.T) {
+	at := time.Date(2020, 5, 17, 10, 30, 0, 123000000, time.FixedZone("MSK", 3*60*60))
+
+	for _, sortKeys := range []bool{false, true} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", SortKeys: sortKeys}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.With(Bool("cached", true)).With(Time("created_at", at)).Info("typed")
+		logger.WithLazy(func() Fields { return Bool("lazy", false) }).Info("lazy")
+
+		entries := buf.entries(t)
+		require.Len(t, entries, 2)
+		assert.Equal(t, true, entries[0]["cached"])
+		assert.Equal(t, "2020-05-17T10:30:00.123+03:00", entries[0]["created_at"])
+		assert.Equal(t, false, entries[1]["lazy"])
+	}
+}
+
+func TestTypedFields_GetField(t *testing.T) {
+	at := time.Date(2020, 5, 17, 10, 30, 0, 0, time.UTC)
+	logger, err := newLogger(LoggingConfig{Service: "testing"}, zapcore.AddSync(ioutil.Discard))
+	require.NoError(t, err)
+
+	logger = logger.With(Bool("cached", true).Merge(Time("created_at", at)))
+
+	value, ok := logger.GetField("cached")
+	require.True(t, ok)
+	assert.Equal(t, true, value)
+
+	value, ok = logger.GetField("created_at")
+	require.True(t, ok)
+	assert.True(t, at.Equal(value.(time.Time)))
+}
+
+func TestBinary(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatPretty} {
+		buf := &lockedBuffer{}
+		logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info", FormatStdout: format}, zapcore.AddSync(buf))
+		require.NoError(t, err)
+
+		logger.With(Binary("payload", []byte("hello"))).Info("binary")
+
+		if format == FormatJSON {
+			entries := buf.entries(t)
+			require.Len(t, entries, 1)
+			assert.Equal(t, "aGVsbG8=", entries[0]["payload"])
+			assert.NotContains(t, entries[0], "payload"+BinarySizeSuffix)
+		} else {
+			assert.Contains(t, buf.buf.String(), `"payload": "aGVsbG8="`)
+		}
+	}
+}
+
+func TestBinary_Truncated(t *testing.T) {
+	defer func(limit int) { MaxBinaryBytes = limit }(MaxBinaryBytes)
+	MaxBinaryBytes = 4
+
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Binary("payload", []byte("hello world"))).Info("truncated")
+	logger.With(Binary("short", []byte("hi"))).Info("short")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("hell")), entries[0]["payload"])
+	assert.Equal(t, float64(len("hello world")), entries[0]["payload"+BinarySizeSuffix])
+	assert.Equal(t, "aGk=", entries[1]["short"])
+	assert.NotContains(t, entries[1], "short"+BinarySizeSuffix)
+
+	MaxBinaryBytes = 0
+	assert.Len(t, Binary("payload", make([]byte, 10<<10)), 1)
+
+	value, ok := logger.With(Binary("payload", []byte("hello"))).GetField("payload")
+	require.True(t, ok)
+	assert.Equal(t, []byte("hello"), value)
+}
+
+// Typed fields skip conversion with zap.Any when flattened, compare with BenchmarkLoggerImpl_InfoBoxed.
+// Roughly 20% faster, allocations are the same since Fields still stores values as interfaces.
+func BenchmarkLoggerImpl_InfoTyped(b *testing.B) {
+	logger, _ := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(ioutil.Discard))
+	fields := Bool("cached", true).Merge(Time("created_at", time.Now()))
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.With(fields).Info("hello there")
+	}
+}
+
+func BenchmarkLoggerImpl_InfoBoxed(b *testing.B) {
+	logger, _ := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(ioutil.Discard))
+	fields := Fields{"cached": true, "created_at": time.Now()}
+
+	b.ReportAllocs()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.With(fields).Info("hello there")
+	}
+}
+
+type testStringer string
+
+func (s *testStringer) String() string {
+	return "stringer " + string(*s)
+}
+
+func TestErrorsStringers(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	origin := errors.New("connection refused")
+	wrapped := pkgerrors.Wrap(fmt.Errorf("dial: %w", origin), "loading")
+	var nilStringer *testStringer
+	value := testStringer("value")
+
+	logger.
+		With(Errors("errors", []error{origin, nil, wrapped})).
+		With(Stringers("stringers", []fmt.Stringer{&value, nil, nilStringer})).
+		Info("slices")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"error": "connection refused"},
+		nil,
+		map[string]interface{}{
+			"error":  "loading: dial: connection refused",
+			"causes": []interface{}{"dial: connection refused", "connection refused"},
+		},
+	}, entries[0]["errors"])
+	assert.Equal(t, []interface{}{"stringer value", nil, nil}, entries[0]["stringers"])
+
+	// Other formats handle nil elements too
+	pretty := &lockedBuffer{}
+	logger, err = newLogger(LoggingConfig{Service: "testing", FormatStdout: FormatPretty}, zapcore.AddSync(pretty))
+	require.NoError(t, err)
+	logger.With(Errors("errors", []error{nil})).With(Stringers("stringers", nil)).Info("pretty")
+	assert.Contains(t, pretty.buf.String(), `"errors": [null]`)
+	assert.Contains(t, pretty.buf.String(), `"stringers": []`)
+}
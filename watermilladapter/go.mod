@@ -0,0 +1,22 @@
+module github.com/w84thesun/logger/watermilladapter
+
+go 1.25.0
+
+require (
+	github.com/ThreeDotsLabs/watermill v1.5.3
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.12.1
+	github.com/w84thesun/logger v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/lithammer/shortuuid/v3 v3.0.7 // indirect
+	github.com/oklog/ulid v1.3.1 // indirect
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+)
+
+replace github.com/w84thesun/logger => ../
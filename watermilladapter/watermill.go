@@ -0,0 +1,59 @@
+// Package watermilladapter implements watermill.LoggerAdapter on top of the logger
+package watermilladapter
+
+import (
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/pkg/errors"
+
+	"github.com/w84thesun/logger"
+)
+
+// Value of the component field attached to all entries
+const Component = "watermill"
+
+type adapter struct {
+	base logger.Logger
+}
+
+// Returns watermill logger, Trace entries are logged at debug level:
+//
+//	router, err := message.NewRouter(message.RouterConfig{}, watermilladapter.New(log))
+func New(l logger.Logger) watermill.LoggerAdapter {
+	return adapter{base: l.WithComponent(Component).WithCallerSkip(1)}
+}
+
+// Error logs errors through Trace, so the entry gets the origin stack of the error chain, or the stack of the call
+// for errors without one, formatted by StackFormatter of the logger. Message is msg followed by the error.
+func (a adapter) Error(msg string, err error, fields watermill.LogFields) {
+	l := a.base.With(toFields(fields))
+	if err == nil {
+		l.Error(msg)
+		return
+	}
+
+	l.With(logger.Fields{logger.ErrorKey: err.Error()}).Trace(errors.WithMessage(err, msg))
+}
+
+func (a adapter) Info(msg string, fields watermill.LogFields) {
+	a.base.With(toFields(fields)).Info(msg)
+}
+
+func (a adapter) Debug(msg string, fields watermill.LogFields) {
+	a.base.With(toFields(fields)).Debug(msg)
+}
+
+func (a adapter) Trace(msg string, fields watermill.LogFields) {
+	a.base.With(toFields(fields)).Debug(msg)
+}
+
+func (a adapter) With(fields watermill.LogFields) watermill.LoggerAdapter {
+	return adapter{base: a.base.With(toFields(fields))}
+}
+
+func toFields(fields watermill.LogFields) logger.Fields {
+	converted := make(logger.Fields, len(fields)+1)
+	for k, v := range fields {
+		converted[k] = v
+	}
+	return converted
+}
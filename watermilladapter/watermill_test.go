@@ -0,0 +1,102 @@
+package watermilladapter
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/w84thesun/logger"
+	"github.com/w84thesun/logger/logtest"
+)
+
+// newTestLogger builds a logger capturing its entries instead of writing them to stdout
+func newTestLogger(t *testing.T) (logger.Logger, func() []map[string]interface{}) {
+	return logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Namespace: "default", Level: "debug"})
+}
+
+func TestAdapter(t *testing.T) {
+	l, entries := newTestLogger(t)
+	log := New(l)
+
+	child := log.With(watermill.LogFields{"handler_name": "orders", "topic": "orders.created"})
+	child.Info("message handled", watermill.LogFields{"message_uuid": "abc"})
+	child.Debug("debug", nil)
+	log.Trace("trace", watermill.LogFields{"key": "value"})
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, "info", got[0]["level"])
+	assert.Equal(t, "message handled", got[0]["message"])
+	assert.Equal(t, "orders", got[0]["handler_name"])
+	assert.Equal(t, "orders.created", got[0]["topic"])
+	assert.Equal(t, "abc", got[0]["message_uuid"])
+	assert.Equal(t, "orders", got[1]["handler_name"])
+	assert.Equal(t, "debug", got[2]["level"])
+	assert.Equal(t, "value", got[2]["key"])
+	assert.NotContains(t, got[2], "handler_name")
+	for _, entry := range got {
+		assert.Equal(t, Component, entry["component"])
+	}
+}
+
+// newOriginError returns error with the stack of its origin
+func newOriginError() error {
+	return pkgerrors.New("with stack")
+}
+
+func TestAdapter_Error(t *testing.T) {
+	l, entries := newTestLogger(t)
+	log := New(l)
+
+	log.Error("handler failed", errors.New("boom"), watermill.LogFields{"retry": 2})
+	log.Error("wrapped", fmt.Errorf("handling: %w", newOriginError()), nil)
+	log.Error("no error", nil, nil)
+
+	got := entries()
+	require.Len(t, got, 3)
+	assert.Equal(t, "error", got[0]["level"])
+	assert.True(t, strings.HasPrefix(got[0]["message"].(string), "handler failed: boom\n"), got[0]["message"])
+	assert.Contains(t, got[0]["message"], "TestAdapter_Error")
+	assert.Equal(t, "boom", got[0][logger.ErrorKey])
+	assert.Equal(t, float64(2), got[0]["retry"])
+
+	// Stack of the origin, not another one added on top of the wrapping error
+	assert.True(t, strings.HasPrefix(got[1]["message"].(string), "wrapped: handling: with stack\n"), got[1]["message"])
+	assert.Contains(t, got[1]["message"], "newOriginError")
+	assert.Equal(t, 1, strings.Count(got[1]["message"].(string), "runtime.goexit"))
+	assert.Equal(t, "handling: with stack", got[1][logger.ErrorKey])
+
+	assert.Equal(t, "no error", got[2]["message"])
+	assert.NotContains(t, got[2], logger.ErrorKey)
+}
+
+func TestAdapter_StructuredStack(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "info", StackFormatter: logger.StructuredStackFormatter})
+	New(l).Error("handler failed", newOriginError(), nil)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.Equal(t, "handler failed: with stack", got[0]["message"])
+	frames := got[0][logger.StackKey].([]interface{})
+	assert.Contains(t, frames[0].(map[string]interface{})["func"], "newOriginError")
+}
+
+func TestAdapter_Caller(t *testing.T) {
+	l, entries := logtest.NewCaptured(t, logger.LoggingConfig{Service: "testing", Level: "debug", Caller: true})
+	log := New(l).With(watermill.LogFields{"topic": "orders"})
+
+	log.Info("handled", nil)
+	_, _, line, _ := runtime.Caller(0)
+
+	got := entries()
+	require.Len(t, got, 1)
+	assert.True(t, strings.HasSuffix(got[0]["caller"].(string), "watermill_test.go:"+strconv.Itoa(line-1)), got[0]["caller"])
+}
@@ -0,0 +1,29 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+// Names of outputs passed to CoreWrapper.WrapOutput
+const (
+	OutputStdout   = "stdout"
+	OutputLogstash = "logstash"
+	OutputJournald = "journald"
+	OutputHTTP     = "http"
+	OutputNATS     = "nats"
+)
+
+// Decorates zap cores built by New, e.g. to collect metrics of written entries
+type CoreWrapper interface {
+	// Wraps the core of a single output, called for every enabled output
+	WrapOutput(name string, core zapcore.Core) zapcore.Core
+
+	// Wraps the core combining all outputs, it sees every written entry once after the level check. Entries suppressed
+	// by LoggingConfig.DedupConsecutive aren't passed to it, the entry with RepeatedKey summarizing them is.
+	WrapCore(core zapcore.Core) zapcore.Core
+}
+
+func wrapOutput(wrapper CoreWrapper, name string, core zapcore.Core) zapcore.Core {
+	if wrapper == nil {
+		return core
+	}
+	return wrapper.WrapOutput(name, core)
+}
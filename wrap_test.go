@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// recordingWrapper counts entries written through wrapped cores
+type recordingWrapper struct {
+	mu      sync.Mutex
+	outputs []string
+	written map[string]int
+}
+
+func (w *recordingWrapper) WrapOutput(name string, core zapcore.Core) zapcore.Core {
+	w.mu.Lock()
+	w.outputs = append(w.outputs, name)
+	w.mu.Unlock()
+	return &recordingCore{Core: core, wrapper: w, name: name}
+}
+
+func (w *recordingWrapper) WrapCore(core zapcore.Core) zapcore.Core {
+	return &recordingCore{Core: core, wrapper: w, name: "all"}
+}
+
+type recordingCore struct {
+	zapcore.Core
+
+	wrapper *recordingWrapper
+	name    string
+}
+
+func (c *recordingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &recordingCore{Core: c.Core.With(fields), wrapper: c.wrapper, name: c.name}
+}
+
+func (c *recordingCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *recordingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.wrapper.mu.Lock()
+	c.wrapper.written[c.name]++
+	c.wrapper.mu.Unlock()
+	return c.Core.Write(ent, fields)
+}
+
+func TestCoreWrapper(t *testing.T) {
+	buf := &lockedBuffer{}
+	wrapper := &recordingWrapper{written: map[string]int{}}
+	logger, err := newLogger(LoggingConfig{
+		Service:     "testing",
+		Level:       "info",
+		Sequence:    true,
+		CoreWrapper: wrapper,
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	logger.With(Fields{"key": "value"}).Info("first")
+	logger.Debug("filtered by level")
+	logger.Warn("second")
+
+	assert.Equal(t, []string{OutputStdout}, wrapper.outputs)
+	assert.Equal(t, map[string]int{"all": 2, OutputStdout: 2}, wrapper.written)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "value", entries[0]["key"])
+	assert.Equal(t, float64(2), entries[1]["seq"])
+}
+
+func TestCoreWrapper_Dedup(t *testing.T) {
+	buf := &lockedBuffer{}
+	wrapper := &recordingWrapper{written: map[string]int{}}
+	logger, err := newLogger(LoggingConfig{
+		Service:          "testing",
+		Level:            "info",
+		DedupConsecutive: true,
+		CoreWrapper:      wrapper,
+	}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		logger.Warn("connection lost")
+	}
+	logger.Info("reconnected")
+
+	// The first entry, the summary of suppressed ones and the different one, counted like Stats does
+	require.Len(t, buf.entries(t), 3)
+	assert.Equal(t, map[string]int{"all": 3, OutputStdout: 3}, wrapper.written)
+	assert.Equal(t, map[string]uint64{"warn": 2, "info": 1}, logger.Stats().Entries)
+}
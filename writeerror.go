@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"reflect"
+	"runtime"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// writeErrorCore reports failed writes of an output to LoggingConfig.OnWriteError
+type writeErrorCore struct {
+	zapcore.Core
+
+	sink    string
+	onError func(sink string, err error)
+}
+
+func newWriteErrorCore(core zapcore.Core, sink string, onError func(sink string, err error)) zapcore.Core {
+	return &writeErrorCore{Core: core, sink: sink, onError: onError}
+}
+
+func (c *writeErrorCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	return &clone
+}
+
+func (c *writeErrorCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *writeErrorCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	err := c.Core.Write(ent, fields)
+	if err != nil && !reportingWriteError() {
+		c.report(err)
+	}
+	return err
+}
+
+// report calls the callback, its frame marks writes of entries logged by the callback itself
+func (c *writeErrorCore) report(err error) {
+	c.onError(c.sink, err)
+}
+
+// Name of report as reported by runtime frames
+var reportFunction = runtime.FuncForPC(reflect.ValueOf((*writeErrorCore).report).Pointer()).Name()
+
+// reportingWriteError reports whether the calling goroutine runs the callback, so failed writes of entries it logs
+// don't call it again. Only the failing path walks the stack, other goroutines are reported as usual.
+func reportingWriteError() bool {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if frame.Function == reportFunction {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
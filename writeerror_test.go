@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnWriteError(t *testing.T) {
+	failed := errors.New("disk full")
+
+	var mu sync.Mutex
+	var sinks []string
+	var errs []error
+	var logger Logger
+
+	var err error
+	logger, err = newLogger(LoggingConfig{
+		Service: "testing",
+		OnWriteError: func(sink string, err error) {
+			mu.Lock()
+			sinks = append(sinks, sink)
+			errs = append(errs, err)
+			mu.Unlock()
+
+			// Fails too, but doesn't call the callback again
+			logger.Error("write failed")
+		},
+	}, &mockSink{err: failed})
+	require.NoError(t, err)
+
+	logger.Info("lost")
+	logger.With(Fields{"a": "b"}).Warn("lost too")
+
+	assert.Equal(t, []string{OutputStdout, OutputStdout}, sinks)
+	for _, err := range errs {
+		assert.True(t, errors.Is(err, failed), err)
+	}
+}
+
+func TestOnWriteError_Concurrent(t *testing.T) {
+	failed := errors.New("disk full")
+
+	var calls int32
+	second := make(chan struct{})
+	logger, err := newLogger(LoggingConfig{
+		Service: "testing",
+		OnWriteError: func(sink string, err error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				// Failures of other goroutines are reported while the first callback still runs
+				select {
+				case <-second:
+				case <-time.After(5 * time.Second):
+				}
+				return
+			}
+			close(second)
+		},
+	}, &mockSink{err: failed})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		logger.Info("first")
+	}()
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&calls) == 1 }, time.Second, time.Millisecond)
+	logger.Info("second")
+	wg.Wait()
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestOnWriteError_Succeeded(t *testing.T) {
+	called := false
+	logger, err := newLogger(LoggingConfig{
+		Service:      "testing",
+		OnWriteError: func(string, error) { called = true },
+	}, &mockSink{})
+	require.NoError(t, err)
+
+	logger.Info("written")
+	assert.False(t, called)
+}
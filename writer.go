@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"bytes"
+	"log"
+	"regexp"
+	"sync"
+)
+
+// Date and time written by standard library loggers with log.LstdFlags and similar flags
+var stdTimestamp = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} )?(\d{2}:\d{2}:\d{2}(\.\d+)? )?`)
+
+// LineWriter splits written bytes into lines and logs each of them as a separate entry.
+// Safe for concurrent use.
+type LineWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+
+	log func(message ...interface{})
+}
+
+func (l loggerImpl) Writer(level string) *LineWriter {
+	return NewLineWriter(l, level)
+}
+
+// NewLineWriter returns LineWriter logging lines with l at the level like Writer does,
+// e.g. for implementations of Logger wrapping or mocking it
+func NewLineWriter(l Logger, level string) *LineWriter {
+	w := &LineWriter{}
+
+	switch level {
+	case "debug":
+		w.log = l.Debug
+	case "warn":
+		w.log = l.Warn
+	case "error":
+		w.log = l.Error
+	case "panic":
+		w.log = l.Panic
+	case "fatal":
+		w.log = l.Fatal
+	default:
+		w.log = l.Info
+	}
+
+	return w
+}
+
+func (l loggerImpl) StdLogger(level string) *log.Logger {
+	return log.New(l.skipCaller(stdLoggerDepth).Writer(level), "", 0)
+}
+
+// Frames skipped by StdLogger: emit, Write and two frames of log.Logger, e.g. Printf and its output method
+const stdLoggerDepth = 4
+
+func (w *LineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+
+		line := w.buf.Next(i + 1)
+		w.emit(line[:i])
+	}
+
+	return len(p), nil
+}
+
+// Sync logs buffered partial line if any
+func (w *LineWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.emit(w.buf.Bytes())
+		w.buf.Reset()
+	}
+
+	return nil
+}
+
+// Close flushes partial line, the writer can still be used after that
+func (w *LineWriter) Close() error {
+	return w.Sync()
+}
+
+func (w *LineWriter) emit(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	line = stdTimestamp.ReplaceAll(line, nil)
+	if len(line) == 0 {
+		return
+	}
+
+	w.log(string(line))
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// lockedBuffer is a buffer safe to write from logging goroutines and read in tests
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries decodes all JSON lines written so far
+func (b *lockedBuffer) entries(t *testing.T) []map[string]interface{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(bytes.NewReader(b.buf.Bytes()))
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLoggerImpl_Writer(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "debug"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	w := logger.With(Fields{"source": "writer"}).Writer("warn")
+
+	_, err = w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("line\r\n\npartial"))
+	require.NoError(t, err)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "first line", entries[0]["message"])
+	assert.Equal(t, "second line", entries[1]["message"])
+	assert.Equal(t, "warn", entries[1]["level"])
+	assert.Equal(t, "writer", entries[1]["source"])
+
+	require.NoError(t, w.Close())
+
+	entries = buf.entries(t)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "partial", entries[2]["message"])
+
+	// Nothing left to flush
+	require.NoError(t, w.Sync())
+	assert.Len(t, buf.entries(t), 3)
+}
+
+func TestLoggerImpl_StdLogger(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "debug"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	std := logger.StdLogger("error")
+	std.Printf("failed: %v", "reason")
+
+	// Timestamp is trimmed when the standard logger writes it
+	stdWithTime := log.New(logger.Writer("debug"), "", log.LstdFlags|log.Lmicroseconds)
+	stdWithTime.Print("with time")
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "failed: reason", entries[0]["message"])
+	assert.Equal(t, "error", entries[0]["level"])
+	assert.Equal(t, "with time", entries[1]["message"])
+	assert.Equal(t, "debug", entries[1]["level"])
+}
+
+func TestLoggerImpl_StdLoggerCaller(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "debug", Caller: true}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	std := logger.StdLogger("error")
+	std.Printf("failed: %v", "reason")
+	std.Println("failed")
+	_, _, line, _ := runtime.Caller(0)
+
+	entries := buf.entries(t)
+	require.Len(t, entries, 2)
+	assert.True(t, strings.HasSuffix(entries[0]["caller"].(string), "writer_test.go:"+strconv.Itoa(line-2)), entries[0]["caller"])
+	assert.True(t, strings.HasSuffix(entries[1]["caller"].(string), "writer_test.go:"+strconv.Itoa(line-1)), entries[1]["caller"])
+}
+
+func TestLoggerImpl_StdLoggerHTTPServer(t *testing.T) {
+	buf := &lockedBuffer{}
+	logger, err := newLogger(LoggingConfig{Service: "testing", Level: "info"}, zapcore.AddSync(buf))
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.Config.ErrorLog = logger.Namespace("http").StdLogger("warn")
+	server.StartTLS()
+	defer server.Close()
+
+	// Plain text request to TLS server fails the handshake
+	conn, err := net.Dial("tcp", server.Listener.Addr().String())
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+	_, _ = bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		return len(buf.entries(t)) > 0
+	}, time.Second, 10*time.Millisecond)
+
+	entry := buf.entries(t)[0]
+	assert.Equal(t, "warn", entry["level"])
+	assert.Equal(t, "http", entry["namespace"])
+	assert.True(t, strings.HasPrefix(entry["message"].(string), "http: TLS handshake error"), entry["message"])
+}